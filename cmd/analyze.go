@@ -3,136 +3,357 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/api/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 var analyzeCmd = &cobra.Command{
 	Use:   "analyze",
-	Short: "Analyze resource requests and costs for a namespace",
-	Long:  `Display pod resource requests, limits, and estimated costs for the specified namespace.`,
-	RunE:  runAnalyze,
+	Short: "Analyze resource requests and costs for one or more namespaces",
+	Long: `Display pod resource requests, limits, and estimated costs for the specified namespaces.
+
+Namespaces can be given with repeated -n flags, matched by label with
+--namespace-selector, or covered all at once with -A/--all-namespaces.
+
+--group-by owner rolls pods up into the Deployment, StatefulSet, DaemonSet,
+CronJob or other controller that owns them, with the running replica count.
+--group-by label:team,annotation:cost-center sums costs by label and annotation
+values instead, nesting each key inside the previous one. Pods without a value
+inherit their namespace's label or annotation, else fall into --unlabelled-bucket.
+
+--include-idle adds every node's allocatable capacity, the requests scheduled on
+it and the cost of the unrequested remainder. --distribute-idle also shares each
+node's idle cost across the namespaces with pods on it, in proportion to their
+CPU and memory request costs there, and includes the shares in their totals.
+
+--shared-costs reads a file naming shared namespaces, such as kube-system or
+monitoring, whose cost is allocated to the other namespaces evenly, in proportion
+to their own cost, or by a weight table. Namespace summaries then show each
+namespace's direct cost and its allocated share.`,
+	RunE: runAnalyze,
 }
 
 var (
-	namespace    string
-	cpuRate      float64
-	memoryRate   float64
-	showCosts    bool
-	outputFormat string
+	namespaces        []string
+	namespaceSelector string
+	allNamespaces     bool
+	analyzeWorkloads  bool
+	showCosts         bool
+	showUsage         bool
+	groupBy           string
+	unlabelledBucket  string
+	includeIdle       bool
+	distributeIdle    bool
+	outputFormat      string
 )
 
+// groupByOwner rolls pods up into their owning controllers
+const groupByOwner = "owner"
+
 func init() {
 	rootCmd.AddCommand(analyzeCmd)
-	analyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace to analyze")
-	analyzeCmd.Flags().Float64Var(&cpuRate, "cpu-rate", 0.034, "Cost per CPU core per hour (USD)")
-	analyzeCmd.Flags().Float64Var(&memoryRate, "memory-rate", 0.004, "Cost per GB memory per hour (USD)")
+	analyzeCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to analyze (repeatable)")
+	analyzeCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Analyze namespaces matching this label selector")
+	analyzeCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Analyze pods in all namespaces")
+	analyzeCmd.Flags().BoolVar(&analyzeWorkloads, "workloads", false, "Estimate from workload controllers at their desired replicas instead of running pods")
+	addRateFlags(analyzeCmd)
 	analyzeCmd.Flags().BoolVar(&showCosts, "costs", true, "Show cost estimates")
-	analyzeCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", "Output format: table, json, csv")
+	analyzeCmd.Flags().BoolVar(&showUsage, "usage", false, "Compare requests with observed usage from metrics-server or Prometheus")
+	addUsageFlags(analyzeCmd)
+	analyzeCmd.Flags().StringVar(&groupBy, "group-by", "", "Aggregate pods by owner, or by label:KEY and annotation:KEY values (comma-separated, nested)")
+	analyzeCmd.Flags().StringVar(&unlabelledBucket, "unlabelled-bucket", analyzer.DefaultUnlabelled, "Group for pods without a value for a --group-by label or annotation")
+	analyzeCmd.Flags().BoolVar(&includeIdle, "include-idle", false, "Report node capacity and the cost of capacity no pod requests")
+	analyzeCmd.Flags().BoolVar(&distributeIdle, "distribute-idle", false, "Share idle node cost across namespaces in proportion to their requests (implies --include-idle)")
+	analyzeCmd.Flags().StringVar(&sharedCostsFile, "shared-costs", "", "Shared-cost configuration file: namespaces whose cost is allocated to the others")
+	analyzeCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", outputFormatUsage()+" (label and annotation --group-by: "+strings.Join(groupFormats, ", ")+"; --costs=false: table)")
+	addOutputFileFlag(analyzeCmd)
+	addReportFlags(analyzeCmd)
 }
 
 func runAnalyze(cmd *cobra.Command, args []string) error {
-	// Check if using default rates and if they're stale
-	usingDefaultCPU := !cmd.Flags().Changed("cpu-rate")
-	usingDefaultMemory := !cmd.Flags().Changed("memory-rate")
-	if showCosts && (usingDefaultCPU || usingDefaultMemory) {
-		checkRateStaleness()
+	if showUsage && analyzeWorkloads {
+		return fmt.Errorf("--usage applies to running pods and cannot be combined with --workloads")
+	}
+	if showUsage && !showCosts {
+		return fmt.Errorf("--usage prices usage against requests and cannot be combined with --costs=false")
+	}
+	if baselineFile != "" && (!showCosts || (groupBy != "" && groupBy != groupByOwner)) {
+		return fmt.Errorf("--baseline cannot be combined with --costs=false or label and annotation --group-by keys")
+	}
+	includeIdle = includeIdle || distributeIdle
+	if includeIdle && (analyzeWorkloads || !showCosts || groupBy != "") {
+		return fmt.Errorf("--include-idle cannot be combined with --workloads, --costs=false or --group-by")
+	}
+	var sharedCosts *analyzer.SharedCosts
+	if sharedCostsFile != "" {
+		if analyzeWorkloads || !showCosts || groupBy != "" {
+			return fmt.Errorf("--shared-costs cannot be combined with --workloads, --costs=false or --group-by")
+		}
+		cfg, err := analyzer.LoadSharedCosts(sharedCostsFile)
+		if err != nil {
+			return err
+		}
+		sharedCosts = &cfg
+	}
+	var groupKeys []analyzer.GroupKey
+	if groupBy != "" {
+		if analyzeWorkloads || !showCosts || showUsage {
+			return fmt.Errorf("--group-by cannot be combined with --workloads, --costs=false or --usage")
+		}
+		if groupBy != groupByOwner {
+			var err error
+			if groupKeys, err = analyzer.ParseGroupKeys(groupBy); err != nil {
+				return fmt.Errorf("invalid --group-by: %w", err)
+			}
+		}
 	}
 
-	client, err := k8s.NewClient()
+	if err := checkOutputFormat(analyzeFormats(len(groupKeys) > 0)); err != nil {
+		return err
+	}
+
+	var rates calculator.Rates
+	if showCosts {
+		var err error
+		rates, err = loadRates(cmd)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient()
 	if err != nil {
-		return fmt.Errorf("failed to create kubernetes client: %w", err)
+		return err
 	}
 
 	ctx := context.Background()
-	pods, err := k8s.FetchPods(ctx, client, namespace)
+	targets, err := resolveNamespaces(ctx, cmd, client)
 	if err != nil {
 		return err
 	}
 
-	if len(pods) == 0 {
-		fmt.Printf("No pods found in namespace '%s'\n", namespace)
+	if analyzeWorkloads {
+		return runAnalyzeWorkloads(ctx, client, targets, rates)
+	}
+
+	var pods []corev1.Pod
+	if allNamespaces || len(targets) > 0 {
+		pods, err = k8s.FetchPodsInNamespaces(ctx, client, targets)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Claims are loaded before the empty check so that namespaces holding only
+	// unmounted claims still report them as orphaned
+	var p pricer
+	var orphans []calculator.ClaimCost
+	if showCosts {
+		p = newPricer(ctx, client, rates)
+		if allNamespaces || len(targets) > 0 {
+			orphans = p.loadStorage(ctx, client, targets, pods)
+		}
+	}
+
+	scope := describeScope(targets)
+	if len(pods) == 0 && len(orphans) == 0 {
+		fmt.Fprintf(os.Stderr, "No pods found in %s\n", scope)
 		return nil
 	}
 
-	fmt.Printf("Analyzing %d pods in namespace '%s':\n\n", len(pods), namespace)
+	// Progress goes to stderr so reports can be piped or written with --output-file
+	fmt.Fprintf(os.Stderr, "Analyzing %d pods in %s:\n\n", len(pods), scope)
 
 	if !showCosts {
 		resources := make([]k8s.PodResources, len(pods))
 		for i, pod := range pods {
 			resources[i] = k8s.ExtractResources(pod)
 		}
-		reporter.PrintPodResourcesTable(resources)
-		return nil
+		return writeReport(func(w io.Writer) error {
+			reporter.PrintPodResourcesTable(w, resources)
+			return nil
+		})
+	}
+
+	// Comparing with a baseline rolls pods up by owner, as diff does
+	if groupBy == groupByOwner || baselineFile != "" {
+		p.loadOwners(ctx, client, targets)
+	}
+	if showUsage {
+		source, err := newUsageSource(client)
+		if err != nil {
+			return err
+		}
+		if err := p.loadUsage(ctx, source, targets); err != nil {
+			return err
+		}
 	}
 
-	// Calculate costs
-	rates := calculator.Rates{
-		CPUPerCorePerHour:  cpuRate,
-		MemoryPerGBPerHour: memoryRate,
+	// Idle and shared costs are allocated across the whole cluster
+	var alloc analyzer.Allocations
+	if includeIdle || sharedCosts != nil {
+		cluster, err := clusterPods(ctx, client, pods)
+		if err != nil {
+			return err
+		}
+		if includeIdle {
+			if alloc.Idle, err = loadIdle(ctx, client, p, cluster, distributeIdle); err != nil {
+				return err
+			}
+		}
+		if sharedCosts != nil {
+			if alloc.Shared, err = loadShared(ctx, client, *sharedCosts, p, cluster, alloc.Idle); err != nil {
+				return err
+			}
+		}
 	}
 
-	podCosts := make([]calculator.PodCost, 0, len(pods))
-	for _, pod := range pods {
-		res := k8s.ExtractResources(pod)
+	podCosts := calculatePodCosts(pods, p)
+	if groupBy == groupByOwner {
+		return printWorkloadCosts(reportMetadata(targets, rates, "These are estimates based on resource requests of running replicas, not actual usage."),
+			analyzer.AggregateByOwner(podCosts), orphans)
+	}
+	if len(groupKeys) > 0 {
+		grouping := analyzer.Grouping{
+			Keys:       groupKeys,
+			Unlabelled: unlabelledBucket,
+			Namespaces: namespaceMetadata(ctx, client),
+		}
+		return printGroups(groupKeys, analyzer.AggregateByGroups(podCosts, orphans, grouping))
+	}
 
-		cpuQty, _ := resource.ParseQuantity(res.CPURequest)
-		memQty, _ := resource.ParseQuantity(res.MemoryRequest)
+	note := "These are estimates based on resource requests, not actual usage."
+	if showUsage {
+		note = fmt.Sprintf("Costs are estimates based on resource requests; usage is the average of %s.", describeUsageSource())
+	}
+	return renderReport(reporter.NewPodReport(reportMetadata(targets, rates, note), analyzer.SortByMonthlyCost(podCosts), orphans, alloc))
+}
 
-		if cpuQty.IsZero() && memQty.IsZero() {
-			continue
+// runAnalyzeWorkloads prices workload controllers at their desired replica counts
+func runAnalyzeWorkloads(ctx context.Context, client *kubernetes.Clientset, targets []string, rates calculator.Rates) error {
+	var workloads []k8s.Workload
+	if allNamespaces || len(targets) > 0 {
+		var err error
+		workloads, err = k8s.FetchWorkloads(ctx, client, targets)
+		if err != nil {
+			return err
 		}
+	}
 
-		cost := calculator.CalculatePodCost(pod.Name, pod.Namespace, cpuQty, memQty, rates)
-		podCosts = append(podCosts, cost)
+	scope := describeScope(targets)
+	if len(workloads) == 0 {
+		fmt.Fprintf(os.Stderr, "No workloads found in %s\n", scope)
+		return nil
 	}
 
-	// Sort by cost (highest first)
-	sortedCosts := analyzer.SortByMonthlyCost(podCosts)
+	fmt.Fprintf(os.Stderr, "Analyzing %d workloads in %s:\n\n", len(workloads), scope)
 
-	// Output based on format
-	switch outputFormat {
-	case "json":
-		if err := reporter.PrintCostJSON(namespace, sortedCosts); err != nil {
-			return fmt.Errorf("failed to output JSON: %w", err)
-		}
-	case "csv":
-		if err := reporter.PrintCostCSV(sortedCosts); err != nil {
-			return fmt.Errorf("failed to output CSV: %w", err)
-		}
-	case "table":
-		reporter.PrintCostTable(sortedCosts)
+	if !showCosts {
+		return writeReport(func(w io.Writer) error {
+			reporter.PrintWorkloadResourcesTable(w, workloads)
+			return nil
+		})
+	}
 
-		// Show summary for table format
-		summary := analyzer.AggregateByNamespace(podCosts)
-		fmt.Printf("\nNamespace Summary:\n")
-		fmt.Printf("  Total Pods: %d\n", summary.TotalPods)
-		fmt.Printf("  Estimated Monthly Cost: $%.2f\n", summary.MonthlyCost)
-		fmt.Printf("\nNote: These are estimates based on resource requests, not actual usage.\n")
+	return printWorkloadCosts(reportMetadata(targets, rates, desiredReplicasNote), calculateWorkloadCosts(workloads, flatPricer(rates)), nil)
+}
+
+// analyzeFormats returns the output formats analyze can render: a resources table
+// with --costs=false, the group formats for label and annotation groups, and every
+// registered report format otherwise
+func analyzeFormats(grouped bool) []string {
+	switch {
+	case !showCosts:
+		return []string{"table"}
+	case grouped:
+		return groupFormats
 	default:
-		return fmt.Errorf("unsupported output format: %s (supported: table, json, csv)", outputFormat)
+		return reporter.Formats()
 	}
+}
+
+// desiredReplicasNote follows tables of workloads priced at their desired replicas
+const desiredReplicasNote = "These are estimates based on resource requests at desired replicas, not actual usage."
 
-	return nil
+// printWorkloadCosts renders workload costs and orphaned claims in the selected
+// output format
+func printWorkloadCosts(meta reporter.Metadata, costs []calculator.WorkloadCost, orphans []calculator.ClaimCost) error {
+	return renderReport(reporter.NewWorkloadReport(meta, analyzer.SortWorkloadsByMonthlyCost(costs), orphans))
 }
 
-func checkRateStaleness() {
-	ratesPath := "config/rates.yaml"
-	_, daysSince, err := calculator.GetRatesLastUpdated(ratesPath)
-	if err != nil {
-		// Silently ignore if rates file doesn't exist or can't be read
-		return
+// reportMetadata describes a report of the given namespaces priced with rates; note
+// follows table output
+func reportMetadata(targets []string, rates calculator.Rates, note string) reporter.Metadata {
+	return reporter.Metadata{
+		Namespace:   reportNamespace(targets),
+		Scope:       describeScope(targets),
+		Rates:       rates,
+		GeneratedAt: time.Now(),
+		Note:        note,
 	}
+}
+
+// reportNamespace returns the namespace to label a report with, if there is exactly one
+func reportNamespace(targets []string) string {
+	if len(targets) == 1 {
+		return targets[0]
+	}
+	return ""
+}
 
-	const staleThreshold = 180 // 6 months
-	if daysSince > staleThreshold {
-		fmt.Fprintf(os.Stderr, "Warning: Default pricing rates are %d days old (last updated %d days ago).\n", daysSince, daysSince)
-		fmt.Fprintf(os.Stderr, "Consider updating %s or use --cpu-rate and --memory-rate flags.\n\n", ratesPath)
+// resolveNamespaces turns the namespace flags into the list of namespaces to analyze.
+// A nil result with --all-namespaces means the whole cluster.
+func resolveNamespaces(ctx context.Context, cmd *cobra.Command, client *kubernetes.Clientset) ([]string, error) {
+	if allNamespaces {
+		return nil, nil
+	}
+
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(names []string) {
+		for _, ns := range names {
+			if !seen[ns] {
+				seen[ns] = true
+				targets = append(targets, ns)
+			}
+		}
+	}
+
+	// The "default" namespace only applies when no other selection was made
+	if cmd.Flags().Changed("namespace") || namespaceSelector == "" {
+		add(namespaces)
+	}
+	if namespaceSelector != "" {
+		matched, err := k8s.ListNamespaces(ctx, client, namespaceSelector)
+		if err != nil {
+			return nil, err
+		}
+		add(matched)
+	}
+
+	return targets, nil
+}
+
+// describeScope renders the analyzed namespaces for progress messages
+func describeScope(targets []string) string {
+	switch {
+	case allNamespaces:
+		return "all namespaces"
+	case len(targets) == 1:
+		return fmt.Sprintf("namespace '%s'", targets[0])
+	case len(targets) == 0:
+		return fmt.Sprintf("namespaces matching '%s'", namespaceSelector)
+	default:
+		return fmt.Sprintf("%d namespaces (%s)", len(targets), strings.Join(targets, ", "))
 	}
 }
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/spf13/cobra"
+)
+
+// cacheSyncTimeout bounds the initial lists of a cache
+const cacheSyncTimeout = 2 * time.Minute
+
+// startCache caches the namespaces selected by the namespace flags until ctx is
+// done. A --namespace-selector is resolved once, when the cache starts.
+func startCache(ctx context.Context, cmd *cobra.Command) (*k8s.Cache, []string, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	targets, err := resolveNamespaces(ctx, cmd, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !allNamespaces && len(targets) == 0 {
+		return nil, nil, fmt.Errorf("no namespaces match '%s'", namespaceSelector)
+	}
+
+	c, err := k8s.NewCache(client, targets, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Syncing pods, nodes and workloads in %s...\n", describeScope(targets))
+	if err := c.Start(ctx, cacheSyncTimeout); err != nil {
+		return nil, nil, err
+	}
+	return c, targets, nil
+}
+
+// priceCache prices the cached pods as analyze prices listed ones, with storage
+// and owners, and returns the claims no pod mounts
+func priceCache(c *k8s.Cache, rates calculator.Rates) ([]calculator.PodCost, []calculator.ClaimCost) {
+	pods := c.Pods()
+	p := nodePricer(rates, c.Nodes())
+	orphans := p.priceClaims(c.Claims(), pods)
+	p.owners = c.OwnerIndex()
+	return calculatePodCosts(pods, p), orphans
+}
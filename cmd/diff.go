@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+// clusterInput is the diff input that stands for the running cluster
+const clusterInput = "cluster"
+
+var diffCmd = &cobra.Command{
+	Use:   "diff BEFORE AFTER",
+	Short: "Compare the cost of two reports, manifest revisions or the cluster",
+	Long: `Report the workloads added, removed and changed between two inputs, with their
+monthly cost before and after and the difference.
+
+Each input is one of:
+  - a JSON report saved from "kcost analyze -o json" or "kcost estimate -o json"
+  - a manifest file or directory, or - for standard input, priced as estimate does
+  - "cluster", the running pods in the namespaces selected by -n, --namespace-selector
+    or -A, rolled up to their owners
+
+For example, to review the cost of a change before it is applied:
+
+  kcost diff cluster deploy/ -n shop -o markdown
+  kcost diff base.json <(helm template ./chart)
+
+Workloads are matched by namespace, kind and name. Storage claims no pod mounts
+are not compared.
+
+Manifests are not bound to nodes, so when compared with one the cluster is priced
+at the flat rates as well, without the instance catalog. Cluster pods are charged for the claims they
+mount; manifests are only charged for StatefulSet volumeClaimTemplates, so a
+workload mounting a separately created claim shows its storage as removed when
+compared with its manifest.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to compare in the cluster (repeatable); for manifests, the namespace for objects that do not set one")
+	diffCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Compare cluster namespaces matching this label selector")
+	diffCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Compare pods in all namespaces of the cluster")
+	diffCmd.Flags().Int32Var(&daemonSetNodes, "daemonset-nodes", 1, "Number of nodes each DaemonSet in manifests is assumed to run on")
+	addRateFlags(diffCmd)
+	diffCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", formatUsage(diffFormats))
+	addOutputFileFlag(diffCmd)
+}
+
+// diffFormats are the output formats for cost diffs
+var diffFormats = []string{"table", "json", "markdown"}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if err := checkOutputFormat(diffFormats); err != nil {
+		return err
+	}
+	if args[0] == "-" && args[1] == "-" {
+		return fmt.Errorf("only one input can be read from standard input")
+	}
+
+	// Rates are only needed to price manifests or the cluster
+	var rates calculator.Rates
+	if !isJSONReport(args[0]) || !isJSONReport(args[1]) {
+		var err error
+		if rates, err = loadRates(cmd); err != nil {
+			return err
+		}
+	}
+
+	before, err := loadDiffInput(cmd, args[0], args[1], rates)
+	if err != nil {
+		return fmt.Errorf("before: %w", err)
+	}
+	after, err := loadDiffInput(cmd, args[1], args[0], rates)
+	if err != nil {
+		return fmt.Errorf("after: %w", err)
+	}
+
+	diff := analyzer.DiffWorkloads(before, after)
+	return writeReport(func(w io.Writer) error {
+		switch outputFormat {
+		case "json":
+			if err := reporter.PrintDiffJSON(w, diff); err != nil {
+				return fmt.Errorf("failed to output JSON: %w", err)
+			}
+		case "markdown":
+			reporter.PrintDiffMarkdown(w, diff)
+		default:
+			reporter.PrintDiffTable(w, diff)
+		}
+		return nil
+	})
+}
+
+// loadDiffInput prices one side of a diff. When the other side is a manifest, the
+// cluster is priced at the flat rates, as manifests are not bound to nodes.
+func loadDiffInput(cmd *cobra.Command, input, other string, rates calculator.Rates) ([]calculator.WorkloadCost, error) {
+	switch {
+	case input == clusterInput:
+		workloads, _, err := loadClusterWorkloads(cmd, rates, !isJSONReport(other))
+		return workloads, err
+	case isJSONReport(input):
+		report, err := readSavedReport(input)
+		if err != nil {
+			return nil, err
+		}
+		return report.Workloads, nil
+	default:
+		return loadManifestWorkloads(cmd, []string{input}, rates)
+	}
+}
+
+// isJSONReport reports whether input is a saved kcost JSON report rather than
+// manifests. JSON manifests are told apart by their top-level kind field.
+func isJSONReport(input string) bool {
+	if !strings.EqualFold(filepath.Ext(input), ".json") {
+		return false
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return false
+	}
+	return reporter.IsJSONReport(data)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/manifest"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate -f PATH",
+	Short: "Estimate costs from manifest files without a cluster",
+	Long: `Price Pods and workload controllers from YAML or JSON manifests before they are applied.
+
+-f accepts files, directories (searched recursively for .yaml, .yml and .json files)
+and "-" for standard input, and may be repeated. Multi-document YAML is supported,
+so the output of "helm template" or "kustomize build" can be piped in directly:
+
+  helm template ./chart | kcost estimate -f -`,
+	Args: cobra.NoArgs,
+	RunE: runEstimate,
+}
+
+var (
+	manifestPaths     []string
+	manifestNamespace string
+	daemonSetNodes    int32
+)
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+	estimateCmd.Flags().StringSliceVarP(&manifestPaths, "filename", "f", nil, "Manifest file, directory, or - for stdin (repeatable)")
+	estimateCmd.Flags().StringVarP(&manifestNamespace, "namespace", "n", "default", "Namespace for objects that do not set one")
+	estimateCmd.Flags().Int32Var(&daemonSetNodes, "daemonset-nodes", 1, "Number of nodes each DaemonSet is assumed to run on")
+	addRateFlags(estimateCmd)
+	estimateCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", outputFormatUsage())
+	addOutputFileFlag(estimateCmd)
+	addReportFlags(estimateCmd)
+	_ = estimateCmd.MarkFlagRequired("filename")
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	if err := checkOutputFormat(reporter.Formats()); err != nil {
+		return err
+	}
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	objects, err := manifest.Load(manifestPaths, cmd.InOrStdin())
+	if err != nil {
+		return fmt.Errorf("failed to load manifests: %w", err)
+	}
+
+	workloads := manifest.Workloads(objects, manifest.Options{
+		Namespace:      manifestNamespace,
+		DaemonSetNodes: daemonSetNodes,
+	})
+	if len(workloads) == 0 {
+		fmt.Fprintln(os.Stderr, "No pods or workload controllers found in manifests")
+		return nil
+	}
+
+	// Progress goes to stderr so reports can be piped in CI
+	fmt.Fprintf(os.Stderr, "Estimating %d workloads from manifests:\n\n", len(workloads))
+
+	meta := reporter.Metadata{
+		Scope:       "manifests",
+		Rates:       rates,
+		GeneratedAt: time.Now(),
+		Note:        desiredReplicasNote,
+	}
+	return printWorkloadCosts(meta, calculateWorkloadCosts(workloads, flatPricer(rates)), nil)
+}
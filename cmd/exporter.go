@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/exporter"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/spf13/cobra"
+)
+
+var exporterCmd = &cobra.Command{
+	Use:   "exporter",
+	Short: "Serve cost estimates as Prometheus metrics",
+	Long: `Run until interrupted, recomputing pod and namespace costs every --refresh
+interval and serving them in the Prometheus text format on /metrics, for
+dashboards and alerts without a separate cost-allocation service.
+
+Main metrics:
+
+  kcost_pod_hourly_cost{namespace,pod,resource}   hourly cost by resource (cpu, memory, storage, extended)
+  kcost_pod_monthly_cost{namespace,pod}           monthly cost of a pod
+  kcost_namespace_monthly_cost{namespace}         monthly cost of a namespace
+  kcost_total_monthly_cost                        monthly cost of everything priced
+
+Pods, nodes, workloads and claims are kept in memory through watches, so a
+refresh reprices the current state without listing the cluster again. A
+--namespace-selector is resolved once, at startup.
+
+--max-pods and --max-namespaces bound the number of series: only the most
+expensive pods and namespaces get their own series, the rest are summed into
+pod="_other" per namespace and namespace="_other", where all pods of the folded
+namespaces become one pod="_other" series. /healthz answers once the first refresh
+has succeeded.
+
+A refresh fails when listing or watching the cluster failed since the previous
+one, as the cached state may be stale: the previous costs stay exported and
+kcost_refresh_failures_total is incremented.`,
+	Args: cobra.NoArgs,
+	RunE: runExporter,
+}
+
+var (
+	listenAddress   string
+	refreshInterval time.Duration
+	exporterLimits  exporter.Limits
+)
+
+func init() {
+	rootCmd.AddCommand(exporterCmd)
+	exporterCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to export (repeatable)")
+	exporterCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Export namespaces matching this label selector")
+	exporterCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Export pods in all namespaces")
+	exporterCmd.Flags().StringVar(&listenAddress, "listen", ":9400", "Address to serve /metrics on")
+	exporterCmd.Flags().DurationVar(&refreshInterval, "refresh", time.Minute, "How often to recompute costs")
+	exporterCmd.Flags().IntVar(&exporterLimits.MaxPods, "max-pods", 1000, "Most pods exported individually; the rest are summed per namespace (0 for no limit)")
+	exporterCmd.Flags().IntVar(&exporterLimits.MaxNamespaces, "max-namespaces", 200, "Most namespaces exported individually; the rest are summed (0 for no limit)")
+	addRateFlags(exporterCmd)
+}
+
+func runExporter(cmd *cobra.Command, args []string) error {
+	if refreshInterval <= 0 {
+		return fmt.Errorf("--refresh must be positive")
+	}
+	if exporterLimits.MaxPods < 0 || exporterLimits.MaxNamespaces < 0 {
+		return fmt.Errorf("--max-pods and --max-namespaces cannot be negative")
+	}
+
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c, _, err := startCache(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	exp := exporter.New(exporterLimits)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exp)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !exp.Ready() {
+			http.Error(w, "costs not computed yet", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	server := &http.Server{Addr: listenAddress, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Serving metrics on %s/metrics, refreshing every %s\n", listenAddress, refreshInterval)
+
+	// A refresh fails when lists or watches failed since the previous one: the
+	// cache may be stale, so the last costs stay exported until watches recover
+	watchErrors := c.WatchErrors()
+	refresh := func() {
+		if errs := c.WatchErrors(); errs > watchErrors {
+			exp.RecordFailure()
+			fmt.Fprintf(os.Stderr, "Warning: failed to refresh costs: %d watch errors since the last refresh; keeping the previous costs\n", errs-watchErrors)
+			watchErrors = errs
+			return
+		}
+		start := time.Now()
+		snapshot := exporterSnapshot(c, rates)
+		snapshot.Time, snapshot.Duration = start, time.Since(start)
+		exp.Update(snapshot)
+	}
+
+	refresh()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			refresh()
+		case err := <-serveErr:
+			return fmt.Errorf("failed to serve metrics: %w", err)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("failed to stop metrics server: %w", err)
+			}
+			return nil
+		}
+	}
+}
+
+// exporterSnapshot prices the cached pods and orphaned claims
+func exporterSnapshot(c *k8s.Cache, rates calculator.Rates) exporter.Snapshot {
+	pods, orphans := priceCache(c, rates)
+	return exporter.Snapshot{
+		Pods:      pods,
+		Orphans:   orphans,
+		Summaries: analyzer.AddOrphanedClaims(analyzer.AggregateByNamespace(pods), orphans),
+		Rates:     rates,
+	}
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/gate"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of kcost gate; other errors exit with 1
+const (
+	exitGateWarn = 2
+	exitGateFail = 3
+)
+
+var gateCmd = &cobra.Command{
+	Use:   "gate",
+	Short: "Check costs against budgets and fail CI when they are exceeded",
+	Long: `Price manifests (-f) or the running cluster and check the monthly cost in total,
+per namespace and per workload against budgets.
+
+Budgets come from a YAML file (--budgets) or, for the total, from flags. With
+--baseline, a JSON report from "kcost analyze -o json" or "kcost estimate -o json",
+increases are checked as well:
+
+  kcost estimate -f base/ -o json > baseline.json
+  kcost gate -f change/ --baseline baseline.json --max-increase 10
+
+Violations are printed and the command exits with 2 when only warning thresholds
+are exceeded and 3 when any failure threshold is.`,
+	Args:          cobra.NoArgs,
+	RunE:          runGate,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	budgetsFile  string
+	baselineFile string
+	totalBudget  gate.Budget
+)
+
+func init() {
+	rootCmd.AddCommand(gateCmd)
+	gateCmd.Flags().StringSliceVarP(&manifestPaths, "filename", "f", nil, "Manifest file, directory, or - for stdin (repeatable); the cluster is priced when omitted")
+	gateCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to check (repeatable); with -f, the namespace for objects that do not set one")
+	gateCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Check namespaces matching this label selector")
+	gateCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Check pods in all namespaces")
+	gateCmd.Flags().Int32Var(&daemonSetNodes, "daemonset-nodes", 1, "Number of nodes each DaemonSet is assumed to run on")
+	gateCmd.Flags().StringVar(&budgetsFile, "budgets", "", "Budgets file with total, per-namespace and per-workload limits")
+	gateCmd.Flags().StringVar(&baselineFile, "baseline", "", "JSON report to check increases against")
+	gateCmd.Flags().Float64Var(&totalBudget.MaxMonthly.Fail, "max-monthly", 0, "Fail when the total monthly cost exceeds this amount")
+	gateCmd.Flags().Float64Var(&totalBudget.MaxMonthly.Warn, "warn-monthly", 0, "Warn when the total monthly cost exceeds this amount")
+	gateCmd.Flags().Float64Var(&totalBudget.MaxIncrease.Fail, "max-increase", 0, "Fail when the total monthly cost rises more than this percentage over --baseline")
+	gateCmd.Flags().Float64Var(&totalBudget.MaxIncrease.Warn, "warn-increase", 0, "Warn when the total monthly cost rises more than this percentage over --baseline")
+	addRateFlags(gateCmd)
+	gateCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", formatUsage(gateFormats))
+	addOutputFileFlag(gateCmd)
+}
+
+// gateFormats are the output formats for budget violations
+var gateFormats = []string{"table", "json"}
+
+func runGate(cmd *cobra.Command, args []string) error {
+	budgets, err := gateBudgets(cmd)
+	if err != nil {
+		return err
+	}
+	if err := checkOutputFormat(gateFormats); err != nil {
+		return err
+	}
+
+	var baseline *gate.Costs
+	if baselineFile != "" {
+		costs, err := readBaseline(baselineFile)
+		if err != nil {
+			return err
+		}
+		baseline = &costs
+	} else if budgets.HasIncrease() {
+		return fmt.Errorf("increase limits require --baseline")
+	}
+
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	var costs gate.Costs
+	if len(manifestPaths) > 0 {
+		workloads, err := loadManifestWorkloads(cmd, manifestPaths, rates)
+		if err != nil {
+			return err
+		}
+		costs = gateCosts(workloads, analyzer.AggregateWorkloadsByNamespace(workloads))
+	} else {
+		workloads, orphans, err := loadClusterWorkloads(cmd, rates, false)
+		if err != nil {
+			return err
+		}
+		costs = gateCosts(workloads, analyzer.AddOrphanedClaims(analyzer.AggregateWorkloadsByNamespace(workloads), orphans))
+	}
+
+	violations := gate.Evaluate(costs, baseline, budgets)
+	err = writeReport(func(w io.Writer) error {
+		switch {
+		case outputFormat == "json":
+			if err := reporter.PrintViolationJSON(w, costs.Total, violations); err != nil {
+				return fmt.Errorf("failed to output JSON: %w", err)
+			}
+		case len(violations) == 0:
+			fmt.Fprintf(w, "All budgets met: $%.2f/month in total\n", costs.Total)
+		default:
+			reporter.PrintViolationTable(w, violations)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch gate.Worst(violations) {
+	case gate.LevelFail:
+		return exitError{code: exitGateFail, err: fmt.Errorf("budget failure thresholds exceeded (%d violations)", len(violations))}
+	case gate.LevelWarn:
+		return exitError{code: exitGateWarn, err: fmt.Errorf("budget warning thresholds exceeded (%d violations)", len(violations))}
+	}
+	return nil
+}
+
+// gateBudgets combines the budgets file with the total limits given as flags,
+// which take precedence
+func gateBudgets(cmd *cobra.Command) (gate.Budgets, error) {
+	var budgets gate.Budgets
+	if budgetsFile != "" {
+		var err error
+		if budgets, err = gate.LoadBudgets(budgetsFile); err != nil {
+			return gate.Budgets{}, err
+		}
+	}
+
+	// Flags override the budgets file's total limits
+	overrides := []struct {
+		name   string
+		value  float64
+		target *float64
+	}{
+		{"max-monthly", totalBudget.MaxMonthly.Fail, &budgets.Total.MaxMonthly.Fail},
+		{"warn-monthly", totalBudget.MaxMonthly.Warn, &budgets.Total.MaxMonthly.Warn},
+		{"max-increase", totalBudget.MaxIncrease.Fail, &budgets.Total.MaxIncrease.Fail},
+		{"warn-increase", totalBudget.MaxIncrease.Warn, &budgets.Total.MaxIncrease.Warn},
+	}
+	changed := false
+	for _, o := range overrides {
+		if cmd.Flags().Changed(o.name) {
+			*o.target = o.value
+			changed = true
+		}
+	}
+
+	if budgetsFile == "" && !changed {
+		return gate.Budgets{}, fmt.Errorf("no budgets given: use --budgets or --max-monthly, --warn-monthly, --max-increase, --warn-increase")
+	}
+	if err := budgets.Validate(); err != nil {
+		return gate.Budgets{}, err
+	}
+	return budgets, nil
+}
+
+// readBaseline reads the costs to check increases against from a JSON report
+func readBaseline(path string) (gate.Costs, error) {
+	report, err := readSavedReport(path)
+	if err != nil {
+		return gate.Costs{}, err
+	}
+	return gateCosts(report.Workloads, report.Namespaces), nil
+}
+
+// gateCosts keys workload and namespace monthly costs for gate.Evaluate
+func gateCosts(workloads []calculator.WorkloadCost, summaries []analyzer.NamespaceSummary) gate.Costs {
+	costs := gate.Costs{
+		Namespaces: make(map[string]float64, len(summaries)),
+		Workloads:  make(map[string]float64, len(workloads)),
+	}
+	for _, w := range workloads {
+		costs.Workloads[gate.WorkloadKey(w.Namespace, w.Kind, w.Name)] += w.Monthly.TotalCost
+	}
+	for _, s := range summaries {
+		costs.Namespaces[s.Namespace] += s.MonthlyCost
+		costs.Total += s.MonthlyCost
+	}
+	return costs
+}
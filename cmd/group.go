@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceMetadata returns the labels and annotations of every namespace, for pods
+// to inherit group values from. Inheritance is skipped, with a warning, if
+// namespaces cannot be listed.
+func namespaceMetadata(ctx context.Context, client *kubernetes.Clientset) map[string]analyzer.Metadata {
+	namespaces, err := k8s.FetchNamespaces(ctx, client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; pods will not inherit namespace labels\n", err)
+		return nil
+	}
+
+	meta := make(map[string]analyzer.Metadata, len(namespaces))
+	for _, ns := range namespaces {
+		meta[ns.Name] = analyzer.Metadata{Labels: ns.Labels, Annotations: ns.Annotations}
+	}
+	return meta
+}
+
+// groupFormats are the output formats for label and annotation group summaries
+var groupFormats = []string{"table", "json", "csv"}
+
+// printGroups renders label and annotation group summaries in the selected output
+// format, which runAnalyze has checked against groupFormats
+func printGroups(keys []analyzer.GroupKey, groups []analyzer.GroupSummary) error {
+	return writeReport(func(w io.Writer) error {
+		switch outputFormat {
+		case "json":
+			if err := reporter.PrintGroupJSON(w, keys, groups); err != nil {
+				return fmt.Errorf("failed to output JSON: %w", err)
+			}
+		case "csv":
+			if err := reporter.PrintGroupCSV(w, keys, groups); err != nil {
+				return fmt.Errorf("failed to output CSV: %w", err)
+			}
+		default:
+			reporter.PrintGroupTable(w, groups)
+			fmt.Fprintf(w, "\nNote: These are estimates based on resource requests, not actual usage.\n")
+		}
+		return nil
+	})
+}
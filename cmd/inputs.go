@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/manifest"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+// loadManifestWorkloads prices the workloads in manifests as estimate does. Objects
+// without a namespace are placed in the single -n namespace.
+func loadManifestWorkloads(cmd *cobra.Command, paths []string, rates calculator.Rates) ([]calculator.WorkloadCost, error) {
+	if len(namespaces) != 1 {
+		return nil, fmt.Errorf("-n takes a single namespace with manifests")
+	}
+	objects, err := manifest.Load(paths, cmd.InOrStdin())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manifests: %w", err)
+	}
+	workloads := manifest.Workloads(objects, manifest.Options{
+		Namespace:      namespaces[0],
+		DaemonSetNodes: daemonSetNodes,
+	})
+
+	fmt.Fprintf(os.Stderr, "Pricing %d workloads from manifests\n", len(workloads))
+	return calculateWorkloadCosts(workloads, flatPricer(rates)), nil
+}
+
+// loadClusterWorkloads prices running pods in the namespaces selected by the
+// namespace flags, rolled up to their owners, and the claims no pod mounts. With
+// flat, the instance catalog is not used and every pod gets the flat rates, as
+// manifests do.
+func loadClusterWorkloads(cmd *cobra.Command, rates calculator.Rates, flat bool) ([]calculator.WorkloadCost, []calculator.ClaimCost, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := context.Background()
+	targets, err := resolveNamespaces(ctx, cmd, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !allNamespaces && len(targets) == 0 {
+		return nil, nil, nil
+	}
+	pods, err := k8s.FetchPodsInNamespaces(ctx, client, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Pricing %d pods in %s\n", len(pods), describeScope(targets))
+	p := flatPricer(rates)
+	if !flat {
+		p = newPricer(ctx, client, rates)
+	}
+	orphans := p.loadStorage(ctx, client, targets, pods)
+	p.loadOwners(ctx, client, targets)
+	return analyzer.AggregateByOwner(calculatePodCosts(pods, p)), orphans, nil
+}
+
+// readSavedReport reads a JSON report written by analyze or estimate
+func readSavedReport(path string) (reporter.SavedReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return reporter.SavedReport{}, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer f.Close()
+
+	report, err := reporter.ReadJSONReport(f)
+	if err != nil {
+		return reporter.SavedReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
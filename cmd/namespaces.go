@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -21,9 +20,9 @@ func init() {
 }
 
 func runNamespaces(cmd *cobra.Command, args []string) error {
-	client, err := k8s.NewClient()
+	client, err := newClient()
 	if err != nil {
-		return fmt.Errorf("failed to create kubernetes client: %w", err)
+		return err
 	}
 
 	ctx := context.Background()
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var nodesCmd = &cobra.Command{
+	Use:   "nodes",
+	Short: "Show node capacity, requested resources and idle cost",
+	Long: `List every node with its allocatable CPU and memory, the sum of the requests
+of the pods scheduled on it, and the cost of the capacity no pod requests.
+
+Nodes in the pricing catalog are priced at rates derived from their instance
+price, other nodes at the flat rates.`,
+	Args: cobra.NoArgs,
+	RunE: runNodes,
+}
+
+func init() {
+	rootCmd.AddCommand(nodesCmd)
+	addRateFlags(nodesCmd)
+	nodesCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", formatUsage(nodeFormats))
+	addOutputFileFlag(nodesCmd)
+}
+
+// nodeFormats are the output formats for node costs
+var nodeFormats = []string{"table", "json", "csv"}
+
+func runNodes(cmd *cobra.Command, args []string) error {
+	if err := checkOutputFormat(nodeFormats); err != nil {
+		return err
+	}
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	nodes, err := k8s.FetchNodes(ctx, client)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		fmt.Fprintln(os.Stderr, "No nodes found")
+		return nil
+	}
+	pods, err := k8s.FetchPods(ctx, client, metav1.NamespaceAll)
+	if err != nil {
+		return err
+	}
+
+	costs := calculateNodeCosts(nodes, pods, nodePricer(rates, nodes))
+
+	fmt.Fprintf(os.Stderr, "Analyzing %d nodes:\n\n", len(nodes))
+	return writeReport(func(w io.Writer) error {
+		switch outputFormat {
+		case "json":
+			if err := reporter.PrintNodeJSON(w, costs); err != nil {
+				return fmt.Errorf("failed to output JSON: %w", err)
+			}
+		case "csv":
+			if err := reporter.PrintNodeCSV(w, costs); err != nil {
+				return fmt.Errorf("failed to output CSV: %w", err)
+			}
+		default:
+			reporter.PrintNodeTable(w, costs)
+			fmt.Fprintf(w, "\nNote: Idle cost prices allocatable capacity that no pod requests.\n")
+		}
+		return nil
+	})
+}
+
+// loadIdle prices the capacity of every node that no pod requests, given the pods of
+// the whole cluster. With distribute, each node's idle cost is shared out across the
+// namespaces with pods on it.
+func loadIdle(ctx context.Context, client *kubernetes.Clientset, p pricer, clusterPods []corev1.Pod, distribute bool) (*analyzer.Idle, error) {
+	nodes, err := k8s.FetchNodes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if p.byNode == nil && len(p.rates.Instances) > 0 {
+		p = nodePricer(p.rates, nodes)
+	}
+
+	idle := &analyzer.Idle{Nodes: calculateNodeCosts(nodes, clusterPods, p)}
+	if distribute {
+		// Shares follow CPU and memory request costs, so pods are priced
+		// without storage or usage
+		idle.Shares = analyzer.DistributeIdle(idle.Nodes, priceQuietly(clusterPods, pricer{rates: p.rates, byNode: p.byNode}))
+	}
+	return idle, nil
+}
+
+// clusterPods returns the pods of every namespace, reusing the analyzed pods when
+// they already cover the cluster
+func clusterPods(ctx context.Context, client *kubernetes.Clientset, pods []corev1.Pod) ([]corev1.Pod, error) {
+	if allNamespaces {
+		return pods, nil
+	}
+	return k8s.FetchPods(ctx, client, metav1.NamespaceAll)
+}
+
+// priceQuietly prices pods like calculatePodCosts, without warning about unpriced
+// extended resources; it is used for pods outside the report
+func priceQuietly(pods []corev1.Pod, p pricer) []calculator.PodCost {
+	costs := make([]calculator.PodCost, 0, len(pods))
+	for _, pod := range pods {
+		if cost, ok := podCost(pod, p.storageFor(pod), p, make(map[string]bool)); ok {
+			costs = append(costs, cost)
+		}
+	}
+	return costs
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputFile string
+	reportTop  int
+)
+
+// addOutputFileFlag registers --output-file on a command that writes a report
+func addOutputFileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the report to this file instead of standard output")
+}
+
+// addReportFlags registers the flags shared by commands that render cost reports:
+// --baseline to compare against a saved JSON report and --top for summary formats
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&baselineFile, "baseline", "", "JSON report to show cost changes against")
+	cmd.Flags().IntVar(&reportTop, "top", reporter.DefaultMarkdownTop, "Pods or workloads listed before the rest are collapsed in markdown output")
+}
+
+// writeReport runs render against standard output or, with --output-file, against
+// a buffer that is written to the file once complete, so a failed run leaves no
+// partial report behind. Progress messages go to stderr either way.
+func writeReport(render func(w io.Writer) error) error {
+	if outputFile == "" {
+		return render(os.Stdout)
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputFile, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", outputFile)
+	return nil
+}
+
+// renderReport writes a cost report in the --output format, compared with the
+// --baseline report when one is given
+func renderReport(r reporter.Report) error {
+	rep, err := reporter.Lookup(outputFormat)
+	if err != nil {
+		return err
+	}
+	r.Top = reportTop
+	if baselineFile != "" {
+		baseline, err := readSavedReport(baselineFile)
+		if err != nil {
+			return err
+		}
+		current := r.Workloads
+		if !r.ByWorkload() {
+			current = analyzer.AggregateByOwner(r.Pods)
+		}
+		diff := analyzer.DiffWorkloads(baseline.Workloads, current)
+		r.Diff = &diff
+	}
+	return writeReport(func(w io.Writer) error {
+		if err := rep.Report(w, r); err != nil {
+			return fmt.Errorf("failed to output %s: %w", outputFormat, err)
+		}
+		return nil
+	})
+}
+
+// outputFormatUsage is the --output help for commands that render cost reports
+func outputFormatUsage() string {
+	return formatUsage(reporter.Formats())
+}
+
+// formatUsage is the --output help for a command that renders the given formats
+func formatUsage(formats []string) string {
+	return "Output format: " + strings.Join(formats, ", ")
+}
+
+// checkOutputFormat rejects an --output format outside formats. Commands call it
+// before fetching anything, so an unsupported format fails without doing the work.
+func checkOutputFormat(formats []string) error {
+	if slices.Contains(formats, outputFormat) {
+		return nil
+	}
+	return fmt.Errorf("unsupported output format: %s (supported: %s)", outputFormat, strings.Join(formats, ", "))
+}
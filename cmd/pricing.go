@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/usage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// pricer picks the rates for each pod: rates derived from the instance catalog for
+// the node the pod runs on where known, the flat rates otherwise
+type pricer struct {
+	rates  calculator.Rates
+	byNode map[string]calculator.Rates
+	// claims holds each bound claim's cost and the number of pods sharing it,
+	// keyed by namespace/name; nil when storage is not priced
+	claims map[string]*claimShare
+	// usage holds each pod's average usage keyed by namespace/name; nil unless --usage
+	usage map[string]usage.Mean
+	// owners resolves pods to their top-level controllers; when nil, owners are
+	// inferred from the pods' own references
+	owners k8s.OwnerIndex
+}
+
+type claimShare struct {
+	cost calculator.ClaimCost
+	pods int
+}
+
+// flatPricer prices every pod with the same rates
+func flatPricer(rates calculator.Rates) pricer {
+	return pricer{rates: rates}
+}
+
+// newPricer derives per-node rates from the instance catalog. Nodes are only listed
+// when the rates file has a catalog; if they cannot be listed, flat rates are used.
+func newPricer(ctx context.Context, client *kubernetes.Clientset, rates calculator.Rates) pricer {
+	p := flatPricer(rates)
+	if len(rates.Instances) == 0 {
+		return p
+	}
+
+	nodes, err := k8s.FetchNodes(ctx, client)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using flat rates for all pods\n", err)
+		return p
+	}
+	return nodePricer(rates, nodes)
+}
+
+// nodePricer derives per-node rates for the given nodes from the instance catalog
+func nodePricer(rates calculator.Rates, nodes []corev1.Node) pricer {
+	p := flatPricer(rates)
+	if len(rates.Instances) == 0 {
+		return p
+	}
+
+	p.byNode = make(map[string]calculator.Rates)
+	var unknown int
+	for _, node := range nodes {
+		provider, region, instanceType := k8s.NodeInstance(node)
+		nodeRates, ok := rates.ForInstance(calculator.Instance{
+			Provider:     provider,
+			Region:       region,
+			InstanceType: instanceType,
+		})
+		if !ok {
+			unknown++
+			continue
+		}
+		p.byNode[node.Name] = nodeRates
+	}
+	if unknown > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %d of %d nodes are not in the pricing catalog; their pods use flat rates\n", unknown, len(nodes))
+	}
+	return p
+}
+
+// loadStorage prices the bound claims in the analyzed namespaces and counts the pods
+// mounting each, so every pod is charged an equal share. Claims no pod mounts are
+// returned as orphaned. Storage is left out, with a warning, if claims cannot be listed.
+func (p *pricer) loadStorage(ctx context.Context, client *kubernetes.Clientset, namespaces []string, pods []corev1.Pod) []calculator.ClaimCost {
+	claims, err := k8s.FetchClaims(ctx, client, namespaces)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; storage costs are not included\n", err)
+		return nil
+	}
+	return p.priceClaims(claims, pods)
+}
+
+// priceClaims prices claims and shares each among the pods mounting it, returning
+// the claims no pod mounts
+func (p *pricer) priceClaims(claims []k8s.Claim, pods []corev1.Pod) []calculator.ClaimCost {
+	p.claims = make(map[string]*claimShare, len(claims))
+	for _, c := range claims {
+		p.claims[c.Namespace+"/"+c.Name] = &claimShare{
+			cost: calculator.CalculateClaimCost(c.Name, c.Namespace, c.StorageClass, c.Capacity, p.rates),
+		}
+	}
+	for _, pod := range pods {
+		for _, name := range k8s.PodClaimNames(pod) {
+			if share, ok := p.claims[pod.Namespace+"/"+name]; ok {
+				share.pods++
+			}
+		}
+	}
+
+	var orphans []calculator.ClaimCost
+	for _, share := range p.claims {
+		if share.pods == 0 {
+			orphans = append(orphans, share.cost)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Monthly != orphans[j].Monthly {
+			return orphans[i].Monthly > orphans[j].Monthly
+		}
+		return orphans[i].Namespace+"/"+orphans[i].Name < orphans[j].Namespace+"/"+orphans[j].Name
+	})
+	return orphans
+}
+
+// loadUsage reads the usage of pods in the analyzed namespaces from source
+func (p *pricer) loadUsage(ctx context.Context, source usage.Source, namespaces []string) error {
+	series, err := source.ContainerUsage(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+	p.usage = usage.PodMeans(series)
+	return nil
+}
+
+// loadOwners indexes ReplicaSets and Jobs so pods can be rolled up to their
+// Deployments and CronJobs. Without it, owners are inferred from the pods alone.
+func (p *pricer) loadOwners(ctx context.Context, client *kubernetes.Clientset, namespaces []string) {
+	owners, err := k8s.FetchOwnerIndex(ctx, client, namespaces)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; owners are inferred from pod references\n", err)
+		return
+	}
+	p.owners = owners
+}
+
+// templateStorage returns the hourly cost of the claims each replica of a workload
+// gets from its volume claim templates
+func (p pricer) templateStorage(w k8s.Workload) float64 {
+	var hourly float64
+	for _, c := range w.Claims {
+		hourly += calculator.CalculateClaimCost(c.Name, c.Namespace, c.StorageClass, c.Capacity, p.rates).Hourly
+	}
+	return hourly
+}
+
+// storageFor returns a pod's hourly share of the claims it mounts
+func (p pricer) storageFor(pod corev1.Pod) float64 {
+	var hourly float64
+	for _, name := range k8s.PodClaimNames(pod) {
+		if share, ok := p.claims[pod.Namespace+"/"+name]; ok && share.pods > 0 {
+			hourly += share.cost.Hourly / float64(share.pods)
+		}
+	}
+	return hourly
+}
+
+// ratesFor returns the rates for a pod's node, falling back to the flat rates
+func (p pricer) ratesFor(pod corev1.Pod) calculator.Rates {
+	return p.nodeRates(pod.Spec.NodeName)
+}
+
+// nodeRates returns the rates for a node, falling back to the flat rates
+func (p pricer) nodeRates(name string) calculator.Rates {
+	if nodeRates, ok := p.byNode[name]; ok {
+		return nodeRates
+	}
+	return p.rates
+}
+
+// calculateNodeCosts prices each node's allocatable capacity and the part of it
+// left unrequested by pods, at the node's rates
+func calculateNodeCosts(nodes []corev1.Node, pods []corev1.Pod, p pricer) []calculator.NodeCost {
+	allocs := k8s.NodeAllocations(nodes, pods)
+	costs := make([]calculator.NodeCost, len(allocs))
+	for i, a := range allocs {
+		costs[i] = calculator.CalculateNodeCost(a.Name, a.InstanceType, a.Pods,
+			a.CPUAllocatable, a.MemoryAllocatable, a.CPURequested, a.MemoryRequested, p.nodeRates(a.Name))
+	}
+	return costs
+}
+
+// calculatePodCosts prices each pod's requests, skipping pods that request nothing
+func calculatePodCosts(pods []corev1.Pod, p pricer) []calculator.PodCost {
+	unpriced := make(map[string]bool)
+	podCosts := make([]calculator.PodCost, 0, len(pods))
+	for _, pod := range pods {
+		if cost, ok := podCost(pod, p.storageFor(pod), p, unpriced); ok {
+			podCosts = append(podCosts, cost)
+		}
+	}
+	warnUnpriced(unpriced)
+	return podCosts
+}
+
+// calculateWorkloadCosts prices each workload's pod template at its desired replicas.
+// Workloads whose template requests nothing are skipped, as with pods. Templates are
+// not bound to a node, so they are priced at the flat rates. Each replica is also
+// charged for the claims it gets from volume claim templates.
+func calculateWorkloadCosts(workloads []k8s.Workload, p pricer) []calculator.WorkloadCost {
+	unpriced := make(map[string]bool)
+	costs := make([]calculator.WorkloadCost, 0, len(workloads))
+	for _, w := range workloads {
+		pod := w.Pod()
+		perReplica, ok := podCost(pod, p.storageFor(pod)+p.templateStorage(w), p, unpriced)
+		if !ok {
+			continue
+		}
+		costs = append(costs, calculator.NewWorkloadCost(w.Kind, w.Replicas, perReplica))
+	}
+	warnUnpriced(unpriced)
+	return costs
+}
+
+// podCost prices one pod and its hourly storage, recording requested extended
+// resources that have no rate
+func podCost(pod corev1.Pod, storage float64, p pricer, unpriced map[string]bool) (calculator.PodCost, bool) {
+	res := k8s.ExtractResources(pod)
+
+	cpuQty, _ := resource.ParseQuantity(res.CPURequest)
+	memQty, _ := resource.ParseQuantity(res.MemoryRequest)
+
+	if cpuQty.IsZero() && memQty.IsZero() && len(res.Extended) == 0 && storage == 0 {
+		return calculator.PodCost{}, false
+	}
+
+	rates := p.ratesFor(pod)
+	var extended map[string]resource.Quantity
+	if len(res.Extended) > 0 {
+		extended = make(map[string]resource.Quantity, len(res.Extended))
+		for name, value := range res.Extended {
+			extended[name] = resource.MustParse(value)
+			if _, ok := rates.ExtendedResources[name]; !ok {
+				unpriced[name] = true
+			}
+		}
+	}
+
+	cost := calculator.CalculatePodCostWithExtended(pod.Name, pod.Namespace, cpuQty, memQty, extended, rates)
+	cost.AddStorage(storage)
+	owner := p.owners.Owner(pod)
+	cost.OwnerKind, cost.OwnerName = owner.Kind, owner.Name
+	cost.Labels, cost.Annotations = pod.Labels, pod.Annotations
+	if !k8s.PodFinished(pod) {
+		cost.Node = pod.Spec.NodeName
+	}
+	if rule := res.RequestRule(); rule != k8s.RuleContainers {
+		cost.RequestRule = rule
+	}
+	if mean, ok := p.usage[pod.Namespace+"/"+pod.Name]; ok {
+		cpuUsed := *resource.NewMilliQuantity(int64(math.Round(mean.CPU*1000)), resource.DecimalSI)
+		memUsed := *resource.NewQuantity(int64(math.Round(mean.Memory)), resource.BinarySI)
+		used := calculator.CalculateUsageCost(cpuQty, memQty, cpuUsed, memUsed, rates)
+		cost.Usage = &used
+	}
+	return cost, true
+}
+
+// warnUnpriced tells the user which requested extended resources were priced at zero
+func warnUnpriced(unpriced map[string]bool) {
+	if len(unpriced) == 0 {
+		return
+	}
+	names := make([]string, 0, len(unpriced))
+	for name := range unpriced {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "Warning: no rate for %s; add it under extended_resources in the rates file. Priced at $0 for now.\n\n", strings.Join(names, ", "))
+}
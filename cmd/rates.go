@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/config"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/pricing"
+	"github.com/spf13/cobra"
+)
+
+var ratesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "Inspect, check and import pricing rates files",
+}
+
+var ratesValidateCmd = &cobra.Command{
+	Use:   "validate [FILE]",
+	Short: "Check a rates file against the schema",
+	Long: `Check a rates file against the schema, reporting every problem with its line and column.
+
+Without FILE, the rates file kcost would use (--rates-file, $KCOST_RATES, the XDG
+config dir, then the built-in rates) is checked.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRatesValidate,
+}
+
+var ratesImportCmd = &cobra.Command{
+	Use:   "import --format FORMAT FILE",
+	Short: "Convert a downloaded pricing dump into a rates file",
+	Long: `Convert a locally downloaded pricing dump into a normalized rates file.
+
+Formats:
+  opencost   OpenCost/Kubecost custom pricing JSON (flat CPU and RAM rates)
+  aws-json   AWS Price List bulk offer file for AmazonEC2, e.g. a region's index.json
+  aws-csv    the same offer file in CSV form
+  gcp-skus   Cloud Billing Catalog SKU list for Compute Engine; pages may be concatenated
+
+AWS imports keep the Linux, shared-tenancy, on-demand price of every instance type
+as an instance catalog and derive the flat rates from --reference-instance. GCP
+imports use the vCPU and memory prices of one --machine-family.
+
+FILE may be "-" for standard input. The rates file is written to standard output
+unless --output-file is given:
+
+  kcost rates import --format aws-json --region us-east-1 index.json \
+    --output-file ~/.config/kcost/rates.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRatesImport,
+}
+
+var (
+	importFormat     string
+	importOutputFile string
+	importOpts       pricing.Options
+)
+
+var (
+	ratesFile  string
+	ratesDate  string
+	cpuRate    float64
+	memoryRate float64
+)
+
+func init() {
+	rootCmd.AddCommand(ratesCmd)
+	ratesCmd.AddCommand(ratesValidateCmd)
+	ratesValidateCmd.Flags().StringVar(&ratesFile, "rates-file", "", "Rates file to check when FILE is not given")
+
+	ratesCmd.AddCommand(ratesImportCmd)
+	ratesImportCmd.Flags().StringVar(&importFormat, "format", "", "Pricing dump format: "+strings.Join(pricing.Formats, ", "))
+	ratesImportCmd.Flags().StringVar(&importOutputFile, "output-file", "", "Write the rates file here instead of standard output")
+	ratesImportCmd.Flags().StringVar(&importOpts.Region, "region", "", "Only import prices for this region")
+	ratesImportCmd.Flags().StringVar(&importOpts.ReferenceInstance, "reference-instance", pricing.DefaultReferenceInstance, "AWS instance type the flat rates are derived from")
+	ratesImportCmd.Flags().StringVar(&importOpts.MachineFamily, "machine-family", "", "GCP machine family to take vCPU and memory prices from, e.g. E2")
+	_ = ratesImportCmd.MarkFlagRequired("format")
+}
+
+// addRateFlags registers the rates file and per-field override flags on a command
+func addRateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&ratesFile, "rates-file", "", "Rates file to use (default: $"+calculator.RatesEnvVar+", then ~/.config/kcost/rates.yaml, then built-in rates)")
+	cmd.Flags().StringVar(&ratesDate, "rates-date", "", "Price with the rates in effect on this date (YYYY-MM-DD, default today)")
+	cmd.Flags().Float64Var(&cpuRate, "cpu-rate", 0, "Cost per CPU core per hour (USD); overrides the rates file")
+	cmd.Flags().Float64Var(&memoryRate, "memory-rate", 0, "Cost per GB memory per hour (USD); overrides the rates file")
+}
+
+// loadRates resolves the rates file, selects the rates in effect on --rates-date and
+// applies any --cpu-rate/--memory-rate overrides.
+// The staleness warning only fires when a value from the file is actually used.
+func loadRates(cmd *cobra.Command) (calculator.Rates, error) {
+	file, err := calculator.LoadRates(ratesFile)
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+
+	at := time.Now()
+	if ratesDate != "" {
+		at, err = time.Parse(calculator.DateLayout, ratesDate)
+		if err != nil {
+			return calculator.Rates{}, fmt.Errorf("invalid --rates-date %q, expected YYYY-MM-DD", ratesDate)
+		}
+	}
+
+	rates := file.Rates.At(at)
+	overrideCPU := cmd.Flags().Changed("cpu-rate")
+	overrideMemory := cmd.Flags().Changed("memory-rate")
+	if overrideCPU {
+		rates.CPUPerCorePerHour = cpuRate
+	}
+	if overrideMemory {
+		rates.MemoryPerGBPerHour = memoryRate
+	}
+
+	if !overrideCPU || !overrideMemory {
+		checkRateStaleness(file)
+	}
+
+	return rates, nil
+}
+
+func checkRateStaleness(file calculator.RatesFile) {
+	_, daysSince, err := file.LastUpdated()
+	if err != nil {
+		// Silently ignore rates files without a usable date
+		return
+	}
+
+	const staleThreshold = 180 // 6 months
+	if daysSince > staleThreshold {
+		fmt.Fprintf(os.Stderr, "Warning: Pricing rates from %s were last updated %d days ago.\n", file.Source(), daysSince)
+		if file.Path == "" {
+			fmt.Fprintf(os.Stderr, "Consider providing current rates with --rates-file or $%s, or use --cpu-rate and --memory-rate flags.\n\n", calculator.RatesEnvVar)
+		} else {
+			fmt.Fprintf(os.Stderr, "Consider updating %s or use --cpu-rate and --memory-rate flags.\n\n", file.Path)
+		}
+	}
+}
+
+func runRatesValidate(cmd *cobra.Command, args []string) error {
+	explicitPath := ratesFile
+	if len(args) == 1 {
+		explicitPath = args[0]
+	}
+
+	path := calculator.FindRatesFile(explicitPath)
+	source := calculator.RatesFile{Path: path}.Source()
+	data := config.DefaultRates
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read rates file: %w", err)
+		}
+	}
+
+	errs := calculator.ValidateRates(data)
+	for _, e := range errs {
+		if e.Line > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s:%d:%d: %s\n", source, e.Line, e.Column, e.Message)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", source, e.Message)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %d problem(s) found", source, len(errs))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s: OK\n", source)
+	return nil
+}
+
+func runRatesImport(cmd *cobra.Command, args []string) error {
+	input := cmd.InOrStdin()
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open pricing dump: %w", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	rates, err := pricing.Import(importFormat, input, importOpts)
+	if err != nil {
+		return fmt.Errorf("failed to import %s pricing: %w", importFormat, err)
+	}
+
+	header := fmt.Sprintf("Generated by: kcost rates import --format %s %s\nRe-run the command on a newer dump to refresh these rates.", importFormat, filepath.Base(args[0]))
+	var buf bytes.Buffer
+	if err := pricing.Write(&buf, rates, header); err != nil {
+		return err
+	}
+	// Guard against writing a file that kcost would refuse to load
+	if errs := calculator.ValidateRates(buf.Bytes()); len(errs) > 0 {
+		return fmt.Errorf("imported rates are invalid: %w", errs)
+	}
+
+	if importOutputFile == "" {
+		_, err := cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(importOutputFile), 0o755); err != nil {
+		return fmt.Errorf("failed to create rates directory: %w", err)
+	}
+	if err := os.WriteFile(importOutputFile, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write rates file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s (%d instance types)\n", importOutputFile, len(rates.Instances))
+	return nil
+}
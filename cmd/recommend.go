@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/recommend"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Recommend right-sized container requests from observed usage",
+	Long: `Compare each container's requests with its observed usage and propose new
+requests and limits, with the projected monthly saving.
+
+Requests are sized for the --percentile of the usage samples plus --headroom.
+Limits keep their current ratio to the request; containers without limits get none.
+
+Usage is sampled from metrics-server --samples times, --interval apart. A single
+sample is a snapshot; take several to cover a service's normal variation, or read
+--usage-range of history from Prometheus with --prometheus-url.`,
+	Args: cobra.NoArgs,
+	RunE: runRecommend,
+}
+
+var (
+	recommendOpts     = recommend.DefaultOptions()
+	recommendHeadroom float64
+)
+
+func init() {
+	rootCmd.AddCommand(recommendCmd)
+	recommendCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to analyze (repeatable)")
+	recommendCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Analyze namespaces matching this label selector")
+	recommendCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Analyze pods in all namespaces")
+	recommendCmd.Flags().Float64Var(&recommendOpts.Percentile, "percentile", recommendOpts.Percentile, "Usage percentile (0-100] to size requests for")
+	recommendCmd.Flags().Float64Var(&recommendHeadroom, "headroom", recommendOpts.Headroom*100, "Headroom in percent added on top of the percentile")
+	addUsageFlags(recommendCmd)
+	addRateFlags(recommendCmd)
+	recommendCmd.Flags().StringVarP(&outputFormat, "output", "o", "table", formatUsage(recommendFormats))
+	addOutputFileFlag(recommendCmd)
+}
+
+// recommendFormats are the output formats for recommendations
+var recommendFormats = []string{"table", "json", "csv"}
+
+func runRecommend(cmd *cobra.Command, args []string) error {
+	if err := checkOutputFormat(recommendFormats); err != nil {
+		return err
+	}
+	if recommendOpts.Percentile <= 0 || recommendOpts.Percentile > 100 {
+		return fmt.Errorf("--percentile must be in (0, 100], got %g", recommendOpts.Percentile)
+	}
+	if recommendHeadroom < 0 {
+		return fmt.Errorf("--headroom must not be negative, got %g", recommendHeadroom)
+	}
+	recommendOpts.Headroom = recommendHeadroom / 100
+
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	targets, err := resolveNamespaces(ctx, cmd, client)
+	if err != nil {
+		return err
+	}
+
+	var pods []corev1.Pod
+	if allNamespaces || len(targets) > 0 {
+		pods, err = k8s.FetchPodsInNamespaces(ctx, client, targets)
+		if err != nil {
+			return err
+		}
+	}
+	scope := describeScope(targets)
+	if len(pods) == 0 {
+		fmt.Fprintf(os.Stderr, "No pods found in %s\n", scope)
+		return nil
+	}
+
+	source, err := newUsageSource(client)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Reading %s...\n", describeUsageSource())
+	series, err := source.ContainerUsage(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	p := newPricer(ctx, client, rates)
+	recs := recommend.Recommend(pods, series, recommendOpts, p.ratesFor)
+	if len(recs) == 0 {
+		fmt.Fprintf(os.Stderr, "No usage samples for pods in %s\n", scope)
+		return nil
+	}
+
+	// Progress goes to stderr so reports can be piped or written with --output-file
+	fmt.Fprintf(os.Stderr, "Recommending requests for %d containers in %s (p%g + %g%% headroom):\n\n",
+		len(recs), scope, recommendOpts.Percentile, recommendHeadroom)
+
+	return writeReport(func(w io.Writer) error {
+		switch outputFormat {
+		case "json":
+			if err := reporter.PrintRecommendationJSON(w, recs, recommendOpts); err != nil {
+				return fmt.Errorf("failed to output JSON: %w", err)
+			}
+		case "csv":
+			if err := reporter.PrintRecommendationCSV(w, recs); err != nil {
+				return fmt.Errorf("failed to output CSV: %w", err)
+			}
+		default:
+			reporter.PrintRecommendationTable(w, recs)
+			if prometheusURL == "" && usageSamples == 1 {
+				fmt.Fprintf(w, "\nNote: Based on a single metrics-server sample; use --samples to observe usage over time.\n")
+			}
+		}
+		return nil
+	})
+}
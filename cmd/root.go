@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
 var rootCmd = &cobra.Command{
@@ -20,10 +23,44 @@ of running your workloads based on CPU and memory requests.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "kcost: %v\n", err)
+		var exit exitError
+		if errors.As(err, &exit) {
+			os.Exit(exit.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitError is an error that exits with a specific code rather than 1
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
+var clientOpts k8s.ClientOptions
+
 func init() {
-	// TODO: global flags (--kubeconfig, --context)
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&clientOpts.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config)")
+	flags.StringVar(&clientOpts.Context, "context", "", "Kubeconfig context to use")
+	flags.StringVar(&clientOpts.Cluster, "cluster", "", "Kubeconfig cluster to use")
+	flags.StringVar(&clientOpts.User, "user", "", "Kubeconfig user to use")
+	flags.StringVar(&clientOpts.RequestTimeout, "request-timeout", "", "Timeout for a single server request (e.g. 10s, 1m); 0 means no timeout")
+}
+
+// newClient builds a Kubernetes client from the global connection flags
+func newClient() (*kubernetes.Clientset, error) {
+	client, err := k8s.NewClient(clientOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	return client, nil
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// sharedCostsFile is the --shared-costs configuration file
+var sharedCostsFile string
+
+// loadShared splits the cost of the shared namespaces across the others according to
+// cfg. The split is computed over the whole cluster, including storage and any
+// distributed idle cost, so that analyzing some namespaces shows their fair share.
+func loadShared(ctx context.Context, client *kubernetes.Clientset, cfg analyzer.SharedCosts, p pricer, clusterPods []corev1.Pod, idle *analyzer.Idle) (*analyzer.Shared, error) {
+	shared := make(map[string]bool)
+	for _, ns := range cfg.Namespaces {
+		shared[ns] = true
+	}
+	for _, selector := range cfg.Selectors {
+		matched, err := k8s.ListNamespaces(ctx, client, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range matched {
+			shared[ns] = true
+		}
+	}
+
+	cluster := pricer{rates: p.rates, byNode: p.byNode}
+	orphans := cluster.loadStorage(ctx, client, nil, clusterPods)
+	summaries := analyzer.AddOrphanedClaims(analyzer.AggregateByNamespace(priceQuietly(clusterPods, cluster)), orphans)
+	summaries = analyzer.Allocations{Idle: idle}.Apply(summaries)
+	return analyzer.ShareCosts(summaries, shared, cfg), nil
+}
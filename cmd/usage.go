@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/usage"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	usageSamples        int
+	usageInterval       time.Duration
+	prometheusURL       string
+	prometheusHeaders   []string
+	prometheusTokenFile string
+	usageRange          string
+	usageStep           time.Duration
+)
+
+// addUsageFlags adds the flags selecting where observed usage comes from:
+// metrics-server by default, Prometheus when --prometheus-url is set
+func addUsageFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&usageSamples, "samples", 1, "Number of metrics-server samples to take")
+	cmd.Flags().DurationVar(&usageInterval, "interval", 30*time.Second, "Time between metrics-server samples")
+	cmd.Flags().StringVar(&prometheusURL, "prometheus-url", "", "Read usage history from this Prometheus-compatible server instead of metrics-server")
+	cmd.Flags().StringArrayVar(&prometheusHeaders, "prometheus-header", nil, `HTTP header for Prometheus requests, e.g. "X-Scope-OrgID: team-a" (repeatable)`)
+	cmd.Flags().StringVar(&prometheusTokenFile, "prometheus-token-file", "", "File holding a bearer token for Prometheus requests")
+	cmd.Flags().StringVar(&usageRange, "usage-range", "7d", "How far back to read Prometheus usage (e.g. 7d, 36h)")
+	cmd.Flags().DurationVar(&usageStep, "usage-step", 5*time.Minute, "Resolution of Prometheus usage history")
+}
+
+// newUsageSource builds the usage source selected by the usage flags
+func newUsageSource(client *kubernetes.Clientset) (usage.Source, error) {
+	if prometheusURL == "" {
+		if usageSamples < 1 {
+			return nil, fmt.Errorf("--samples must be at least 1, got %d", usageSamples)
+		}
+		return usage.MetricsServer{Client: client, Samples: usageSamples, Interval: usageInterval}, nil
+	}
+
+	lookback, err := parseRange(usageRange)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(http.Header)
+	for _, h := range prometheusHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf(`invalid --prometheus-header %q, expected "Name: value"`, h)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if prometheusTokenFile != "" {
+		token, err := os.ReadFile(prometheusTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prometheus token file: %w", err)
+		}
+		headers.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	return usage.Prometheus{
+		URL:     prometheusURL,
+		Headers: headers,
+		Range:   lookback,
+		Step:    usageStep,
+	}, nil
+}
+
+// describeUsageSource names the usage source for progress messages and notes
+func describeUsageSource() string {
+	if prometheusURL != "" {
+		return fmt.Sprintf("Prometheus usage over the last %s", usageRange)
+	}
+	if usageSamples == 1 {
+		return "a single metrics-server sample"
+	}
+	return fmt.Sprintf("%d metrics-server samples, %s apart", usageSamples, usageInterval)
+}
+
+// parseRange parses a Go duration, also accepting whole days such as 7d
+func parseRange(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --usage-range %q, expected a positive duration such as 7d or 36h", s)
+	}
+	return d, nil
+}
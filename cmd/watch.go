@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Show pod costs live as the cluster changes",
+	Long: `Keep pods, nodes, workloads and claims in memory through watches and redraw
+the cost table whenever they change, until interrupted.
+
+Changes arriving within --interval of each other are shown in one redraw. On a
+terminal the screen is cleared before each redraw; otherwise each table is
+appended to the output. --top limits the table to the most expensive pods; the
+namespace summary always covers every pod.`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+var (
+	watchInterval time.Duration
+	watchTop      int
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringSliceVarP(&namespaces, "namespace", "n", []string{"default"}, "Namespace to watch (repeatable)")
+	watchCmd.Flags().StringVar(&namespaceSelector, "namespace-selector", "", "Watch namespaces matching this label selector, resolved at startup")
+	watchCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Watch pods in all namespaces")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 2*time.Second, "Shortest time between redraws")
+	watchCmd.Flags().IntVar(&watchTop, "top", 20, "Pods shown, most expensive first (0 for all)")
+	addRateFlags(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	rates, err := loadRates(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	c, targets, err := startCache(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	// The adds of the initial lists are already reflected in the first table
+	drainEvents(c)
+	inPlace := isTerminal(os.Stdout)
+	var previous *float64
+	draw := func() {
+		total := drawWatch(os.Stdout, c, rates, describeScope(targets), inPlace, previous)
+		previous = &total
+	}
+
+	draw()
+	var redraw <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.Events():
+			if redraw == nil {
+				redraw = time.After(watchInterval)
+			}
+		case <-redraw:
+			redraw = nil
+			drainEvents(c)
+			draw()
+		}
+	}
+}
+
+// drawWatch prices the cached pods and writes the header, cost table and namespace
+// summary, returning the monthly total. inPlace clears the screen first; previous
+// is the total of the last redraw, for showing the change.
+func drawWatch(w io.Writer, c *k8s.Cache, rates calculator.Rates, scope string, inPlace bool, previous *float64) float64 {
+	pods, orphans := priceCache(c, rates)
+	pods = analyzer.SortByMonthlyCost(pods)
+	summaries := analyzer.AddOrphanedClaims(analyzer.AggregateByNamespace(pods), orphans)
+	total := analyzer.Total(summaries).MonthlyCost
+
+	if inPlace {
+		// Move the cursor home and clear the screen
+		fmt.Fprint(w, "\033[H\033[2J")
+	} else {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%s  %d pods in %s  $%.2f/month", time.Now().Format("15:04:05"), len(pods), scope, total)
+	if previous != nil {
+		if delta := total - *previous; delta >= 0.005 || delta <= -0.005 {
+			fmt.Fprintf(w, " (%s)", formatMonthlyDelta(delta))
+		}
+	}
+	fmt.Fprint(w, "\n\n")
+
+	shown := pods
+	if watchTop > 0 && len(shown) > watchTop {
+		shown = shown[:watchTop]
+	}
+	reporter.PrintCostTable(w, shown)
+	if len(shown) < len(pods) {
+		fmt.Fprintf(w, "... and %d more pods\n", len(pods)-len(shown))
+	}
+	reporter.PrintNamespaceSummary(w, summaries)
+	return total
+}
+
+func formatMonthlyDelta(delta float64) string {
+	if delta < 0 {
+		return fmt.Sprintf("-$%.2f/month", -delta)
+	}
+	return fmt.Sprintf("+$%.2f/month", delta)
+}
+
+// drainEvents discards pending events
+func drainEvents(c *k8s.Cache) {
+	for {
+		select {
+		case <-c.Events():
+		default:
+			return
+		}
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal, rather
+// than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
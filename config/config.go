@@ -0,0 +1,9 @@
+package config
+
+import _ "embed"
+
+// DefaultRates is config/rates.yaml compiled into the binary. It is used when no
+// rates file is found on disk.
+//
+//go:embed rates.yaml
+var DefaultRates []byte
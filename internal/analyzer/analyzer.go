@@ -7,33 +7,188 @@ import (
 )
 
 type NamespaceSummary struct {
-	Namespace    string
-	TotalPods    int
-	HourlyCost   float64
-	DailyCost    float64
-	MonthlyCost  float64
+	Namespace   string
+	TotalPods   int
+	HourlyCost  float64
+	DailyCost   float64
+	MonthlyCost float64
+	// OrphanedClaims counts bound PVCs no pod mounts; their cost is included
+	// in the totals above and broken out in OrphanedMonthlyCost
+	OrphanedClaims      int
+	OrphanedMonthlyCost float64
+	// UsageMonthlyCost prices the measured usage of the pods that have usage data;
+	// MeasuredMonthlyCost is the CPU and memory request cost of those same pods
+	UsageMonthlyCost    float64
+	MeasuredMonthlyCost float64
+	// IdleMonthlyCost is the namespace's share of unrequested node capacity,
+	// included in the totals above when idle cost is distributed
+	IdleMonthlyCost float64
+	// SharedMonthlyCost is the namespace's allocated share of the shared
+	// namespaces' cost, included in the totals above
+	SharedMonthlyCost float64
 }
 
-// AggregateByNamespace sums up costs for all pods in a namespace
-func AggregateByNamespace(costs []calculator.PodCost) NamespaceSummary {
-	if len(costs) == 0 {
-		return NamespaceSummary{}
+// DirectMonthlyCost returns the monthly cost before any shared-cost allocation
+func (s NamespaceSummary) DirectMonthlyCost() float64 {
+	return s.MonthlyCost - s.SharedMonthlyCost
+}
+
+// Efficiency returns usage cost as a fraction of the request cost of the pods with
+// usage data; ok is false when no pod has any
+func (s NamespaceSummary) Efficiency() (efficiency float64, ok bool) {
+	if s.MeasuredMonthlyCost <= 0 {
+		return 0, false
 	}
+	return s.UsageMonthlyCost / s.MeasuredMonthlyCost, true
+}
 
-	summary := NamespaceSummary{
-		Namespace: costs[0].Namespace,
-		TotalPods: len(costs),
+// AggregateByNamespace sums up pod costs per namespace.
+// Summaries are ordered by monthly cost (descending), then by namespace name.
+func AggregateByNamespace(costs []calculator.PodCost) []NamespaceSummary {
+	agg := newNamespaceAggregator()
+	for _, pc := range costs {
+		agg.add(pc.Namespace, 1, pc.Hourly, pc.Daily, pc.Monthly)
+		if pc.Usage != nil {
+			summary := agg.get(pc.Namespace)
+			summary.UsageMonthlyCost += pc.Usage.Monthly.TotalCost
+			summary.MeasuredMonthlyCost += pc.Monthly.CPUCost + pc.Monthly.MemoryCost
+		}
 	}
+	return agg.summaries()
+}
 
+// AggregateWorkloadsByNamespace sums up workload costs per namespace, counting
+// each workload's desired replicas as pods. Ordering matches AggregateByNamespace.
+func AggregateWorkloadsByNamespace(costs []calculator.WorkloadCost) []NamespaceSummary {
+	agg := newNamespaceAggregator()
+	for _, wc := range costs {
+		agg.add(wc.Namespace, int(wc.Replicas), wc.Hourly, wc.Daily, wc.Monthly)
+	}
+	return agg.summaries()
+}
+
+// MixedRequestRule marks an owner whose replicas' requests were set by different rules
+const MixedRequestRule = "mixed"
+
+// AggregateByOwner rolls pods up into their owning controllers, counting the pods
+// of each as its replicas. The request rule is kept when all replicas share it and
+// reported as "mixed" otherwise. Results are in order of first appearance.
+func AggregateByOwner(costs []calculator.PodCost) []calculator.WorkloadCost {
+	index := make(map[string]int)
+	var owners []calculator.WorkloadCost
 	for _, pc := range costs {
-		summary.HourlyCost += pc.Hourly.TotalCost
-		summary.DailyCost += pc.Daily.TotalCost
-		summary.MonthlyCost += pc.Monthly.TotalCost
+		kind, name := pc.OwnerKind, pc.OwnerName
+		if kind == "" {
+			kind, name = "Pod", pc.Name
+		}
+
+		key := pc.Namespace + "/" + kind + "/" + name
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(owners)
+			owners = append(owners, calculator.WorkloadCost{
+				Kind:        kind,
+				Name:        name,
+				Namespace:   pc.Namespace,
+				RequestRule: pc.RequestRule,
+			})
+			i = len(owners) - 1
+		}
+
+		owner := &owners[i]
+		owner.Replicas++
+		owner.Hourly = owner.Hourly.Add(pc.Hourly)
+		owner.Daily = owner.Daily.Add(pc.Daily)
+		owner.Monthly = owner.Monthly.Add(pc.Monthly)
+		if owner.RequestRule != pc.RequestRule {
+			owner.RequestRule = MixedRequestRule
+		}
 	}
+	return owners
+}
+
+// AddOrphanedClaims adds the cost of claims that no pod mounts to the namespace
+// summaries, creating summaries for namespaces that only hold orphaned claims.
+// The result is re-sorted by monthly cost.
+func AddOrphanedClaims(summaries []NamespaceSummary, orphans []calculator.ClaimCost) []NamespaceSummary {
+	if len(orphans) == 0 {
+		return summaries
+	}
+
+	agg := newNamespaceAggregator()
+	for _, s := range summaries {
+		summary := s
+		agg[s.Namespace] = &summary
+	}
+	for _, o := range orphans {
+		summary := agg.get(o.Namespace)
+		summary.OrphanedClaims++
+		summary.OrphanedMonthlyCost += o.Monthly
+		summary.HourlyCost += o.Hourly
+		summary.DailyCost += o.Daily
+		summary.MonthlyCost += o.Monthly
+	}
+	return agg.summaries()
+}
+
+type namespaceAggregator map[string]*NamespaceSummary
+
+func newNamespaceAggregator() namespaceAggregator {
+	return make(namespaceAggregator)
+}
 
+func (a namespaceAggregator) get(namespace string) *NamespaceSummary {
+	summary, ok := a[namespace]
+	if !ok {
+		summary = &NamespaceSummary{Namespace: namespace}
+		a[namespace] = summary
+	}
 	return summary
 }
 
+func (a namespaceAggregator) add(namespace string, pods int, hourly, daily, monthly calculator.ResourceCost) {
+	summary := a.get(namespace)
+	summary.TotalPods += pods
+	summary.HourlyCost += hourly.TotalCost
+	summary.DailyCost += daily.TotalCost
+	summary.MonthlyCost += monthly.TotalCost
+}
+
+func (a namespaceAggregator) summaries() []NamespaceSummary {
+	summaries := make([]NamespaceSummary, 0, len(a))
+	for _, summary := range a {
+		summaries = append(summaries, *summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].MonthlyCost != summaries[j].MonthlyCost {
+			return summaries[i].MonthlyCost > summaries[j].MonthlyCost
+		}
+		return summaries[i].Namespace < summaries[j].Namespace
+	})
+
+	return summaries
+}
+
+// Total sums namespace summaries into a cluster-wide grand total.
+// The returned summary has an empty Namespace.
+func Total(summaries []NamespaceSummary) NamespaceSummary {
+	var total NamespaceSummary
+	for _, s := range summaries {
+		total.TotalPods += s.TotalPods
+		total.HourlyCost += s.HourlyCost
+		total.DailyCost += s.DailyCost
+		total.MonthlyCost += s.MonthlyCost
+		total.OrphanedClaims += s.OrphanedClaims
+		total.OrphanedMonthlyCost += s.OrphanedMonthlyCost
+		total.UsageMonthlyCost += s.UsageMonthlyCost
+		total.MeasuredMonthlyCost += s.MeasuredMonthlyCost
+		total.IdleMonthlyCost += s.IdleMonthlyCost
+		total.SharedMonthlyCost += s.SharedMonthlyCost
+	}
+	return total
+}
+
 // SortByMonthlyCost sorts pod costs by monthly total (descending)
 func SortByMonthlyCost(costs []calculator.PodCost) []calculator.PodCost {
 	sorted := make([]calculator.PodCost, len(costs))
@@ -45,3 +200,15 @@ func SortByMonthlyCost(costs []calculator.PodCost) []calculator.PodCost {
 
 	return sorted
 }
+
+// SortWorkloadsByMonthlyCost sorts workload costs by monthly total (descending)
+func SortWorkloadsByMonthlyCost(costs []calculator.WorkloadCost) []calculator.WorkloadCost {
+	sorted := make([]calculator.WorkloadCost, len(costs))
+	copy(sorted, costs)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Monthly.TotalCost > sorted[j].Monthly.TotalCost
+	})
+
+	return sorted
+}
@@ -21,11 +21,7 @@ func TestAggregateByNamespace(t *testing.T) {
 	tests := []struct {
 		name          string
 		costs         []calculator.PodCost
-		wantNamespace string
-		wantPods      int
-		wantHourly    float64
-		wantDaily     float64
-		wantMonthly   float64
+		wantSummaries []NamespaceSummary
 	}{
 		{
 			name: "multiple pods",
@@ -45,48 +41,193 @@ func TestAggregateByNamespace(t *testing.T) {
 					Monthly:   calculator.ResourceCost{CPUCost: 14.6, MemoryCost: 7.3, TotalCost: 21.9},
 				},
 			},
-			wantNamespace: "default",
-			wantPods:      2,
-			wantHourly:    0.045,
-			wantDaily:     1.08,
-			wantMonthly:   32.85,
+			wantSummaries: []NamespaceSummary{
+				{Namespace: "default", TotalPods: 2, HourlyCost: 0.045, DailyCost: 1.08, MonthlyCost: 32.85},
+			},
+		},
+		{
+			name: "multiple namespaces ordered by monthly cost",
+			costs: []calculator.PodCost{
+				{Name: "a", Namespace: "team-a", Hourly: calculator.ResourceCost{TotalCost: 0.01}, Daily: calculator.ResourceCost{TotalCost: 0.24}, Monthly: calculator.ResourceCost{TotalCost: 7.3}},
+				{Name: "b1", Namespace: "team-b", Hourly: calculator.ResourceCost{TotalCost: 0.02}, Daily: calculator.ResourceCost{TotalCost: 0.48}, Monthly: calculator.ResourceCost{TotalCost: 14.6}},
+				{Name: "b2", Namespace: "team-b", Hourly: calculator.ResourceCost{TotalCost: 0.02}, Daily: calculator.ResourceCost{TotalCost: 0.48}, Monthly: calculator.ResourceCost{TotalCost: 14.6}},
+			},
+			wantSummaries: []NamespaceSummary{
+				{Namespace: "team-b", TotalPods: 2, HourlyCost: 0.04, DailyCost: 0.96, MonthlyCost: 29.2},
+				{Namespace: "team-a", TotalPods: 1, HourlyCost: 0.01, DailyCost: 0.24, MonthlyCost: 7.3},
+			},
 		},
 		{
 			name:          "empty input",
 			costs:         []calculator.PodCost{},
-			wantNamespace: "",
-			wantPods:      0,
-			wantHourly:    0,
-			wantDaily:     0,
-			wantMonthly:   0,
+			wantSummaries: []NamespaceSummary{},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			summary := AggregateByNamespace(tt.costs)
+			summaries := AggregateByNamespace(tt.costs)
 
-			if summary.Namespace != tt.wantNamespace {
-				t.Errorf("namespace: got %q, want %q", summary.Namespace, tt.wantNamespace)
+			if len(summaries) != len(tt.wantSummaries) {
+				t.Fatalf("summary count: got %d, want %d", len(summaries), len(tt.wantSummaries))
 			}
 
-			if summary.TotalPods != tt.wantPods {
-				t.Errorf("total pods: got %d, want %d", summary.TotalPods, tt.wantPods)
+			for i, want := range tt.wantSummaries {
+				assertSummary(t, summaries[i], want)
 			}
+		})
+	}
+}
 
-			if math.Abs(summary.HourlyCost-tt.wantHourly) > hourlyTolerance {
-				t.Errorf("hourly cost: got %.4f, want %.4f", summary.HourlyCost, tt.wantHourly)
-			}
+func TestTotal(t *testing.T) {
+	t.Parallel()
+	summaries := []NamespaceSummary{
+		{Namespace: "team-b", TotalPods: 2, HourlyCost: 0.04, DailyCost: 0.96, MonthlyCost: 29.2},
+		{Namespace: "team-a", TotalPods: 1, HourlyCost: 0.01, DailyCost: 0.24, MonthlyCost: 7.3},
+	}
 
-			if math.Abs(summary.DailyCost-tt.wantDaily) > dailyMonthlyTolerance {
-				t.Errorf("daily cost: got %.2f, want %.2f", summary.DailyCost, tt.wantDaily)
-			}
+	assertSummary(t, Total(summaries), NamespaceSummary{TotalPods: 3, HourlyCost: 0.05, DailyCost: 1.2, MonthlyCost: 36.5})
+	assertSummary(t, Total(nil), NamespaceSummary{})
+}
 
-			if math.Abs(summary.MonthlyCost-tt.wantMonthly) > dailyMonthlyTolerance {
-				t.Errorf("monthly cost: got %.2f, want %.2f", summary.MonthlyCost, tt.wantMonthly)
-			}
-		})
+func TestAddOrphanedClaims(t *testing.T) {
+	t.Parallel()
+	summaries := AggregateByNamespace([]calculator.PodCost{
+		{Name: "api", Namespace: "team-a", Hourly: calculator.ResourceCost{TotalCost: 0.01}, Daily: calculator.ResourceCost{TotalCost: 0.24}, Monthly: calculator.ResourceCost{TotalCost: 7.3}},
+	})
+	orphans := []calculator.ClaimCost{
+		{Name: "old-data", Namespace: "team-a", Hourly: 0.01, Daily: 0.24, Monthly: 7.3},
+		{Name: "backup", Namespace: "team-b", Hourly: 0.1, Daily: 2.4, Monthly: 73},
+	}
+
+	got := AddOrphanedClaims(summaries, orphans)
+
+	want := []NamespaceSummary{
+		{Namespace: "team-b", TotalPods: 0, HourlyCost: 0.1, DailyCost: 2.4, MonthlyCost: 73, OrphanedClaims: 1, OrphanedMonthlyCost: 73},
+		{Namespace: "team-a", TotalPods: 1, HourlyCost: 0.02, DailyCost: 0.48, MonthlyCost: 14.6, OrphanedClaims: 1, OrphanedMonthlyCost: 7.3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("summary count: got %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		assertSummary(t, got[i], w)
+		if got[i].OrphanedClaims != w.OrphanedClaims {
+			t.Errorf("%s orphaned claims: got %d, want %d", w.Namespace, got[i].OrphanedClaims, w.OrphanedClaims)
+		}
+		if math.Abs(got[i].OrphanedMonthlyCost-w.OrphanedMonthlyCost) > dailyMonthlyTolerance {
+			t.Errorf("%s orphaned monthly cost: got %.2f, want %.2f", w.Namespace, got[i].OrphanedMonthlyCost, w.OrphanedMonthlyCost)
+		}
+	}
+
+	if total := Total(got); total.OrphanedClaims != 2 {
+		t.Errorf("total orphaned claims: got %d, want 2", total.OrphanedClaims)
+	}
+}
+
+func TestAggregateByOwner(t *testing.T) {
+	t.Parallel()
+	pod := func(name, ns, kind, owner, rule string, monthly float64) calculator.PodCost {
+		return calculator.PodCost{
+			Name: name, Namespace: ns, OwnerKind: kind, OwnerName: owner, RequestRule: rule,
+			Monthly: calculator.ResourceCost{CPUCost: monthly, TotalCost: monthly},
+		}
+	}
+	costs := []calculator.PodCost{
+		pod("api-7d9f-a", "shop", "Deployment", "api", "", 10),
+		pod("worker-0", "shop", "StatefulSet", "worker", "init:migrate", 4),
+		pod("api-7d9f-b", "shop", "Deployment", "api", "", 10),
+		pod("api-5c4d-c", "shop", "Deployment", "api", "", 12),
+		pod("worker-1", "shop", "StatefulSet", "worker", "", 4),
+		pod("api-6b1e-a", "staging", "Deployment", "api", "", 5),
+		{Name: "debug", Namespace: "shop", Monthly: calculator.ResourceCost{TotalCost: 1}},
+	}
+
+	got := AggregateByOwner(costs)
+
+	want := []calculator.WorkloadCost{
+		{Kind: "Deployment", Name: "api", Namespace: "shop", Replicas: 3, Monthly: calculator.ResourceCost{TotalCost: 32}},
+		{Kind: "StatefulSet", Name: "worker", Namespace: "shop", Replicas: 2, Monthly: calculator.ResourceCost{TotalCost: 8}, RequestRule: MixedRequestRule},
+		{Kind: "Deployment", Name: "api", Namespace: "staging", Replicas: 1, Monthly: calculator.ResourceCost{TotalCost: 5}},
+		{Kind: "Pod", Name: "debug", Namespace: "shop", Replicas: 1, Monthly: calculator.ResourceCost{TotalCost: 1}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("owner count: got %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Kind != w.Kind || g.Name != w.Name || g.Namespace != w.Namespace {
+			t.Errorf("owner %d: got %s %s/%s, want %s %s/%s", i, g.Kind, g.Namespace, g.Name, w.Kind, w.Namespace, w.Name)
+		}
+		if g.Replicas != w.Replicas {
+			t.Errorf("%s replicas: got %d, want %d", w.Name, g.Replicas, w.Replicas)
+		}
+		if math.Abs(g.Monthly.TotalCost-w.Monthly.TotalCost) > dailyMonthlyTolerance {
+			t.Errorf("%s monthly cost: got %.2f, want %.2f", w.Name, g.Monthly.TotalCost, w.Monthly.TotalCost)
+		}
+		if g.RequestRule != w.RequestRule {
+			t.Errorf("%s request rule: got %q, want %q", w.Name, g.RequestRule, w.RequestRule)
+		}
+	}
+}
+
+func TestAggregateByNamespaceUsage(t *testing.T) {
+	t.Parallel()
+	costs := []calculator.PodCost{
+		{
+			Name: "api", Namespace: "team-a",
+			Monthly: calculator.ResourceCost{CPUCost: 30, MemoryCost: 10, StorageCost: 5, TotalCost: 45},
+			Usage:   &calculator.UsageCost{Monthly: calculator.ResourceCost{TotalCost: 8}},
+		},
+		{
+			// Not sampled by metrics-server yet: counted in cost, not in efficiency
+			Name: "worker", Namespace: "team-a",
+			Monthly: calculator.ResourceCost{CPUCost: 20, TotalCost: 20},
+		},
+		{
+			Name: "batch", Namespace: "team-b",
+			Monthly: calculator.ResourceCost{CPUCost: 10, TotalCost: 10},
+		},
+	}
+
+	summaries := AggregateByNamespace(costs)
+	if len(summaries) != 2 {
+		t.Fatalf("summary count: got %d, want 2", len(summaries))
+	}
+
+	efficiency, ok := summaries[0].Efficiency()
+	if !ok || math.Abs(efficiency-0.2) > hourlyTolerance {
+		t.Errorf("team-a efficiency: got %.4f (ok=%v), want 0.2000", efficiency, ok)
+	}
+	if _, ok := summaries[1].Efficiency(); ok {
+		t.Error("team-b efficiency: got ok, want not ok without usage data")
+	}
+	if total := Total(summaries); math.Abs(total.UsageMonthlyCost-8) > dailyMonthlyTolerance {
+		t.Errorf("total usage monthly cost: got %.2f, want 8.00", total.UsageMonthlyCost)
+	}
+}
+
+func assertSummary(t *testing.T, got, want NamespaceSummary) {
+	t.Helper()
+
+	if got.Namespace != want.Namespace {
+		t.Errorf("namespace: got %q, want %q", got.Namespace, want.Namespace)
+	}
+
+	if got.TotalPods != want.TotalPods {
+		t.Errorf("%s total pods: got %d, want %d", want.Namespace, got.TotalPods, want.TotalPods)
+	}
+
+	if math.Abs(got.HourlyCost-want.HourlyCost) > hourlyTolerance {
+		t.Errorf("%s hourly cost: got %.4f, want %.4f", want.Namespace, got.HourlyCost, want.HourlyCost)
+	}
+
+	if math.Abs(got.DailyCost-want.DailyCost) > dailyMonthlyTolerance {
+		t.Errorf("%s daily cost: got %.2f, want %.2f", want.Namespace, got.DailyCost, want.DailyCost)
+	}
+
+	if math.Abs(got.MonthlyCost-want.MonthlyCost) > dailyMonthlyTolerance {
+		t.Errorf("%s monthly cost: got %.2f, want %.2f", want.Namespace, got.MonthlyCost, want.MonthlyCost)
 	}
 }
 
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// Kinds of change between two sets of workloads
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// WorkloadDiff is a workload that was added, removed or changed in cost or replicas
+type WorkloadDiff struct {
+	Change    string
+	Namespace string
+	Kind      string
+	Name      string
+	// Before and After are zero on the side where the workload does not exist
+	BeforeReplicas int32
+	AfterReplicas  int32
+	Before         float64
+	After          float64
+}
+
+// Delta returns the change in monthly cost
+func (d WorkloadDiff) Delta() float64 {
+	return d.After - d.Before
+}
+
+// CostDiff compares the monthly cost of two sets of workloads
+type CostDiff struct {
+	Workloads []WorkloadDiff
+	// Unchanged counts workloads present on both sides with the same replicas and cost
+	Unchanged int
+	Before    float64
+	After     float64
+}
+
+// Delta returns the change in total monthly cost
+func (d CostDiff) Delta() float64 {
+	return d.After - d.Before
+}
+
+// DiffWorkloads matches workloads by namespace, kind and name. Costs that differ by
+// less than a cent count as unchanged. Differences are ordered by the size of their
+// delta (largest first), then by namespace, kind and name.
+func DiffWorkloads(before, after []calculator.WorkloadCost) CostDiff {
+	type side struct {
+		replicas int32
+		monthly  float64
+	}
+	key := func(w calculator.WorkloadCost) WorkloadDiff {
+		return WorkloadDiff{Namespace: w.Namespace, Kind: w.Kind, Name: w.Name}
+	}
+
+	var diff CostDiff
+	sides := make(map[WorkloadDiff][2]*side)
+	for i, workloads := range [][]calculator.WorkloadCost{before, after} {
+		for _, w := range workloads {
+			k := key(w)
+			s := sides[k]
+			if s[i] == nil {
+				s[i] = &side{}
+			}
+			s[i].replicas += w.Replicas
+			s[i].monthly += w.Monthly.TotalCost
+			sides[k] = s
+			if i == 0 {
+				diff.Before += w.Monthly.TotalCost
+			} else {
+				diff.After += w.Monthly.TotalCost
+			}
+		}
+	}
+
+	for d, s := range sides {
+		b, a := s[0], s[1]
+		switch {
+		case b == nil:
+			d.Change = ChangeAdded
+		case a == nil:
+			d.Change = ChangeRemoved
+		case b.replicas == a.replicas && math.Abs(a.monthly-b.monthly) < 0.01:
+			diff.Unchanged++
+			continue
+		default:
+			d.Change = ChangeChanged
+		}
+		if b != nil {
+			d.BeforeReplicas, d.Before = b.replicas, b.monthly
+		}
+		if a != nil {
+			d.AfterReplicas, d.After = a.replicas, a.monthly
+		}
+		diff.Workloads = append(diff.Workloads, d)
+	}
+
+	sort.Slice(diff.Workloads, func(i, j int) bool {
+		a, b := diff.Workloads[i], diff.Workloads[j]
+		if da, db := math.Abs(a.Delta()), math.Abs(b.Delta()); da != db {
+			return da > db
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return diff
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestDiffWorkloads(t *testing.T) {
+	t.Parallel()
+	workload := func(ns, name string, replicas int32, monthly float64) calculator.WorkloadCost {
+		return calculator.WorkloadCost{
+			Kind: "Deployment", Name: name, Namespace: ns, Replicas: replicas,
+			Monthly: calculator.ResourceCost{TotalCost: monthly},
+		}
+	}
+	before := []calculator.WorkloadCost{
+		workload("shop", "web", 3, 90),
+		workload("shop", "api", 2, 40),
+		workload("shop", "cache", 1, 10),
+		workload("batch", "old", 1, 25),
+	}
+	after := []calculator.WorkloadCost{
+		workload("shop", "web", 4, 120),
+		workload("shop", "api", 2, 40.004),
+		workload("shop", "cache", 1, 5),
+		workload("batch", "new", 2, 30),
+	}
+
+	diff := DiffWorkloads(before, after)
+
+	want := []struct {
+		change, name string
+		delta        float64
+	}{
+		{ChangeAdded, "new", 30},
+		{ChangeChanged, "web", 30},
+		{ChangeRemoved, "old", -25},
+		{ChangeChanged, "cache", -5},
+	}
+	if len(diff.Workloads) != len(want) {
+		t.Fatalf("got %d differences, want %d: %+v", len(diff.Workloads), len(want), diff.Workloads)
+	}
+	for i, w := range want {
+		got := diff.Workloads[i]
+		if got.Change != w.change || got.Name != w.name || math.Abs(got.Delta()-w.delta) > 1e-9 {
+			t.Errorf("difference %d: got %s %s %.2f, want %s %s %.2f", i, got.Change, got.Name, got.Delta(), w.change, w.name, w.delta)
+		}
+	}
+	if web := diff.Workloads[1]; web.BeforeReplicas != 3 || web.AfterReplicas != 4 {
+		t.Errorf("web replicas: got %d -> %d, want 3 -> 4", web.BeforeReplicas, web.AfterReplicas)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("unchanged: got %d, want 1", diff.Unchanged)
+	}
+	if math.Abs(diff.Before-165) > 1e-9 || math.Abs(diff.After-195.004) > 1e-9 {
+		t.Errorf("totals: got %.3f -> %.3f, want 165 -> 195.004", diff.Before, diff.After)
+	}
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// Group key sources accepted by ParseGroupKeys
+const (
+	GroupSourceLabel      = "label"
+	GroupSourceAnnotation = "annotation"
+)
+
+// DefaultUnlabelled is the bucket for pods that have no value for a group key
+const DefaultUnlabelled = "<none>"
+
+// GroupKey is a label or annotation that costs are grouped by
+type GroupKey struct {
+	Source string
+	Name   string
+}
+
+func (k GroupKey) String() string {
+	return k.Source + ":" + k.Name
+}
+
+// ParseGroupKeys parses a comma-separated list such as "label:team,annotation:cost-center".
+// Later keys nest inside earlier ones.
+func ParseGroupKeys(spec string) ([]GroupKey, error) {
+	var keys []GroupKey
+	for _, part := range strings.Split(spec, ",") {
+		source, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || name == "" || (source != GroupSourceLabel && source != GroupSourceAnnotation) {
+			return nil, fmt.Errorf("invalid group key %q, expected label:NAME or annotation:NAME", part)
+		}
+		keys = append(keys, GroupKey{Source: source, Name: name})
+	}
+	return keys, nil
+}
+
+// Metadata is the labels and annotations of a namespace
+type Metadata struct {
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// Grouping describes how to group costs by labels and annotations
+type Grouping struct {
+	Keys []GroupKey
+	// Unlabelled names the bucket for pods without a value for a key
+	Unlabelled string
+	// Namespaces holds namespace metadata, keyed by name. A pod without a value
+	// for a key inherits its namespace's label or annotation.
+	Namespaces map[string]Metadata
+}
+
+// GroupSummary is the cost of one group value. Children break it down by the
+// next group key.
+type GroupSummary struct {
+	Key         string
+	Value       string
+	TotalPods   int
+	HourlyCost  float64
+	DailyCost   float64
+	MonthlyCost float64
+	Children    []GroupSummary
+}
+
+// groupItem is a pod or orphaned claim to be grouped
+type groupItem struct {
+	namespace   string
+	labels      map[string]string
+	annotations map[string]string
+	pods        int
+	hourly      float64
+	daily       float64
+	monthly     float64
+}
+
+// AggregateByGroups builds nested summaries by each group key in turn. Orphaned
+// claims have no labels of their own and are grouped by their namespace's metadata.
+// Groups at each level are ordered by monthly cost (descending), then value.
+func AggregateByGroups(costs []calculator.PodCost, orphans []calculator.ClaimCost, g Grouping) []GroupSummary {
+	items := make([]groupItem, 0, len(costs)+len(orphans))
+	for _, pc := range costs {
+		items = append(items, groupItem{
+			namespace:   pc.Namespace,
+			labels:      pc.Labels,
+			annotations: pc.Annotations,
+			pods:        1,
+			hourly:      pc.Hourly.TotalCost,
+			daily:       pc.Daily.TotalCost,
+			monthly:     pc.Monthly.TotalCost,
+		})
+	}
+	for _, o := range orphans {
+		items = append(items, groupItem{namespace: o.Namespace, hourly: o.Hourly, daily: o.Daily, monthly: o.Monthly})
+	}
+	return g.level(items, 0)
+}
+
+func (g Grouping) level(items []groupItem, depth int) []GroupSummary {
+	if depth >= len(g.Keys) {
+		return nil
+	}
+	key := g.Keys[depth]
+
+	index := make(map[string]int)
+	var groups []GroupSummary
+	var members [][]groupItem
+	for _, item := range items {
+		value := g.value(item, key)
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, GroupSummary{Key: key.Name, Value: value})
+			members = append(members, nil)
+		}
+		groups[i].TotalPods += item.pods
+		groups[i].HourlyCost += item.hourly
+		groups[i].DailyCost += item.daily
+		groups[i].MonthlyCost += item.monthly
+		members[i] = append(members[i], item)
+	}
+
+	for i := range groups {
+		groups[i].Children = g.level(members[i], depth+1)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].MonthlyCost != groups[j].MonthlyCost {
+			return groups[i].MonthlyCost > groups[j].MonthlyCost
+		}
+		return groups[i].Value < groups[j].Value
+	})
+	return groups
+}
+
+// value returns an item's value for a key, falling back to its namespace and then
+// to the unlabelled bucket
+func (g Grouping) value(item groupItem, key GroupKey) string {
+	own, inherited := item.labels, g.Namespaces[item.namespace].Labels
+	if key.Source == GroupSourceAnnotation {
+		own, inherited = item.annotations, g.Namespaces[item.namespace].Annotations
+	}
+	if v := own[key.Name]; v != "" {
+		return v
+	}
+	if v := inherited[key.Name]; v != "" {
+		return v
+	}
+	if g.Unlabelled == "" {
+		return DefaultUnlabelled
+	}
+	return g.Unlabelled
+}
+
+// GroupTotal sums top-level groups into a grand total
+func GroupTotal(groups []GroupSummary) GroupSummary {
+	var total GroupSummary
+	for _, g := range groups {
+		total.TotalPods += g.TotalPods
+		total.HourlyCost += g.HourlyCost
+		total.DailyCost += g.DailyCost
+		total.MonthlyCost += g.MonthlyCost
+	}
+	return total
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestParseGroupKeys(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		spec    string
+		want    []GroupKey
+		wantErr bool
+	}{
+		{name: "single label", spec: "label:team", want: []GroupKey{{Source: GroupSourceLabel, Name: "team"}}},
+		{
+			name: "nested",
+			spec: "label:team, annotation:example.com/cost-center",
+			want: []GroupKey{{Source: GroupSourceLabel, Name: "team"}, {Source: GroupSourceAnnotation, Name: "example.com/cost-center"}},
+		},
+		{name: "missing source", spec: "team", wantErr: true},
+		{name: "unknown source", spec: "field:team", wantErr: true},
+		{name: "empty name", spec: "label:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseGroupKeys(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error: got %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("key count: got %d, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				if got[i] != w {
+					t.Errorf("key %d: got %q, want %q", i, got[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregateByGroups(t *testing.T) {
+	t.Parallel()
+	pod := func(ns string, labels, annotations map[string]string, monthly float64) calculator.PodCost {
+		return calculator.PodCost{
+			Namespace: ns, Labels: labels, Annotations: annotations,
+			Monthly: calculator.ResourceCost{TotalCost: monthly},
+		}
+	}
+	costs := []calculator.PodCost{
+		pod("shop", map[string]string{"team": "payments"}, map[string]string{"cost-center": "cc-1"}, 10),
+		pod("shop", map[string]string{"team": "payments"}, nil, 5),
+		pod("shop", nil, map[string]string{"cost-center": "cc-2"}, 8),
+		pod("misc", nil, nil, 1),
+	}
+	orphans := []calculator.ClaimCost{{Name: "old-data", Namespace: "shop", Monthly: 2}}
+	g := Grouping{
+		Keys: []GroupKey{{Source: GroupSourceLabel, Name: "team"}, {Source: GroupSourceAnnotation, Name: "cost-center"}},
+		Namespaces: map[string]Metadata{
+			"shop": {Labels: map[string]string{"team": "storefront"}, Annotations: map[string]string{"cost-center": "cc-9"}},
+		},
+		Unlabelled: "unassigned",
+	}
+
+	got := AggregateByGroups(costs, orphans, g)
+
+	type child struct {
+		value   string
+		monthly float64
+	}
+	want := []struct {
+		value    string
+		pods     int
+		monthly  float64
+		children []child
+	}{
+		{value: "payments", pods: 2, monthly: 15, children: []child{{"cc-1", 10}, {"cc-9", 5}}},
+		{value: "storefront", pods: 1, monthly: 10, children: []child{{"cc-2", 8}, {"cc-9", 2}}},
+		{value: "unassigned", pods: 1, monthly: 1, children: []child{{"unassigned", 1}}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("group count: got %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Key != "team" || got[i].Value != w.value {
+			t.Errorf("group %d: got %s=%s, want team=%s", i, got[i].Key, got[i].Value, w.value)
+		}
+		if got[i].TotalPods != w.pods {
+			t.Errorf("%s pods: got %d, want %d", w.value, got[i].TotalPods, w.pods)
+		}
+		if math.Abs(got[i].MonthlyCost-w.monthly) > dailyMonthlyTolerance {
+			t.Errorf("%s monthly cost: got %.2f, want %.2f", w.value, got[i].MonthlyCost, w.monthly)
+		}
+		if len(got[i].Children) != len(w.children) {
+			t.Fatalf("%s child count: got %d, want %d", w.value, len(got[i].Children), len(w.children))
+		}
+		for j, c := range w.children {
+			gc := got[i].Children[j]
+			if gc.Key != "cost-center" || gc.Value != c.value || math.Abs(gc.MonthlyCost-c.monthly) > dailyMonthlyTolerance {
+				t.Errorf("%s child %d: got %s=%s %.2f, want cost-center=%s %.2f", w.value, j, gc.Key, gc.Value, gc.MonthlyCost, c.value, c.monthly)
+			}
+		}
+	}
+
+	if total := GroupTotal(got); total.TotalPods != 4 || math.Abs(total.MonthlyCost-26) > dailyMonthlyTolerance {
+		t.Errorf("total: got %d pods %.2f, want 4 pods 26.00", total.TotalPods, total.MonthlyCost)
+	}
+}
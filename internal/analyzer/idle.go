@@ -0,0 +1,81 @@
+package analyzer
+
+import "github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+
+// Idle is the cost of node capacity that no pod requests
+type Idle struct {
+	Nodes []calculator.NodeCost
+	// Shares holds each namespace's hourly share of the idle cost; nil unless the
+	// idle cost is distributed
+	Shares map[string]float64
+}
+
+// DistributeIdle splits each node's idle cost across the namespaces with pods on it,
+// in proportion to the cost of their CPU and memory requests there. The idle cost
+// of nodes without priced pods is not distributed. Shares are hourly.
+func DistributeIdle(nodes []calculator.NodeCost, costs []calculator.PodCost) map[string]float64 {
+	requested := make(map[string]map[string]float64)
+	for _, pc := range costs {
+		if pc.Node == "" {
+			continue
+		}
+		if requested[pc.Node] == nil {
+			requested[pc.Node] = make(map[string]float64)
+		}
+		requested[pc.Node][pc.Namespace] += pc.Hourly.CPUCost + pc.Hourly.MemoryCost
+	}
+
+	shares := make(map[string]float64)
+	for _, node := range nodes {
+		var total float64
+		for _, cost := range requested[node.Name] {
+			total += cost
+		}
+		if total <= 0 {
+			continue
+		}
+		for namespace, cost := range requested[node.Name] {
+			shares[namespace] += node.IdleHourly.TotalCost * cost / total
+		}
+	}
+	return shares
+}
+
+// AddIdleShares adds each namespace's share of the idle cost to its summary. Shares
+// of namespaces without a summary are left out, so a report covering some
+// namespaces only carries their part. The result is re-sorted by monthly cost.
+func AddIdleShares(summaries []NamespaceSummary, shares map[string]float64) []NamespaceSummary {
+	if len(shares) == 0 {
+		return summaries
+	}
+
+	agg := newNamespaceAggregator()
+	for _, s := range summaries {
+		summary := s
+		summary.IdleMonthlyCost = shares[s.Namespace] * calculator.HoursPerMonth
+		summary.HourlyCost += shares[s.Namespace]
+		summary.DailyCost += shares[s.Namespace] * calculator.HoursPerDay
+		summary.MonthlyCost += summary.IdleMonthlyCost
+		agg[s.Namespace] = &summary
+	}
+	return agg.summaries()
+}
+
+// NodeTotal sums node costs into a cluster-wide total with an empty Name
+func NodeTotal(nodes []calculator.NodeCost) calculator.NodeCost {
+	var total calculator.NodeCost
+	for _, n := range nodes {
+		total.Pods += n.Pods
+		total.CPUAllocatableCores += n.CPUAllocatableCores
+		total.MemoryAllocatableGB += n.MemoryAllocatableGB
+		total.CPURequestedCores += n.CPURequestedCores
+		total.MemoryRequestedGB += n.MemoryRequestedGB
+		total.Hourly = total.Hourly.Add(n.Hourly)
+		total.Daily = total.Daily.Add(n.Daily)
+		total.Monthly = total.Monthly.Add(n.Monthly)
+		total.IdleHourly = total.IdleHourly.Add(n.IdleHourly)
+		total.IdleDaily = total.IdleDaily.Add(n.IdleDaily)
+		total.IdleMonthly = total.IdleMonthly.Add(n.IdleMonthly)
+	}
+	return total
+}
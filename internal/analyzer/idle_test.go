@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestDistributeIdle(t *testing.T) {
+	t.Parallel()
+	nodes := []calculator.NodeCost{
+		{Name: "a", IdleHourly: calculator.ResourceCost{TotalCost: 0.3}},
+		{Name: "b", IdleHourly: calculator.ResourceCost{TotalCost: 0.1}},
+		{Name: "empty", IdleHourly: calculator.ResourceCost{TotalCost: 1}},
+	}
+	pod := func(ns, node string, cpu, storage float64) calculator.PodCost {
+		return calculator.PodCost{Namespace: ns, Node: node, Hourly: calculator.ResourceCost{CPUCost: cpu, StorageCost: storage, TotalCost: cpu + storage}}
+	}
+	costs := []calculator.PodCost{
+		pod("shop", "a", 0.2, 0.5),
+		pod("batch", "a", 0.1, 0),
+		pod("batch", "b", 0.05, 0),
+		pod("shop", "", 1, 0),
+	}
+
+	got := DistributeIdle(nodes, costs)
+
+	want := map[string]float64{"shop": 0.2, "batch": 0.2}
+	if len(got) != len(want) {
+		t.Fatalf("share count: got %d, want %d", len(got), len(want))
+	}
+	for ns, w := range want {
+		if math.Abs(got[ns]-w) > hourlyTolerance {
+			t.Errorf("%s share: got %.4f, want %.4f", ns, got[ns], w)
+		}
+	}
+
+	summaries := AddIdleShares([]NamespaceSummary{
+		{Namespace: "shop", TotalPods: 2, HourlyCost: 0.5, MonthlyCost: 365},
+	}, got)
+	if len(summaries) != 1 {
+		t.Fatalf("summary count: got %d, want 1", len(summaries))
+	}
+	if math.Abs(summaries[0].IdleMonthlyCost-146) > dailyMonthlyTolerance {
+		t.Errorf("idle monthly cost: got %.2f, want 146.00", summaries[0].IdleMonthlyCost)
+	}
+	if math.Abs(summaries[0].MonthlyCost-511) > dailyMonthlyTolerance {
+		t.Errorf("monthly cost: got %.2f, want 511.00", summaries[0].MonthlyCost)
+	}
+	if math.Abs(summaries[0].HourlyCost-0.7) > hourlyTolerance {
+		t.Errorf("hourly cost: got %.4f, want 0.7000", summaries[0].HourlyCost)
+	}
+}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"gopkg.in/yaml.v3"
+)
+
+// Strategies for distributing the cost of shared namespaces
+const (
+	// ShareEven gives every other namespace the same share
+	ShareEven = "even"
+	// ShareProportional shares in proportion to each namespace's own cost
+	ShareProportional = "proportional"
+	// ShareWeighted shares by the weights table; unlisted namespaces get nothing
+	ShareWeighted = "weighted"
+)
+
+// SharedCosts is the shared-cost configuration file schema: the namespaces whose cost
+// is spread across the others, given by name or by namespace label selector, and how
+type SharedCosts struct {
+	Namespaces []string           `yaml:"namespaces,omitempty"`
+	Selectors  []string           `yaml:"selectors,omitempty"`
+	Strategy   string             `yaml:"strategy"`
+	Weights    map[string]float64 `yaml:"weights,omitempty"`
+}
+
+// LoadSharedCosts reads a shared-cost configuration from a YAML file
+func LoadSharedCosts(path string) (SharedCosts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SharedCosts{}, fmt.Errorf("failed to read shared costs file: %w", err)
+	}
+	cfg, err := ParseSharedCosts(data)
+	if err != nil {
+		return SharedCosts{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseSharedCosts decodes and validates a shared-cost configuration. Unknown
+// fields are rejected so that typos do not silently change the allocation.
+func ParseSharedCosts(data []byte) (SharedCosts, error) {
+	var cfg SharedCosts
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil {
+		return SharedCosts{}, fmt.Errorf("failed to parse shared costs YAML: %w", err)
+	}
+
+	var errs []error
+	if len(cfg.Namespaces) == 0 && len(cfg.Selectors) == 0 {
+		errs = append(errs, errors.New("no shared namespaces: set namespaces or selectors"))
+	}
+	switch cfg.Strategy {
+	case ShareEven, ShareProportional:
+	case ShareWeighted:
+		if len(cfg.Weights) == 0 {
+			errs = append(errs, errors.New("strategy weighted needs a weights table"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown strategy %q (supported: %s, %s, %s)", cfg.Strategy, ShareEven, ShareProportional, ShareWeighted))
+	}
+	for namespace, weight := range cfg.Weights {
+		if weight < 0 {
+			errs = append(errs, fmt.Errorf("weight for %s must not be negative, got %g", namespace, weight))
+		}
+	}
+	if len(errs) > 0 {
+		return SharedCosts{}, fmt.Errorf("invalid shared costs file: %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// Shared is the cost of the shared namespaces and how it was split
+type Shared struct {
+	Strategy string
+	// Pool holds the summaries of the shared namespaces
+	Pool []NamespaceSummary
+	// Shares holds each receiving namespace's hourly share of the pool; empty when
+	// there was nothing to share it with
+	Shares map[string]float64
+}
+
+// ShareCosts splits the cost of the shared namespaces across the other namespaces
+// in summaries according to the strategy. Summaries should cover every namespace
+// that can receive a share, or shares are split among too few.
+func ShareCosts(summaries []NamespaceSummary, shared map[string]bool, cfg SharedCosts) *Shared {
+	result := &Shared{Strategy: cfg.Strategy, Shares: make(map[string]float64)}
+
+	var pool float64
+	weights := make(map[string]float64)
+	var totalWeight float64
+	for _, s := range summaries {
+		if shared[s.Namespace] {
+			result.Pool = append(result.Pool, s)
+			pool += s.HourlyCost
+			continue
+		}
+
+		var weight float64
+		switch cfg.Strategy {
+		case ShareEven:
+			weight = 1
+		case ShareProportional:
+			weight = s.HourlyCost
+		case ShareWeighted:
+			weight = cfg.Weights[s.Namespace]
+		}
+		if weight > 0 {
+			weights[s.Namespace] = weight
+			totalWeight += weight
+		}
+	}
+
+	if totalWeight <= 0 {
+		return result
+	}
+	for namespace, weight := range weights {
+		result.Shares[namespace] = pool * weight / totalWeight
+	}
+	return result
+}
+
+// pooled reports whether a namespace's cost was moved to other namespaces
+func (s *Shared) pooled(namespace string) bool {
+	if len(s.Shares) == 0 {
+		return false
+	}
+	for _, p := range s.Pool {
+		if p.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Allocations are costs moved between namespaces after aggregation; either may be nil
+type Allocations struct {
+	Idle   *Idle
+	Shared *Shared
+}
+
+// Apply adds distributed idle shares to the summaries, then replaces the summaries
+// of shared namespaces by the shares of the others. Shares of namespaces without a
+// summary are left out. The result is re-sorted by monthly cost.
+func (a Allocations) Apply(summaries []NamespaceSummary) []NamespaceSummary {
+	if a.Idle != nil {
+		summaries = AddIdleShares(summaries, a.Idle.Shares)
+	}
+	if a.Shared == nil || len(a.Shared.Shares) == 0 {
+		return summaries
+	}
+
+	agg := newNamespaceAggregator()
+	for _, s := range summaries {
+		if a.Shared.pooled(s.Namespace) {
+			continue
+		}
+		summary := s
+		share := a.Shared.Shares[s.Namespace]
+		summary.SharedMonthlyCost = share * calculator.HoursPerMonth
+		summary.HourlyCost += share
+		summary.DailyCost += share * calculator.HoursPerDay
+		summary.MonthlyCost += summary.SharedMonthlyCost
+		agg[s.Namespace] = &summary
+	}
+	return agg.summaries()
+}
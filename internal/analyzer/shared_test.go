@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseSharedCosts(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "even", yaml: "namespaces: [kube-system]\nstrategy: even\n"},
+		{name: "weighted by selector", yaml: "selectors: [\"kcost.io/shared=true\"]\nstrategy: weighted\nweights: {shop: 2, batch: 1}\n"},
+		{name: "no namespaces", yaml: "strategy: even\n", wantErr: "no shared namespaces"},
+		{name: "unknown strategy", yaml: "namespaces: [kube-system]\nstrategy: random\n", wantErr: `unknown strategy "random"`},
+		{name: "weighted without weights", yaml: "namespaces: [kube-system]\nstrategy: weighted\n", wantErr: "needs a weights table"},
+		{name: "negative weight", yaml: "namespaces: [kube-system]\nstrategy: weighted\nweights: {shop: -1}\n", wantErr: "must not be negative"},
+		{name: "unknown field", yaml: "namespace: [kube-system]\nstrategy: even\n", wantErr: "field namespace not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseSharedCosts([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShareCosts(t *testing.T) {
+	t.Parallel()
+	summaries := []NamespaceSummary{
+		{Namespace: "kube-system", TotalPods: 5, HourlyCost: 0.3, MonthlyCost: 219},
+		{Namespace: "shop", TotalPods: 4, HourlyCost: 0.2, MonthlyCost: 146},
+		{Namespace: "batch", TotalPods: 2, HourlyCost: 0.1, MonthlyCost: 73},
+	}
+	shared := map[string]bool{"kube-system": true}
+
+	tests := []struct {
+		name        string
+		cfg         SharedCosts
+		wantShares  map[string]float64
+		wantSummary map[string]float64
+	}{
+		{
+			name:        "even",
+			cfg:         SharedCosts{Strategy: ShareEven},
+			wantShares:  map[string]float64{"shop": 0.15, "batch": 0.15},
+			wantSummary: map[string]float64{"shop": 0.35, "batch": 0.25},
+		},
+		{
+			name:        "proportional",
+			cfg:         SharedCosts{Strategy: ShareProportional},
+			wantShares:  map[string]float64{"shop": 0.2, "batch": 0.1},
+			wantSummary: map[string]float64{"shop": 0.4, "batch": 0.2},
+		},
+		{
+			name:        "weighted",
+			cfg:         SharedCosts{Strategy: ShareWeighted, Weights: map[string]float64{"batch": 1, "other": 5}},
+			wantShares:  map[string]float64{"batch": 0.3},
+			wantSummary: map[string]float64{"shop": 0.2, "batch": 0.4},
+		},
+		{
+			name:        "nobody to share with",
+			cfg:         SharedCosts{Strategy: ShareWeighted, Weights: map[string]float64{"other": 1}},
+			wantShares:  map[string]float64{},
+			wantSummary: map[string]float64{"kube-system": 0.3, "shop": 0.2, "batch": 0.1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := ShareCosts(summaries, shared, tt.cfg)
+
+			if len(got.Pool) != 1 || got.Pool[0].Namespace != "kube-system" {
+				t.Fatalf("pool: got %v, want kube-system", got.Pool)
+			}
+			if len(got.Shares) != len(tt.wantShares) {
+				t.Fatalf("share count: got %d, want %d", len(got.Shares), len(tt.wantShares))
+			}
+			for ns, w := range tt.wantShares {
+				if math.Abs(got.Shares[ns]-w) > hourlyTolerance {
+					t.Errorf("%s share: got %.4f, want %.4f", ns, got.Shares[ns], w)
+				}
+			}
+
+			applied := Allocations{Shared: got}.Apply(summaries)
+			if len(applied) != len(tt.wantSummary) {
+				t.Fatalf("summary count: got %d, want %d", len(applied), len(tt.wantSummary))
+			}
+			for _, s := range applied {
+				if math.Abs(s.HourlyCost-tt.wantSummary[s.Namespace]) > hourlyTolerance {
+					t.Errorf("%s hourly cost: got %.4f, want %.4f", s.Namespace, s.HourlyCost, tt.wantSummary[s.Namespace])
+				}
+				wantShared := tt.wantShares[s.Namespace] * 730
+				if math.Abs(s.SharedMonthlyCost-wantShared) > dailyMonthlyTolerance {
+					t.Errorf("%s shared cost: got %.2f, want %.2f", s.Namespace, s.SharedMonthlyCost, wantShared)
+				}
+			}
+			if total := Total(applied); math.Abs(total.HourlyCost-0.6) > hourlyTolerance {
+				t.Errorf("total hourly cost: got %.4f, want 0.6000", total.HourlyCost)
+			}
+		})
+	}
+}
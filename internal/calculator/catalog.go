@@ -0,0 +1,96 @@
+package calculator
+
+import "math"
+
+// InstancePrice is a catalog entry for one instance type. Provider and Region may be
+// left empty to match any provider or region.
+type InstancePrice struct {
+	Provider     string  `yaml:"provider,omitempty"`
+	Region       string  `yaml:"region,omitempty"`
+	InstanceType string  `yaml:"instance_type"`
+	VCPU         float64 `yaml:"vcpu"`
+	MemoryGB     float64 `yaml:"memory_gb"`
+	GPU          int     `yaml:"gpu,omitempty"`
+	HourlyPrice  float64 `yaml:"hourly_price"`
+}
+
+// GPUResources are the extended resources that count an instance's GPUs
+var GPUResources = []string{"nvidia.com/gpu", "amd.com/gpu"}
+
+// Instance identifies the machine a node runs on, as read from its labels
+type Instance struct {
+	Provider     string
+	Region       string
+	InstanceType string
+}
+
+// FindInstance returns the catalog entry for an instance. An entry naming the
+// instance's region beats one that leaves the region empty; entries naming a
+// different provider or region never match.
+func (r Rates) FindInstance(inst Instance) (InstancePrice, bool) {
+	var best InstancePrice
+	bestScore := -1
+	for _, p := range r.Instances {
+		if p.InstanceType != inst.InstanceType {
+			continue
+		}
+		score := 0
+		if p.Provider != "" {
+			if p.Provider != inst.Provider {
+				continue
+			}
+			score++
+		}
+		if p.Region != "" {
+			if p.Region != inst.Region {
+				continue
+			}
+			score += 2
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// ForInstance returns rates derived from the catalog price of an instance, or the
+// rates unchanged when the instance is not in the catalog.
+//
+// The hourly price is split between CPU and memory so that the ratio of the
+// per-core to per-GB rate stays that of the flat rates, i.e.
+// price = vcpu*cpuRate + memoryGB*memoryRate with cpuRate/memoryRate unchanged.
+// The GPUs of GPU instances are first taken out of the price at the GPU rate,
+// since pods requesting them are charged for them separately.
+func (r Rates) ForInstance(inst Instance) (Rates, bool) {
+	price, ok := r.FindInstance(inst)
+	if !ok || price.VCPU <= 0 {
+		return r, false
+	}
+	price.HourlyPrice = math.Max(price.HourlyPrice-float64(price.GPU)*r.gpuRate(), 0)
+
+	derived := r
+	switch {
+	case r.MemoryPerGBPerHour <= 0 || price.MemoryGB <= 0:
+		derived.CPUPerCorePerHour = price.HourlyPrice / price.VCPU
+		derived.MemoryPerGBPerHour = 0
+	case r.CPUPerCorePerHour <= 0:
+		derived.CPUPerCorePerHour = 0
+		derived.MemoryPerGBPerHour = price.HourlyPrice / price.MemoryGB
+	default:
+		ratio := r.CPUPerCorePerHour / r.MemoryPerGBPerHour
+		derived.MemoryPerGBPerHour = price.HourlyPrice / (price.VCPU*ratio + price.MemoryGB)
+		derived.CPUPerCorePerHour = derived.MemoryPerGBPerHour * ratio
+	}
+	return derived, true
+}
+
+// gpuRate returns the rate of the first GPU resource that has one
+func (r Rates) gpuRate() float64 {
+	for _, name := range GPUResources {
+		if rate, ok := r.ExtendedResources[name]; ok {
+			return rate
+		}
+	}
+	return 0
+}
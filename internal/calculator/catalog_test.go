@@ -0,0 +1,97 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindInstance(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		Instances: []InstancePrice{
+			{InstanceType: "m5.large", HourlyPrice: 0.1},
+			{Provider: "aws", InstanceType: "m5.large", HourlyPrice: 0.2},
+			{Provider: "aws", Region: "eu-west-1", InstanceType: "m5.large", HourlyPrice: 0.3},
+			{Provider: "gcp", InstanceType: "e2-standard-2", HourlyPrice: 0.4},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		inst      Instance
+		wantPrice float64
+		wantOK    bool
+	}{
+		{name: "region match beats provider match", inst: Instance{Provider: "aws", Region: "eu-west-1", InstanceType: "m5.large"}, wantPrice: 0.3, wantOK: true},
+		{name: "provider match beats wildcard", inst: Instance{Provider: "aws", Region: "us-east-1", InstanceType: "m5.large"}, wantPrice: 0.2, wantOK: true},
+		{name: "wildcard entry", inst: Instance{InstanceType: "m5.large"}, wantPrice: 0.1, wantOK: true},
+		{name: "other provider never matches", inst: Instance{Provider: "azure", InstanceType: "e2-standard-2"}, wantOK: false},
+		{name: "unknown type", inst: Instance{Provider: "aws", InstanceType: "t3.micro"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := rates.FindInstance(tt.inst)
+			if ok != tt.wantOK {
+				t.Fatalf("found: got %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.HourlyPrice != tt.wantPrice {
+				t.Errorf("hourly price: got %.2f, want %.2f", got.HourlyPrice, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestForInstance(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		CPUPerCorePerHour:  0.04,
+		MemoryPerGBPerHour: 0.005,
+		Instances: []InstancePrice{
+			{InstanceType: "m5.large", VCPU: 2, MemoryGB: 8, HourlyPrice: 0.096},
+		},
+	}
+
+	derived, ok := rates.ForInstance(Instance{InstanceType: "m5.large"})
+	if !ok {
+		t.Fatal("expected m5.large to be found")
+	}
+
+	// The whole node priced at the derived rates must equal the catalog price
+	nodeCost := 2*derived.CPUPerCorePerHour + 8*derived.MemoryPerGBPerHour
+	if math.Abs(nodeCost-0.096) > 1e-9 {
+		t.Errorf("node cost: got %.6f, want 0.096000", nodeCost)
+	}
+	if ratio := derived.CPUPerCorePerHour / derived.MemoryPerGBPerHour; math.Abs(ratio-8) > 1e-9 {
+		t.Errorf("CPU:memory ratio: got %.4f, want 8.0000", ratio)
+	}
+
+	unchanged, ok := rates.ForInstance(Instance{InstanceType: "t3.micro"})
+	if ok || unchanged.CPUPerCorePerHour != rates.CPUPerCorePerHour {
+		t.Errorf("unknown instance: got %.4f (found %v), want flat rate %.4f", unchanged.CPUPerCorePerHour, ok, rates.CPUPerCorePerHour)
+	}
+}
+
+func TestForInstanceGPU(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		CPUPerCorePerHour:  0.04,
+		MemoryPerGBPerHour: 0.005,
+		ExtendedResources:  map[string]float64{"nvidia.com/gpu": 2.5},
+		Instances: []InstancePrice{
+			{InstanceType: "p3.2xlarge", VCPU: 8, MemoryGB: 61, GPU: 1, HourlyPrice: 3.06},
+		},
+	}
+
+	derived, ok := rates.ForInstance(Instance{InstanceType: "p3.2xlarge"})
+	if !ok {
+		t.Fatal("expected p3.2xlarge to be found")
+	}
+
+	// CPU and memory share what is left after the GPU is charged at its own rate
+	nodeCost := 8*derived.CPUPerCorePerHour + 61*derived.MemoryPerGBPerHour
+	if math.Abs(nodeCost-0.56) > 1e-9 {
+		t.Errorf("CPU and memory cost: got %.6f, want 0.560000", nodeCost)
+	}
+}
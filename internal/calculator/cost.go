@@ -1,63 +1,167 @@
 package calculator
 
 import (
+	"strings"
+
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type ResourceCost struct {
 	CPUCost    float64
 	MemoryCost float64
-	TotalCost  float64
+	// Extended holds the cost of each extended resource (e.g. nvidia.com/gpu) by name
+	Extended map[string]float64
+	// StorageCost is the pod's share of the persistent volumes it mounts
+	StorageCost float64
+	TotalCost   float64
 }
 
 type PodCost struct {
 	Name      string
 	Namespace string
+	// OwnerKind and OwnerName identify the top-level controller of the pod, e.g. a
+	// Deployment rather than its ReplicaSet; bare pods are their own owner
+	OwnerKind string
+	OwnerName string
+	// Node is the node whose capacity the pod holds; empty for pods that are
+	// unscheduled or finished, and for workload templates
+	Node string
+	// Labels and Annotations are the pod's own, for grouping costs
+	Labels      map[string]string
+	Annotations map[string]string
+	Hourly      ResourceCost
+	Daily       ResourceCost
+	Monthly     ResourceCost
+	// RequestRule names what set the priced requests when it was not simply the
+	// sum of the app containers, e.g. an init container or pod overhead
+	RequestRule string
+	// Usage is the pod's measured usage, nil unless usage was requested and sampled
+	Usage *UsageCost
+}
+
+// WorkloadCost is the cost of a workload controller running its desired replicas
+type WorkloadCost struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Replicas  int32
 	Hourly    ResourceCost
 	Daily     ResourceCost
 	Monthly   ResourceCost
+	// RequestRule is the per-replica PodCost.RequestRule
+	RequestRule string
+}
+
+// Scale multiplies every cost component by factor
+func (c ResourceCost) Scale(factor float64) ResourceCost {
+	scaled := ResourceCost{
+		CPUCost:     c.CPUCost * factor,
+		MemoryCost:  c.MemoryCost * factor,
+		StorageCost: c.StorageCost * factor,
+		TotalCost:   c.TotalCost * factor,
+	}
+	if c.Extended != nil {
+		scaled.Extended = make(map[string]float64, len(c.Extended))
+		for name, cost := range c.Extended {
+			scaled.Extended[name] = cost * factor
+		}
+	}
+	return scaled
+}
+
+// Add sums two costs component by component
+func (c ResourceCost) Add(other ResourceCost) ResourceCost {
+	sum := ResourceCost{
+		CPUCost:     c.CPUCost + other.CPUCost,
+		MemoryCost:  c.MemoryCost + other.MemoryCost,
+		StorageCost: c.StorageCost + other.StorageCost,
+		TotalCost:   c.TotalCost + other.TotalCost,
+	}
+	if c.Extended != nil || other.Extended != nil {
+		sum.Extended = make(map[string]float64, len(c.Extended)+len(other.Extended))
+		for name, cost := range c.Extended {
+			sum.Extended[name] += cost
+		}
+		for name, cost := range other.Extended {
+			sum.Extended[name] += cost
+		}
+	}
+	return sum
+}
+
+// NewWorkloadCost scales the cost of a single replica to the workload's replica count
+func NewWorkloadCost(kind string, replicas int32, perReplica PodCost) WorkloadCost {
+	n := float64(replicas)
+	return WorkloadCost{
+		Kind:      kind,
+		Name:      perReplica.Name,
+		Namespace: perReplica.Namespace,
+		Replicas:  replicas,
+		Hourly:    perReplica.Hourly.Scale(n),
+		Daily:     perReplica.Daily.Scale(n),
+		Monthly:   perReplica.Monthly.Scale(n),
+
+		RequestRule: perReplica.RequestRule,
+	}
 }
 
+// Hours used to turn hourly costs into daily and monthly costs
+const (
+	HoursPerDay   = 24
+	HoursPerMonth = 730 // average month
+)
+
 // CalculatePodCost computes cost for a pod's resource requests
 func CalculatePodCost(podName, namespace string, cpuRequest, memoryRequest resource.Quantity, rates Rates) PodCost {
-	// Convert CPU to cores (millicores to cores)
-	cpuCores := float64(cpuRequest.MilliValue()) / 1000.0
+	return CalculatePodCostWithExtended(podName, namespace, cpuRequest, memoryRequest, nil, rates)
+}
 
-	// Convert memory to GB
-	memoryGB := float64(memoryRequest.Value()) / (1024 * 1024 * 1024)
+// CalculatePodCostWithExtended computes cost for a pod's CPU, memory and extended
+// resource requests. Extended resources without a rate are reported at zero cost.
+func CalculatePodCostWithExtended(podName, namespace string, cpuRequest, memoryRequest resource.Quantity, extended map[string]resource.Quantity, rates Rates) PodCost {
+	cpuCores := cores(cpuRequest)
+	memoryGB := gigabytes(memoryRequest)
 
 	// Calculate hourly costs
-	hourlyCPU := cpuCores * rates.CPUPerCorePerHour
-	hourlyMemory := memoryGB * rates.MemoryPerGBPerHour
-	hourlyTotal := hourlyCPU + hourlyMemory
-
-	// Calculate daily costs (24 hours)
-	dailyCPU := hourlyCPU * 24
-	dailyMemory := hourlyMemory * 24
-	dailyTotal := hourlyTotal * 24
+	hourly := ResourceCost{
+		CPUCost:    cpuCores * rates.CPUPerCorePerHour,
+		MemoryCost: memoryGB * rates.MemoryPerGBPerHour,
+	}
+	hourly.TotalCost = hourly.CPUCost + hourly.MemoryCost
 
-	// Calculate monthly costs (730 hours average)
-	monthlyCPU := hourlyCPU * 730
-	monthlyMemory := hourlyMemory * 730
-	monthlyTotal := hourlyTotal * 730
+	if len(extended) > 0 {
+		hourly.Extended = make(map[string]float64, len(extended))
+		for name, qty := range extended {
+			cost := ExtendedUnits(name, qty) * rates.ExtendedResources[name]
+			hourly.Extended[name] = cost
+			hourly.TotalCost += cost
+		}
+	}
 
 	return PodCost{
 		Name:      podName,
 		Namespace: namespace,
-		Hourly: ResourceCost{
-			CPUCost:    hourlyCPU,
-			MemoryCost: hourlyMemory,
-			TotalCost:  hourlyTotal,
-		},
-		Daily: ResourceCost{
-			CPUCost:    dailyCPU,
-			MemoryCost: dailyMemory,
-			TotalCost:  dailyTotal,
-		},
-		Monthly: ResourceCost{
-			CPUCost:    monthlyCPU,
-			MemoryCost: monthlyMemory,
-			TotalCost:  monthlyTotal,
-		},
+		Hourly:    hourly,
+		Daily:     hourly.Scale(HoursPerDay),
+		Monthly:   hourly.Scale(HoursPerMonth),
 	}
 }
+
+// ExtendedUnits converts an extended resource quantity into the unit its rate is
+// quoted in: GB for hugepages-*, otherwise the resource's own count (e.g. GPUs)
+func ExtendedUnits(name string, qty resource.Quantity) float64 {
+	if strings.HasPrefix(name, "hugepages-") {
+		return float64(qty.Value()) / (1024 * 1024 * 1024)
+	}
+	return qty.AsApproximateFloat64()
+}
+
+// cores converts a CPU quantity to cores
+func cores(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000.0
+}
+
+// gigabytes converts a memory quantity to GB (GiB)
+func gigabytes(q resource.Quantity) float64 {
+	return float64(q.Value()) / (1024 * 1024 * 1024)
+}
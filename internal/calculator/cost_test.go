@@ -3,6 +3,7 @@ package calculator
 import (
 	"math"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -105,6 +106,46 @@ func TestCalculatePodCost(t *testing.T) {
 	}
 }
 
+func TestCalculatePodCostWithExtended(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		CPUPerCorePerHour:  0.034,
+		MemoryPerGBPerHour: 0.004,
+		ExtendedResources: map[string]float64{
+			"nvidia.com/gpu": 2.5,
+			"hugepages-2Mi":  0.01,
+		},
+	}
+
+	extended := map[string]resource.Quantity{
+		"nvidia.com/gpu":   resource.MustParse("2"),
+		"hugepages-2Mi":    resource.MustParse("1Gi"),
+		"example.com/fpga": resource.MustParse("1"),
+	}
+	cost := CalculatePodCostWithExtended("trainer", "ml", resource.MustParse("1"), resource.MustParse("0"), extended, rates)
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "GPU hourly", got: cost.Hourly.Extended["nvidia.com/gpu"], want: 5.0},
+		{name: "hugepages priced per GB", got: cost.Hourly.Extended["hugepages-2Mi"], want: 0.01},
+		{name: "unpriced resource", got: cost.Hourly.Extended["example.com/fpga"], want: 0},
+		{name: "hourly total includes extended", got: cost.Hourly.TotalCost, want: 0.034 + 5.0 + 0.01},
+		{name: "monthly GPU", got: cost.Monthly.Extended["nvidia.com/gpu"], want: 5.0 * 730},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > tolerance {
+			t.Errorf("%s: got %.4f, want %.4f", tt.name, tt.got, tt.want)
+		}
+	}
+	if _, ok := cost.Hourly.Extended["example.com/fpga"]; !ok {
+		t.Error("unpriced resource missing from extended costs")
+	}
+}
+
 func TestDefaultRates(t *testing.T) {
 	t.Parallel()
 	rates := DefaultRates()
@@ -178,34 +219,26 @@ memory_per_gb_per_hour: 0.006
 	})
 }
 
-func TestGetRatesLastUpdated(t *testing.T) {
+func TestRatesFileLastUpdated(t *testing.T) {
 	t.Parallel()
 	t.Run("valid date", func(t *testing.T) {
 		t.Parallel()
-		tmpfile, err := os.CreateTemp("", "rates-*.yaml")
-		if err != nil {
-			t.Fatalf("failed to create temp file: %v", err)
-		}
-		defer os.Remove(tmpfile.Name())
-
-		testDate := time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)
-		content := `# Default cost rates (USD)
-# Last updated: 2023-01-15
-#
+		rates, err := ParseRates([]byte(`apiVersion: kcost/v1
+last_updated: 2023-01-15
 cpu_per_core_per_hour: 0.034
 memory_per_gb_per_hour: 0.004
-`
-		if _, err := tmpfile.Write([]byte(content)); err != nil {
-			t.Fatalf("failed to write temp file: %v", err)
+`))
+		if err != nil {
+			t.Fatalf("ParseRates failed: %v", err)
 		}
-		tmpfile.Close()
 
-		lastUpdated, daysSince, err := GetRatesLastUpdated(tmpfile.Name())
+		lastUpdated, daysSince, err := RatesFile{Rates: rates}.LastUpdated()
 		if err != nil {
-			t.Fatalf("GetRatesLastUpdated failed: %v", err)
+			t.Fatalf("LastUpdated failed: %v", err)
 		}
 
-		if lastUpdated.Year() != 2023 || lastUpdated.Month() != time.January || lastUpdated.Day() != 15 {
+		testDate := time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)
+		if !lastUpdated.Equal(testDate) {
 			t.Errorf("expected date 2023-01-15, got %v", lastUpdated)
 		}
 
@@ -218,31 +251,188 @@ memory_per_gb_per_hour: 0.004
 
 	t.Run("no date found", func(t *testing.T) {
 		t.Parallel()
-		tmpfile, err := os.CreateTemp("", "rates-*.yaml")
-		if err != nil {
-			t.Fatalf("failed to create temp file: %v", err)
-		}
-		defer os.Remove(tmpfile.Name())
-
-		content := `cpu_per_core_per_hour: 0.034
-memory_per_gb_per_hour: 0.004
-`
-		if _, err := tmpfile.Write([]byte(content)); err != nil {
-			t.Fatalf("failed to write temp file: %v", err)
-		}
-		tmpfile.Close()
-
-		_, _, err = GetRatesLastUpdated(tmpfile.Name())
+		_, _, err := RatesFile{Rates: DefaultRates()}.LastUpdated()
 		if err == nil {
 			t.Error("expected error when no date found, got nil")
 		}
 	})
+}
 
-	t.Run("invalid file", func(t *testing.T) {
-		t.Parallel()
-		_, _, err := GetRatesLastUpdated("/nonexistent/file.yaml")
-		if err == nil {
-			t.Error("expected error for nonexistent file, got nil")
+func TestNewWorkloadCost(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		CPUPerCorePerHour:  0.034,
+		MemoryPerGBPerHour: 0.004,
+	}
+	perReplica := CalculatePodCost("web", "default", resource.MustParse("500m"), resource.MustParse("1Gi"), rates)
+
+	tests := []struct {
+		name     string
+		replicas int32
+	}{
+		{name: "three replicas", replicas: 3},
+		{name: "scaled to zero", replicas: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cost := NewWorkloadCost("Deployment", tt.replicas, perReplica)
+
+			if cost.Kind != "Deployment" || cost.Name != "web" || cost.Namespace != "default" {
+				t.Errorf("identity: got %s %s/%s, want Deployment default/web", cost.Kind, cost.Namespace, cost.Name)
+			}
+
+			if cost.Replicas != tt.replicas {
+				t.Errorf("replicas: got %d, want %d", cost.Replicas, tt.replicas)
+			}
+
+			n := float64(tt.replicas)
+			if math.Abs(cost.Hourly.CPUCost-perReplica.Hourly.CPUCost*n) > tolerance {
+				t.Errorf("hourly CPU cost: got %.4f, want %.4f", cost.Hourly.CPUCost, perReplica.Hourly.CPUCost*n)
+			}
+
+			if math.Abs(cost.Monthly.TotalCost-perReplica.Monthly.TotalCost*n) > tolerance {
+				t.Errorf("monthly cost: got %.2f, want %.2f", cost.Monthly.TotalCost, perReplica.Monthly.TotalCost*n)
+			}
+		})
+	}
+}
+
+func TestRatesAt(t *testing.T) {
+	t.Parallel()
+	rates, err := ParseRates([]byte(`apiVersion: kcost/v1
+cpu_per_core_per_hour: 0.03
+memory_per_gb_per_hour: 0.003
+periods:
+  - effective_from: 2024-01-01
+    effective_to: 2024-06-30
+    cpu_per_core_per_hour: 0.05
+    memory_per_gb_per_hour: 0.005
+  - effective_from: 2024-07-01
+    cpu_per_core_per_hour: 0.04
+    memory_per_gb_per_hour: 0.004
+`))
+	if err != nil {
+		t.Fatalf("ParseRates failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantCPU float64
+	}{
+		{name: "before any period uses top-level rates", at: time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC), wantCPU: 0.03},
+		{name: "first day of period", at: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), wantCPU: 0.05},
+		{name: "effective_to is inclusive", at: time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), wantCPU: 0.05},
+		{name: "open-ended period", at: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), wantCPU: 0.04},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := rates.At(tt.at).CPUPerCorePerHour; got != tt.wantCPU {
+				t.Errorf("CPU rate: got %.4f, want %.4f", got, tt.wantCPU)
+			}
+		})
+	}
+}
+
+// Note: This test cannot use t.Parallel() because t.Setenv modifies the process environment.
+func TestRatesAtUncoveredDate(t *testing.T) {
+	t.Parallel()
+	periods := `periods:
+  - effective_from: 2024-01-01
+    effective_to: 2024-06-30
+    cpu_per_core_per_hour: 0.05
+    memory_per_gb_per_hour: 0.005
+`
+	// Without base rates, dates outside the period would be priced at zero
+	if _, err := ParseRates([]byte(periods)); err == nil {
+		t.Fatal("expected an error for a periods-only file")
+	}
+
+	rates, err := ParseRates([]byte("cpu_per_core_per_hour: 0.03\nmemory_per_gb_per_hour: 0.003\n" + periods))
+	if err != nil {
+		t.Fatalf("ParseRates failed: %v", err)
+	}
+	at := rates.At(time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	if at.CPUPerCorePerHour != 0.03 || at.MemoryPerGBPerHour != 0.003 {
+		t.Errorf("got %.4f/%.4f, want the base rates 0.0300/0.0030", at.CPUPerCorePerHour, at.MemoryPerGBPerHour)
+	}
+}
+
+func TestLoadRates(t *testing.T) {
+	dir := t.TempDir()
+	writeRates := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
 		}
-	})
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write rates file: %v", err)
+		}
+		return path
+	}
+
+	explicitPath := writeRates("explicit.yaml", "cpu_per_core_per_hour: 0.1\nmemory_per_gb_per_hour: 0.01\n")
+	envPath := writeRates("env.yaml", "cpu_per_core_per_hour: 0.2\nmemory_per_gb_per_hour: 0.02\n")
+	userPath := writeRates("xdg/kcost/rates.yaml", "cpu_per_core_per_hour: 0.3\nmemory_per_gb_per_hour: 0.03\n")
+
+	tests := []struct {
+		name       string
+		explicit   string
+		env        string
+		xdg        string
+		wantPath   string
+		wantCPU    float64
+		wantErr    bool
+		wantBundle bool
+	}{
+		{name: "explicit path wins", explicit: explicitPath, env: envPath, xdg: filepath.Join(dir, "xdg"), wantPath: explicitPath, wantCPU: 0.1},
+		{name: "environment variable", env: envPath, xdg: filepath.Join(dir, "xdg"), wantPath: envPath, wantCPU: 0.2},
+		{name: "XDG config dir", xdg: filepath.Join(dir, "xdg"), wantPath: userPath, wantCPU: 0.3},
+		{name: "embedded defaults", xdg: filepath.Join(dir, "empty"), wantBundle: true},
+		{name: "missing explicit file", explicit: filepath.Join(dir, "missing.yaml"), wantErr: true},
+		{name: "missing env file", env: filepath.Join(dir, "missing.yaml"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(RatesEnvVar, tt.env)
+			t.Setenv("XDG_CONFIG_HOME", tt.xdg)
+
+			file, err := LoadRates(tt.explicit)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadRates failed: %v", err)
+			}
+
+			if tt.wantBundle {
+				if file.Path != "" {
+					t.Errorf("path: got %q, want embedded defaults", file.Path)
+				}
+				if file.Rates.CPUPerCorePerHour <= 0 {
+					t.Errorf("expected positive embedded CPU rate, got %.4f", file.Rates.CPUPerCorePerHour)
+				}
+				if _, _, err := file.LastUpdated(); err != nil {
+					t.Errorf("embedded rates should carry a last updated date: %v", err)
+				}
+				return
+			}
+
+			if file.Path != tt.wantPath {
+				t.Errorf("path: got %q, want %q", file.Path, tt.wantPath)
+			}
+			if file.Rates.CPUPerCorePerHour != tt.wantCPU {
+				t.Errorf("CPU rate: got %.4f, want %.4f", file.Rates.CPUPerCorePerHour, tt.wantCPU)
+			}
+		})
+	}
 }
@@ -0,0 +1,66 @@
+package calculator
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// NodeCost prices a node's allocatable CPU and memory and the part of it that no pod
+// requests
+type NodeCost struct {
+	Name                string
+	InstanceType        string
+	Pods                int
+	CPUAllocatableCores float64
+	MemoryAllocatableGB float64
+	CPURequestedCores   float64
+	MemoryRequestedGB   float64
+	// Hourly, Daily and Monthly price the allocatable capacity
+	Hourly  ResourceCost
+	Daily   ResourceCost
+	Monthly ResourceCost
+	// IdleHourly, IdleDaily and IdleMonthly price the capacity left unrequested
+	IdleHourly  ResourceCost
+	IdleDaily   ResourceCost
+	IdleMonthly ResourceCost
+}
+
+// CalculateNodeCost prices a node's allocatable capacity and its idle remainder.
+// Requests beyond allocatable, which static pods can cause, leave nothing idle
+// rather than a negative amount.
+func CalculateNodeCost(name, instanceType string, pods int, cpuAllocatable, memoryAllocatable, cpuRequested, memoryRequested resource.Quantity, rates Rates) NodeCost {
+	capacity := CalculatePodCost(name, "", cpuAllocatable, memoryAllocatable, rates)
+	idle := CalculatePodCost(name, "", unrequested(cpuAllocatable, cpuRequested), unrequested(memoryAllocatable, memoryRequested), rates)
+
+	return NodeCost{
+		Name:                name,
+		InstanceType:        instanceType,
+		Pods:                pods,
+		CPUAllocatableCores: cores(cpuAllocatable),
+		MemoryAllocatableGB: gigabytes(memoryAllocatable),
+		CPURequestedCores:   cores(cpuRequested),
+		MemoryRequestedGB:   gigabytes(memoryRequested),
+		Hourly:              capacity.Hourly,
+		Daily:               capacity.Daily,
+		Monthly:             capacity.Monthly,
+		IdleHourly:          idle.Hourly,
+		IdleDaily:           idle.Daily,
+		IdleMonthly:         idle.Monthly,
+	}
+}
+
+// IdleFraction returns the idle cost as a fraction of the node's cost, 0 for a node
+// whose capacity costs nothing
+func (c NodeCost) IdleFraction() float64 {
+	if c.Hourly.TotalCost <= 0 {
+		return 0
+	}
+	return c.IdleHourly.TotalCost / c.Hourly.TotalCost
+}
+
+// unrequested returns allocatable minus requested, floored at zero
+func unrequested(allocatable, requested resource.Quantity) resource.Quantity {
+	left := allocatable.DeepCopy()
+	left.Sub(requested)
+	if left.Sign() < 0 {
+		return resource.Quantity{}
+	}
+	return left
+}
@@ -0,0 +1,47 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCalculateNodeCost(t *testing.T) {
+	t.Parallel()
+	rates := Rates{CPUPerCorePerHour: 0.04, MemoryPerGBPerHour: 0.005}
+
+	tests := []struct {
+		name           string
+		cpuRequested   string
+		memRequested   string
+		wantIdleHourly float64
+		wantFraction   float64
+	}{
+		{name: "half requested", cpuRequested: "1", memRequested: "4Gi", wantIdleHourly: 0.06, wantFraction: 0.5},
+		{name: "nothing requested", cpuRequested: "0", memRequested: "0", wantIdleHourly: 0.12, wantFraction: 1},
+		{name: "overcommitted memory", cpuRequested: "1", memRequested: "10Gi", wantIdleHourly: 0.04, wantFraction: 1.0 / 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := CalculateNodeCost("node-a", "m5.large", 3,
+				resource.MustParse("2"), resource.MustParse("8Gi"),
+				resource.MustParse(tt.cpuRequested), resource.MustParse(tt.memRequested), rates)
+
+			if math.Abs(got.Hourly.TotalCost-0.12) > tolerance {
+				t.Errorf("hourly cost: got %.4f, want 0.1200", got.Hourly.TotalCost)
+			}
+			if math.Abs(got.IdleHourly.TotalCost-tt.wantIdleHourly) > tolerance {
+				t.Errorf("idle hourly cost: got %.4f, want %.4f", got.IdleHourly.TotalCost, tt.wantIdleHourly)
+			}
+			if math.Abs(got.IdleMonthly.TotalCost-tt.wantIdleHourly*HoursPerMonth) > tolerance {
+				t.Errorf("idle monthly cost: got %.2f, want %.2f", got.IdleMonthly.TotalCost, tt.wantIdleHourly*HoursPerMonth)
+			}
+			if math.Abs(got.IdleFraction()-tt.wantFraction) > tolerance {
+				t.Errorf("idle fraction: got %.4f, want %.4f", got.IdleFraction(), tt.wantFraction)
+			}
+		})
+	}
+}
@@ -1,73 +1,213 @@
 package calculator
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 	"time"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/config"
 	"gopkg.in/yaml.v3"
 )
 
+// RatesEnvVar names the environment variable that points at a rates file
+const RatesEnvVar = "KCOST_RATES"
+
+// RatesAPIVersion is the schema version written to and accepted in rates files
+const RatesAPIVersion = "kcost/v1"
+
+// DateLayout is the format of dates in rates files
+const DateLayout = "2006-01-02"
+
+// Rates is the rates file schema. The top-level per-unit rates apply whenever
+// no period covers the date being priced; files that only set those two fields
+// (the original format) remain valid. Instances is an optional pricing catalog
+// used to derive per-node rates (see ForInstance). ExtendedResources prices
+// extended resources such as nvidia.com/gpu per unit-hour (per GB-hour for hugepages-*).
+// Persistent volume storage is priced per GB-month by StorageClass, with
+// StoragePerGBPerMonth for classes that are not listed.
 type Rates struct {
-	CPUPerCorePerHour    float64 `yaml:"cpu_per_core_per_hour"`
-	MemoryPerGBPerHour   float64 `yaml:"memory_per_gb_per_hour"`
+	APIVersion           string             `yaml:"apiVersion,omitempty"`
+	Currency             string             `yaml:"currency,omitempty"`
+	LastUpdated          Date               `yaml:"last_updated,omitempty"`
+	Source               string             `yaml:"source,omitempty"`
+	CPUPerCorePerHour    float64            `yaml:"cpu_per_core_per_hour"`
+	MemoryPerGBPerHour   float64            `yaml:"memory_per_gb_per_hour"`
+	Periods              []RatePeriod       `yaml:"periods,omitempty"`
+	Instances            []InstancePrice    `yaml:"instances,omitempty"`
+	ExtendedResources    map[string]float64 `yaml:"extended_resources,omitempty"`
+	StoragePerGBPerMonth float64            `yaml:"storage_per_gb_per_month,omitempty"`
+	StorageClasses       map[string]float64 `yaml:"storage_classes,omitempty"`
+}
+
+// RatePeriod holds the rates in effect between two dates, both inclusive.
+// A zero EffectiveTo leaves the period open-ended.
+type RatePeriod struct {
+	EffectiveFrom      Date    `yaml:"effective_from"`
+	EffectiveTo        Date    `yaml:"effective_to,omitempty"`
+	CPUPerCorePerHour  float64 `yaml:"cpu_per_core_per_hour"`
+	MemoryPerGBPerHour float64 `yaml:"memory_per_gb_per_hour"`
+}
+
+// Covers reports whether t falls within the period
+func (p RatePeriod) Covers(t time.Time) bool {
+	if t.Before(p.EffectiveFrom.Time) {
+		return false
+	}
+	return p.EffectiveTo.IsZero() || t.Before(p.EffectiveTo.AddDate(0, 0, 1))
+}
+
+// At returns a copy of the rates with the per-unit values in effect at t:
+// those of the first period covering t, or the top-level values when none does
+func (r Rates) At(t time.Time) Rates {
+	for _, p := range r.Periods {
+		if p.Covers(t) {
+			r.CPUPerCorePerHour = p.CPUPerCorePerHour
+			r.MemoryPerGBPerHour = p.MemoryPerGBPerHour
+			break
+		}
+	}
+	return r
+}
+
+// Date is a calendar date written as YYYY-MM-DD in rates files
+type Date struct {
+	time.Time
+}
+
+// UnmarshalYAML parses a YYYY-MM-DD scalar
+func (d *Date) UnmarshalYAML(node *yaml.Node) error {
+	t, err := time.Parse(DateLayout, node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid date %q, expected YYYY-MM-DD", node.Line, node.Value)
+	}
+	d.Time = t
+	return nil
+}
+
+// MarshalYAML writes the date as an unquoted YYYY-MM-DD
+func (d Date) MarshalYAML() (any, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: d.Format(DateLayout)}, nil
+}
+
+// RatesFile is a loaded rates file together with where it came from
+type RatesFile struct {
+	Rates Rates
+	// Path is the file the rates were read from; empty for the embedded defaults
+	Path string
+}
+
+// Source describes where the rates were loaded from, for messages
+func (f RatesFile) Source() string {
+	if f.Path == "" {
+		return "built-in default rates"
+	}
+	return f.Path
+}
+
+// LastUpdated returns the file's last_updated date and the number of days since
+func (f RatesFile) LastUpdated() (time.Time, int, error) {
+	if f.Rates.LastUpdated.IsZero() {
+		return time.Time{}, 0, fmt.Errorf("no last_updated date in %s", f.Source())
+	}
+	lastUpdated := f.Rates.LastUpdated.Time
+	daysSince := int(time.Since(lastUpdated).Hours() / 24)
+	return lastUpdated, daysSince, nil
 }
 
 // DefaultRates returns reasonable default pricing
 func DefaultRates() Rates {
 	return Rates{
-		CPUPerCorePerHour:  0.034,
-		MemoryPerGBPerHour: 0.004,
+		CPUPerCorePerHour:    0.034,
+		MemoryPerGBPerHour:   0.004,
+		StoragePerGBPerMonth: 0.08,
 	}
 }
 
+// LoadRates discovers and loads the rates file; see FindRatesFile for the search order
+func LoadRates(explicitPath string) (RatesFile, error) {
+	if path := FindRatesFile(explicitPath); path != "" {
+		return loadRatesFile(path)
+	}
+
+	rates, err := ParseRates(config.DefaultRates)
+	if err != nil {
+		return RatesFile{}, fmt.Errorf("failed to parse built-in rates: %w", err)
+	}
+	return RatesFile{Rates: rates}, nil
+}
+
+// FindRatesFile returns the rates file to use, or "" for the embedded defaults.
+// The first match wins:
+//  1. explicitPath (the --rates-file flag)
+//  2. the file named by $KCOST_RATES
+//  3. $XDG_CONFIG_HOME/kcost/rates.yaml (or ~/.config/kcost/rates.yaml), if it exists
+//  4. the copy of config/rates.yaml embedded in the binary
+//
+// 1 and 2 are returned even if missing, since they were asked for explicitly.
+func FindRatesFile(explicitPath string) string {
+	if explicitPath != "" {
+		return explicitPath
+	}
+
+	if envPath := os.Getenv(RatesEnvVar); envPath != "" {
+		return envPath
+	}
+
+	if userPath, ok := userRatesPath(); ok {
+		if _, err := os.Stat(userPath); err == nil {
+			return userPath
+		}
+	}
+
+	return ""
+}
+
 // LoadRatesFromFile reads rates from a YAML file
 func LoadRatesFromFile(path string) (Rates, error) {
-	data, err := os.ReadFile(path)
+	file, err := loadRatesFile(path)
 	if err != nil {
-		return Rates{}, fmt.Errorf("failed to read rates file: %w", err)
+		return Rates{}, err
+	}
+	return file.Rates, nil
+}
+
+// ParseRates validates and decodes rates from YAML
+func ParseRates(data []byte) (Rates, error) {
+	if errs := ValidateRates(data); len(errs) > 0 {
+		return Rates{}, fmt.Errorf("invalid rates file: %w", errs)
 	}
 
 	var rates Rates
 	if err := yaml.Unmarshal(data, &rates); err != nil {
 		return Rates{}, fmt.Errorf("failed to parse rates YAML: %w", err)
 	}
-
 	return rates, nil
 }
 
-// GetRatesLastUpdated extracts the last updated date from rates file
-// Returns the date and number of days since update, or error if not found
-func GetRatesLastUpdated(path string) (time.Time, int, error) {
-	file, err := os.Open(path)
+func loadRatesFile(path string) (RatesFile, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return time.Time{}, 0, fmt.Errorf("failed to open rates file: %w", err)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "Last updated:") {
-			parts := strings.SplitN(line, "Last updated:", 2)
-			if len(parts) != 2 {
-				continue
-			}
-			dateStr := strings.TrimSpace(parts[1])
-			lastUpdated, err := time.Parse("2006-01-02", dateStr)
-			if err != nil {
-				return time.Time{}, 0, fmt.Errorf("invalid date format: %w", err)
-			}
-			daysSince := int(time.Since(lastUpdated).Hours() / 24)
-			return lastUpdated, daysSince, nil
-		}
+		return RatesFile{}, fmt.Errorf("failed to read rates file: %w", err)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return time.Time{}, 0, fmt.Errorf("error reading file: %w", err)
+	rates, err := ParseRates(data)
+	if err != nil {
+		return RatesFile{}, fmt.Errorf("%s: %w", path, err)
 	}
 
-	return time.Time{}, 0, fmt.Errorf("no 'Last updated' date found in rates file")
+	return RatesFile{Rates: rates, Path: path}, nil
+}
+
+// userRatesPath returns the per-user rates file location under the XDG config dir
+func userRatesPath() (string, bool) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "kcost", "rates.yaml"), true
 }
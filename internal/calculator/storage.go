@@ -0,0 +1,50 @@
+package calculator
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// ClaimCost is the storage cost of a PersistentVolumeClaim
+type ClaimCost struct {
+	Name         string
+	Namespace    string
+	StorageClass string
+	CapacityGB   float64
+	Hourly       float64
+	Daily        float64
+	Monthly      float64
+}
+
+// StorageRate returns the per GB-month rate for a StorageClass, falling back to
+// StoragePerGBPerMonth for classes without their own rate
+func (r Rates) StorageRate(storageClass string) float64 {
+	if rate, ok := r.StorageClasses[storageClass]; ok {
+		return rate
+	}
+	return r.StoragePerGBPerMonth
+}
+
+// CalculateClaimCost computes the cost of a claim's capacity at its StorageClass rate
+func CalculateClaimCost(name, namespace, storageClass string, capacity resource.Quantity, rates Rates) ClaimCost {
+	capacityGB := float64(capacity.Value()) / (1024 * 1024 * 1024)
+	monthly := capacityGB * rates.StorageRate(storageClass)
+	hourly := monthly / HoursPerMonth
+
+	return ClaimCost{
+		Name:         name,
+		Namespace:    namespace,
+		StorageClass: storageClass,
+		CapacityGB:   capacityGB,
+		Hourly:       hourly,
+		Daily:        hourly * HoursPerDay,
+		Monthly:      monthly,
+	}
+}
+
+// AddStorage adds an hourly storage cost to the pod's costs
+func (c *PodCost) AddStorage(hourly float64) {
+	c.Hourly.StorageCost += hourly
+	c.Hourly.TotalCost += hourly
+	c.Daily.StorageCost += hourly * HoursPerDay
+	c.Daily.TotalCost += hourly * HoursPerDay
+	c.Monthly.StorageCost += hourly * HoursPerMonth
+	c.Monthly.TotalCost += hourly * HoursPerMonth
+}
@@ -0,0 +1,53 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCalculateClaimCost(t *testing.T) {
+	t.Parallel()
+	rates := Rates{
+		StoragePerGBPerMonth: 0.08,
+		StorageClasses:       map[string]float64{"premium-rwo": 0.17},
+	}
+
+	tests := []struct {
+		name         string
+		storageClass string
+		capacity     string
+		wantMonthly  float64
+	}{
+		{name: "class with its own rate", storageClass: "premium-rwo", capacity: "100Gi", wantMonthly: 17},
+		{name: "class falls back to default rate", storageClass: "standard", capacity: "50Gi", wantMonthly: 4},
+		{name: "no class", capacity: "10Gi", wantMonthly: 0.8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cost := CalculateClaimCost("data", "db", tt.storageClass, resource.MustParse(tt.capacity), rates)
+			if math.Abs(cost.Monthly-tt.wantMonthly) > tolerance {
+				t.Errorf("monthly cost: got %.4f, want %.4f", cost.Monthly, tt.wantMonthly)
+			}
+			if math.Abs(cost.Hourly*HoursPerMonth-cost.Monthly) > tolerance {
+				t.Errorf("hourly cost %.6f does not add up to monthly %.4f", cost.Hourly, cost.Monthly)
+			}
+		})
+	}
+}
+
+func TestPodCostAddStorage(t *testing.T) {
+	t.Parallel()
+	cost := CalculatePodCost("web", "default", resource.MustParse("1"), resource.MustParse("0"), Rates{CPUPerCorePerHour: 0.034})
+	cost.AddStorage(0.01)
+
+	if math.Abs(cost.Monthly.StorageCost-7.3) > tolerance {
+		t.Errorf("monthly storage cost: got %.4f, want 7.3000", cost.Monthly.StorageCost)
+	}
+	if want := (0.034 + 0.01) * HoursPerMonth; math.Abs(cost.Monthly.TotalCost-want) > tolerance {
+		t.Errorf("monthly total: got %.4f, want %.4f", cost.Monthly.TotalCost, want)
+	}
+}
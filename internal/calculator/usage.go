@@ -0,0 +1,40 @@
+package calculator
+
+import "k8s.io/apimachinery/pkg/api/resource"
+
+// UsageCost compares a pod's measured CPU and memory with its requests, priced at
+// the same rates
+type UsageCost struct {
+	CPURequestCores float64
+	CPUUsageCores   float64
+	MemoryRequestGB float64
+	MemoryUsageGB   float64
+	Hourly          ResourceCost
+	Daily           ResourceCost
+	Monthly         ResourceCost
+}
+
+// CalculateUsageCost prices measured usage alongside the requests it is compared with
+func CalculateUsageCost(cpuRequest, memoryRequest, cpuUsage, memoryUsage resource.Quantity, rates Rates) UsageCost {
+	usage := CalculatePodCost("", "", cpuUsage, memoryUsage, rates)
+	return UsageCost{
+		CPURequestCores: cores(cpuRequest),
+		CPUUsageCores:   cores(cpuUsage),
+		MemoryRequestGB: gigabytes(memoryRequest),
+		MemoryUsageGB:   gigabytes(memoryUsage),
+		Hourly:          usage.Hourly,
+		Daily:           usage.Daily,
+		Monthly:         usage.Monthly,
+	}
+}
+
+// Efficiency returns the usage cost as a fraction of the requested CPU and memory
+// cost. It exceeds 1 when a pod uses more than it requests; ok is false when the
+// pod has no usage data or requests no CPU or memory.
+func (c PodCost) Efficiency() (efficiency float64, ok bool) {
+	requested := c.Hourly.CPUCost + c.Hourly.MemoryCost
+	if c.Usage == nil || requested <= 0 {
+		return 0, false
+	}
+	return c.Usage.Hourly.TotalCost / requested, true
+}
@@ -0,0 +1,49 @@
+package calculator
+
+import (
+	"math"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPodCostEfficiency(t *testing.T) {
+	t.Parallel()
+	rates := Rates{CPUPerCorePerHour: 0.04, MemoryPerGBPerHour: 0.005}
+
+	tests := []struct {
+		name           string
+		cpuRequest     string
+		memoryRequest  string
+		cpuUsage       string
+		memoryUsage    string
+		wantEfficiency float64
+		wantOK         bool
+	}{
+		{name: "quarter of requests used", cpuRequest: "1", memoryRequest: "2Gi", cpuUsage: "250m", memoryUsage: "512Mi", wantEfficiency: 0.25, wantOK: true},
+		{name: "bursting above requests", cpuRequest: "100m", memoryRequest: "0", cpuUsage: "300m", memoryUsage: "0", wantEfficiency: 3, wantOK: true},
+		{name: "nothing requested", cpuRequest: "0", memoryRequest: "0", cpuUsage: "50m", memoryUsage: "64Mi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cpuRequest, memoryRequest := resource.MustParse(tt.cpuRequest), resource.MustParse(tt.memoryRequest)
+			cost := CalculatePodCost("web", "shop", cpuRequest, memoryRequest, rates)
+			usage := CalculateUsageCost(cpuRequest, memoryRequest, resource.MustParse(tt.cpuUsage), resource.MustParse(tt.memoryUsage), rates)
+			cost.Usage = &usage
+
+			got, ok := cost.Efficiency()
+			if ok != tt.wantOK {
+				t.Fatalf("ok: got %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.wantEfficiency) > tolerance {
+				t.Errorf("efficiency: got %.4f, want %.4f", got, tt.wantEfficiency)
+			}
+		})
+	}
+
+	if _, ok := (PodCost{Hourly: ResourceCost{CPUCost: 1}}).Efficiency(); ok {
+		t.Error("pod without usage data: got ok, want not ok")
+	}
+}
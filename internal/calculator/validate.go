@@ -0,0 +1,312 @@
+package calculator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a rates file, located by line and column
+type ValidationError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// ValidationErrors collects every problem found in a rates file
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// fieldValidator checks the value node of a mapping key
+type fieldValidator func(v *validator, value *yaml.Node)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ratesFields lists the keys allowed at the top level of a rates file
+var ratesFields = map[string]fieldValidator{
+	"apiVersion": func(v *validator, n *yaml.Node) {
+		if v.string(n) && n.Value != RatesAPIVersion {
+			v.errorf(n, "unsupported apiVersion %q, expected %q", n.Value, RatesAPIVersion)
+		}
+	},
+	"currency": func(v *validator, n *yaml.Node) {
+		if v.string(n) && !currencyPattern.MatchString(n.Value) {
+			v.errorf(n, "currency must be a three-letter ISO 4217 code such as USD, got %q", n.Value)
+		}
+	},
+	"last_updated":             func(v *validator, n *yaml.Node) { v.date(n) },
+	"source":                   func(v *validator, n *yaml.Node) { v.string(n) },
+	"cpu_per_core_per_hour":    func(v *validator, n *yaml.Node) { v.rate(n) },
+	"memory_per_gb_per_hour":   func(v *validator, n *yaml.Node) { v.rate(n) },
+	"periods":                  func(v *validator, n *yaml.Node) { v.periods(n) },
+	"instances":                func(v *validator, n *yaml.Node) { v.instances(n) },
+	"extended_resources":       func(v *validator, n *yaml.Node) { v.extendedResources(n) },
+	"storage_per_gb_per_month": func(v *validator, n *yaml.Node) { v.rate(n) },
+	"storage_classes":          func(v *validator, n *yaml.Node) { v.rateMap(n, "storage class", nil) },
+}
+
+// periodFields lists the keys allowed in each entry of periods
+var periodFields = map[string]fieldValidator{
+	"effective_from":         func(v *validator, n *yaml.Node) { v.date(n) },
+	"effective_to":           func(v *validator, n *yaml.Node) { v.date(n) },
+	"cpu_per_core_per_hour":  func(v *validator, n *yaml.Node) { v.rate(n) },
+	"memory_per_gb_per_hour": func(v *validator, n *yaml.Node) { v.rate(n) },
+}
+
+// instanceFields lists the keys allowed in each entry of instances
+var instanceFields = map[string]fieldValidator{
+	"provider":      func(v *validator, n *yaml.Node) { v.string(n) },
+	"region":        func(v *validator, n *yaml.Node) { v.string(n) },
+	"instance_type": func(v *validator, n *yaml.Node) { v.string(n) },
+	"vcpu": func(v *validator, n *yaml.Node) {
+		if f, ok := v.number(n); ok && f <= 0 {
+			v.errorf(n, "vcpu must be positive, got %s", n.Value)
+		}
+	},
+	"memory_gb":    func(v *validator, n *yaml.Node) { v.rate(n) },
+	"gpu":          func(v *validator, n *yaml.Node) { v.count(n) },
+	"hourly_price": func(v *validator, n *yaml.Node) { v.rate(n) },
+}
+
+// ValidateRates checks a rates file against the schema and returns every problem
+// found, each with the line and column it was found at. It returns nil for a valid file.
+func ValidateRates(data []byte) ValidationErrors {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return ValidationErrors{{Message: err.Error()}}
+	}
+	if len(doc.Content) == 0 {
+		return ValidationErrors{{Message: "rates file is empty"}}
+	}
+
+	v := &validator{}
+	// The base rates price every date no period covers, so they are required
+	// even when periods are set
+	v.mapping(doc.Content[0], ratesFields, []string{"cpu_per_core_per_hour", "memory_per_gb_per_hour"})
+	return v.errs
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) errorf(n *yaml.Node, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Line: n.Line, Column: n.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// mapping checks each key of a mapping node, rejecting unknown and duplicate keys
+// and reporting required keys that are missing
+func (v *validator) mapping(n *yaml.Node, fields map[string]fieldValidator, required []string) {
+	if n.Kind != yaml.MappingNode {
+		v.errorf(n, "expected a mapping")
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		check, ok := fields[key.Value]
+		if !ok {
+			v.errorf(key, "unknown field %q", key.Value)
+			continue
+		}
+		if seen[key.Value] {
+			v.errorf(key, "duplicate field %q", key.Value)
+			continue
+		}
+		seen[key.Value] = true
+		check(v, value)
+	}
+
+	for _, name := range required {
+		if !seen[name] {
+			v.errorf(n, "missing required field %q", name)
+		}
+	}
+}
+
+func (v *validator) string(n *yaml.Node) bool {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		v.errorf(n, "expected a string")
+		return false
+	}
+	return true
+}
+
+func (v *validator) date(n *yaml.Node) (time.Time, bool) {
+	if n.Kind != yaml.ScalarNode {
+		v.errorf(n, "expected a date (YYYY-MM-DD)")
+		return time.Time{}, false
+	}
+	t, err := time.Parse(DateLayout, n.Value)
+	if err != nil {
+		v.errorf(n, "invalid date %q, expected YYYY-MM-DD", n.Value)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (v *validator) number(n *yaml.Node) (float64, bool) {
+	if n.Kind != yaml.ScalarNode || (n.Tag != "!!float" && n.Tag != "!!int") {
+		v.errorf(n, "expected a number")
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(n.Value, 64)
+	if err != nil {
+		v.errorf(n, "invalid number %q", n.Value)
+		return 0, false
+	}
+	return f, true
+}
+
+func (v *validator) rate(n *yaml.Node) {
+	if f, ok := v.number(n); ok && f < 0 {
+		v.errorf(n, "rate must not be negative, got %s", n.Value)
+	}
+}
+
+func (v *validator) count(n *yaml.Node) {
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+		v.errorf(n, "expected a whole number")
+		return
+	}
+	if i, err := strconv.Atoi(n.Value); err != nil || i < 0 {
+		v.errorf(n, "expected a non-negative whole number, got %s", n.Value)
+	}
+}
+
+// extendedResources checks the resource name to rate mapping. CPU and memory are
+// priced by the top-level rates, so they are rejected here.
+func (v *validator) extendedResources(n *yaml.Node) {
+	v.rateMap(n, "resource", func(key *yaml.Node) bool {
+		if key.Value == "cpu" || key.Value == "memory" {
+			v.errorf(key, "%s is priced by the top-level rates, not extended_resources", key.Value)
+			return false
+		}
+		return true
+	})
+}
+
+// rateMap checks a mapping of names to rates, rejecting duplicate names and any
+// name the allow callback reports on
+func (v *validator) rateMap(n *yaml.Node, what string, allow func(key *yaml.Node) bool) {
+	if n.Kind != yaml.MappingNode {
+		v.errorf(n, "expected a mapping of %s names to rates", what)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if allow != nil && !allow(key) {
+			continue
+		}
+		if seen[key.Value] {
+			v.errorf(key, "duplicate %s %q", what, key.Value)
+			continue
+		}
+		seen[key.Value] = true
+		v.rate(value)
+	}
+}
+
+// instances checks each catalog entry and rejects duplicate provider/region/type entries
+func (v *validator) instances(n *yaml.Node) {
+	if n.Kind != yaml.SequenceNode {
+		v.errorf(n, "expected a list of instance types")
+		return
+	}
+
+	seen := make(map[string]int)
+	for _, item := range n.Content {
+		v.mapping(item, instanceFields, []string{"instance_type", "vcpu", "memory_gb", "hourly_price"})
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+
+		fields := make(map[string]string)
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			fields[item.Content[i].Value] = item.Content[i+1].Value
+		}
+		key := fields["provider"] + "/" + fields["region"] + "/" + fields["instance_type"]
+		if line, dup := seen[key]; dup {
+			v.errorf(item, "duplicate instance %s (first defined on line %d)", fields["instance_type"], line)
+			continue
+		}
+		seen[key] = item.Line
+	}
+}
+
+// periods checks each period and that no two periods overlap
+func (v *validator) periods(n *yaml.Node) {
+	if n.Kind != yaml.SequenceNode {
+		v.errorf(n, "expected a list of periods")
+		return
+	}
+
+	type span struct {
+		node     *yaml.Node
+		from, to time.Time
+	}
+	var spans []span
+
+	for _, item := range n.Content {
+		v.mapping(item, periodFields, []string{"effective_from", "cpu_per_core_per_hour", "memory_per_gb_per_hour"})
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+
+		s := span{node: item}
+		fromOK, toOK := false, true
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key, value := item.Content[i], item.Content[i+1]
+			switch key.Value {
+			case "effective_from":
+				s.from, fromOK = parseDate(value)
+			case "effective_to":
+				s.to, toOK = parseDate(value)
+			}
+		}
+		if !fromOK || !toOK {
+			continue
+		}
+		if !s.to.IsZero() && s.to.Before(s.from) {
+			v.errorf(item, "effective_to %s is before effective_from %s", s.to.Format(DateLayout), s.from.Format(DateLayout))
+			continue
+		}
+		spans = append(spans, s)
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].from.Before(spans[j].from) })
+	for i := 1; i < len(spans); i++ {
+		prev, cur := spans[i-1], spans[i]
+		if prev.to.IsZero() || !cur.from.After(prev.to) {
+			v.errorf(cur.node, "period starting %s overlaps the period starting %s (line %d)",
+				cur.from.Format(DateLayout), prev.from.Format(DateLayout), prev.node.Line)
+		}
+	}
+}
+
+func parseDate(n *yaml.Node) (time.Time, bool) {
+	t, err := time.Parse(DateLayout, n.Value)
+	return t, err == nil
+}
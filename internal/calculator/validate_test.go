@@ -0,0 +1,183 @@
+package calculator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/config"
+)
+
+func TestValidateRates(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		content  string
+		wantErrs []string // expected "line N" prefixes or message fragments, in order
+	}{
+		{
+			name: "valid structured file",
+			content: `apiVersion: kcost/v1
+currency: EUR
+last_updated: 2025-01-31
+source: on-prem amortization
+cpu_per_core_per_hour: 0.02
+memory_per_gb_per_hour: 0.002
+periods:
+  - effective_from: 2024-01-01
+    effective_to: 2024-12-31
+    cpu_per_core_per_hour: 0.025
+    memory_per_gb_per_hour: 0.0025
+`,
+		},
+		{
+			name:    "legacy two-field file",
+			content: "cpu_per_core_per_hour: 0.05\nmemory_per_gb_per_hour: 0.006\n",
+		},
+		{
+			name: "field errors carry line numbers",
+			content: `apiVersion: kcost/v2
+currency: dollars
+last_updated: 29/11/2025
+cpu_per_core_per_hour: cheap
+memory_per_gb_per_hour: -1
+cpu_rate: 0.1
+`,
+			wantErrs: []string{
+				"line 1, column 13: unsupported apiVersion",
+				"line 2, column 11: currency must be",
+				"line 3, column 15: invalid date",
+				"line 4, column 24: expected a number",
+				"line 5, column 25: rate must not be negative",
+				`line 6, column 1: unknown field "cpu_rate"`,
+			},
+		},
+		{
+			name:    "missing base rates",
+			content: "currency: USD\ncpu_per_core_per_hour: 0.05\n",
+			wantErrs: []string{
+				`line 1, column 1: missing required field "memory_per_gb_per_hour"`,
+			},
+		},
+		{
+			name: "periods do not replace the base rates",
+			content: `periods:
+  - effective_from: 2024-01-01
+    cpu_per_core_per_hour: 0.05
+    memory_per_gb_per_hour: 0.005
+`,
+			wantErrs: []string{
+				`line 1, column 1: missing required field "cpu_per_core_per_hour"`,
+				`line 1, column 1: missing required field "memory_per_gb_per_hour"`,
+			},
+		},
+		{
+			name: "period problems",
+			content: `cpu_per_core_per_hour: 0.03
+memory_per_gb_per_hour: 0.003
+periods:
+  - effective_from: 2024-01-01
+    cpu_per_core_per_hour: 0.05
+    memory_per_gb_per_hour: 0.005
+  - effective_from: 2024-03-01
+    effective_to: 2024-04-01
+    cpu_per_core_per_hour: 0.04
+    memory_per_gb_per_hour: 0.004
+  - effective_from: 2025-02-01
+    effective_to: 2025-01-01
+    cpu_per_core_per_hour: 0.04
+    memory_per_gb_per_hour: 0.004
+  - effective_to: 2026-01-01
+    cpu_per_core_per_hour: 0.04
+`,
+			wantErrs: []string{
+				"line 11, column 5: effective_to 2025-01-01 is before effective_from",
+				`line 15, column 5: missing required field "effective_from"`,
+				`line 15, column 5: missing required field "memory_per_gb_per_hour"`,
+				"line 7, column 5: period starting 2024-03-01 overlaps the period starting 2024-01-01 (line 4)",
+			},
+		},
+		{
+			name: "instance problems",
+			content: `cpu_per_core_per_hour: 0.03
+memory_per_gb_per_hour: 0.003
+instances:
+  - provider: aws
+    region: us-east-1
+    instance_type: m5.large
+    vcpu: 2
+    memory_gb: 8
+    hourly_price: 0.096
+  - provider: aws
+    region: us-east-1
+    instance_type: m5.large
+    vcpu: 2
+    memory_gb: 8
+    hourly_price: 0.1
+  - instance_type: c5.xlarge
+    vcpu: 4
+    hourly_price: -0.17
+`,
+			wantErrs: []string{
+				"line 10, column 5: duplicate instance",
+				"line 18, column 19: rate must not be negative",
+				`line 16, column 5: missing required field "memory_gb"`,
+			},
+		},
+		{
+			name: "extended resource problems",
+			content: `cpu_per_core_per_hour: 0.03
+memory_per_gb_per_hour: 0.003
+extended_resources:
+  nvidia.com/gpu: 2.5
+  hugepages-2Mi: free
+  cpu: 0.1
+`,
+			wantErrs: []string{
+				"line 5, column 18: expected a number",
+				"line 6, column 3: cpu is priced by the top-level rates",
+			},
+		},
+		{
+			name: "storage problems",
+			content: `cpu_per_core_per_hour: 0.03
+memory_per_gb_per_hour: 0.003
+storage_per_gb_per_month: -0.1
+storage_classes:
+  gp3: 0.08
+  gp3: 0.1
+`,
+			wantErrs: []string{
+				"line 3, column 27: rate must not be negative",
+				`line 6, column 3: duplicate storage class "gp3"`,
+			},
+		},
+		{
+			name:     "YAML syntax error",
+			content:  "cpu_per_core_per_hour: [0.1\n",
+			wantErrs: []string{"yaml: line"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			errs := ValidateRates([]byte(tt.content))
+
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("error count: got %d (%v), want %d", len(errs), errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i].Error(), want) {
+					t.Errorf("error %d: got %q, want it to contain %q", i, errs[i].Error(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestBundledRatesAreValid(t *testing.T) {
+	t.Parallel()
+	if errs := ValidateRates(config.DefaultRates); len(errs) > 0 {
+		t.Errorf("config/rates.yaml is invalid: %v", errs)
+	}
+}
@@ -0,0 +1,298 @@
+// Package exporter serves cost estimates as Prometheus metrics
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// Other is the pod or namespace label value that series beyond the cardinality
+// limits are summed into. Kubernetes names cannot start with an underscore, so it
+// never collides with a real pod or namespace.
+const Other = "_other"
+
+// Snapshot is one computation of the costs to export
+type Snapshot struct {
+	Pods      []calculator.PodCost
+	Orphans   []calculator.ClaimCost
+	Summaries []analyzer.NamespaceSummary
+	Rates     calculator.Rates
+	// Time is when the snapshot was taken and Duration how long it took
+	Time     time.Time
+	Duration time.Duration
+}
+
+// Limits bound the number of series exported; zero means no limit
+type Limits struct {
+	// MaxPods keeps the most expensive pods; the rest are summed per namespace
+	// into a pod="_other" series
+	MaxPods int
+	// MaxNamespaces keeps the most expensive namespaces; the rest are summed into
+	// namespace="_other" and their pods into a single pod="_other" series in it
+	MaxNamespaces int
+}
+
+// Exporter holds the latest snapshot and serves it in the Prometheus text format.
+// It is safe for concurrent use.
+type Exporter struct {
+	limits Limits
+
+	mu       sync.RWMutex
+	snapshot *Snapshot
+	failures int
+}
+
+// New returns an exporter with no snapshot; until Update is called it serves only
+// the refresh failure counter
+func New(limits Limits) *Exporter {
+	return &Exporter{limits: limits}
+}
+
+// Update replaces the exported snapshot
+func (e *Exporter) Update(s Snapshot) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.snapshot = &s
+}
+
+// RecordFailure counts a refresh that failed; the previous snapshot stays exported
+func (e *Exporter) RecordFailure() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures++
+}
+
+// Ready reports whether a snapshot has been taken
+func (e *Exporter) Ready() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.snapshot != nil
+}
+
+// ServeHTTP writes the metrics
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	snapshot, failures := e.snapshot, e.failures
+	e.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if snapshot != nil {
+		if err := Write(&buf, *snapshot, e.limits); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	writeFamily(&buf, "kcost_refresh_failures_total", "counter", "Cost refreshes that failed since the exporter started.",
+		[]sample{{value: float64(failures)}})
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// Write renders a snapshot in the Prometheus text exposition format, applying the
+// cardinality limits
+func Write(w io.Writer, s Snapshot, limits Limits) error {
+	summaries, pods, foldedNamespaces := limitNamespaces(s.Summaries, s.Pods, limits.MaxNamespaces)
+	pods, foldedPods := limitPods(pods, limits.MaxPods)
+
+	var buf bytes.Buffer
+	var podCosts, podMonthly []sample
+	for _, p := range pods {
+		for _, c := range components(p.Hourly) {
+			podCosts = append(podCosts, sample{labels: []label{{"namespace", p.Namespace}, {"pod", p.Name}, {"resource", c.resource}}, value: c.cost})
+		}
+		podMonthly = append(podMonthly, sample{labels: []label{{"namespace", p.Namespace}, {"pod", p.Name}}, value: p.Monthly.TotalCost})
+	}
+	writeFamily(&buf, "kcost_pod_hourly_cost", "gauge", "Hourly cost of a pod's requests by resource; sum over resource for the pod's total.", podCosts)
+	writeFamily(&buf, "kcost_pod_monthly_cost", "gauge", "Monthly cost of a pod's requests.", podMonthly)
+
+	var nsHourly, nsMonthly, nsPods, nsOrphaned []sample
+	for _, ns := range summaries {
+		labels := []label{{"namespace", ns.Namespace}}
+		nsHourly = append(nsHourly, sample{labels: labels, value: ns.HourlyCost})
+		nsMonthly = append(nsMonthly, sample{labels: labels, value: ns.MonthlyCost})
+		nsPods = append(nsPods, sample{labels: labels, value: float64(ns.TotalPods)})
+		if ns.OrphanedClaims > 0 {
+			nsOrphaned = append(nsOrphaned, sample{labels: labels, value: ns.OrphanedMonthlyCost})
+		}
+	}
+	writeFamily(&buf, "kcost_namespace_hourly_cost", "gauge", "Hourly cost of a namespace, including orphaned storage.", nsHourly)
+	writeFamily(&buf, "kcost_namespace_monthly_cost", "gauge", "Monthly cost of a namespace, including orphaned storage.", nsMonthly)
+	writeFamily(&buf, "kcost_namespace_pods", "gauge", "Pods priced in a namespace.", nsPods)
+	writeFamily(&buf, "kcost_namespace_orphaned_storage_monthly_cost", "gauge", "Monthly cost of bound PVCs no pod mounts.", nsOrphaned)
+
+	total := analyzer.Total(s.Summaries)
+	writeFamily(&buf, "kcost_total_monthly_cost", "gauge", "Monthly cost of everything priced.", []sample{{value: total.MonthlyCost}})
+	writeFamily(&buf, "kcost_rate_cpu_core_hour", "gauge", "Flat rate per CPU core-hour.", []sample{{value: s.Rates.CPUPerCorePerHour}})
+	writeFamily(&buf, "kcost_rate_memory_gb_hour", "gauge", "Flat rate per GB-hour of memory.", []sample{{value: s.Rates.MemoryPerGBPerHour}})
+	writeFamily(&buf, "kcost_series_folded", "gauge", "Pods and namespaces summed into _other series by the cardinality limits.", []sample{
+		{labels: []label{{"kind", "namespace"}}, value: float64(foldedNamespaces)},
+		{labels: []label{{"kind", "pod"}}, value: float64(foldedPods)},
+	})
+	writeFamily(&buf, "kcost_last_refresh_timestamp_seconds", "gauge", "When the exported costs were computed.",
+		[]sample{{value: float64(s.Time.UnixMilli()) / 1000}})
+	writeFamily(&buf, "kcost_refresh_duration_seconds", "gauge", "How long the last refresh took.", []sample{{value: s.Duration.Seconds()}})
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+type resourceCost struct {
+	resource string
+	cost     float64
+}
+
+// components splits a cost by resource: CPU and memory always, storage and
+// extended resources when the pod is charged for them
+func components(c calculator.ResourceCost) []resourceCost {
+	out := []resourceCost{{"cpu", c.CPUCost}, {"memory", c.MemoryCost}}
+	if c.StorageCost > 0 {
+		out = append(out, resourceCost{"storage", c.StorageCost})
+	}
+	names := make([]string, 0, len(c.Extended))
+	for name := range c.Extended {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out = append(out, resourceCost{name, c.Extended[name]})
+	}
+	return out
+}
+
+// limitNamespaces keeps the limit most expensive namespaces and folds the rest into
+// Other. The pods of folded namespaces are summed into a single pod named Other, so
+// pods sharing a name across folded namespaces do not produce duplicate series. It
+// returns how many namespaces were folded.
+func limitNamespaces(summaries []analyzer.NamespaceSummary, pods []calculator.PodCost, limit int) ([]analyzer.NamespaceSummary, []calculator.PodCost, int) {
+	if limit <= 0 || len(summaries) <= limit {
+		return summaries, pods, 0
+	}
+
+	sorted := append([]analyzer.NamespaceSummary(nil), summaries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MonthlyCost > sorted[j].MonthlyCost })
+	kept := make(map[string]bool, limit)
+	other := analyzer.NamespaceSummary{Namespace: Other}
+	for i, s := range sorted {
+		if i < limit {
+			kept[s.Namespace] = true
+			continue
+		}
+		other.TotalPods += s.TotalPods
+		other.HourlyCost += s.HourlyCost
+		other.DailyCost += s.DailyCost
+		other.MonthlyCost += s.MonthlyCost
+		other.OrphanedClaims += s.OrphanedClaims
+		other.OrphanedMonthlyCost += s.OrphanedMonthlyCost
+	}
+
+	limited := make([]calculator.PodCost, 0, len(pods))
+	folded := calculator.PodCost{Name: Other, Namespace: Other}
+	var anyFolded bool
+	for _, p := range pods {
+		if kept[p.Namespace] {
+			limited = append(limited, p)
+			continue
+		}
+		addPodCost(&folded, p)
+		anyFolded = true
+	}
+	if anyFolded {
+		limited = append(limited, folded)
+	}
+	return append(sorted[:limit:limit], other), limited, len(sorted) - limit
+}
+
+// limitPods keeps the limit most expensive pods and sums the rest per namespace into
+// a pod named Other. Pods already named Other, from folded namespaces, are always
+// kept and absorb the pods folded in their namespace. It returns how many pods were
+// folded.
+func limitPods(pods []calculator.PodCost, limit int) ([]calculator.PodCost, int) {
+	var limited, ranked []calculator.PodCost
+	others := make(map[string]int)
+	for _, p := range pods {
+		if p.Name == Other {
+			others[p.Namespace] = len(limited)
+			limited = append(limited, p)
+			continue
+		}
+		ranked = append(ranked, p)
+	}
+	if limit <= 0 || len(ranked) <= limit {
+		return pods, 0
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Hourly.TotalCost > ranked[j].Hourly.TotalCost })
+	limited = append(limited, ranked[:limit]...)
+	for _, p := range ranked[limit:] {
+		i, ok := others[p.Namespace]
+		if !ok {
+			i = len(limited)
+			others[p.Namespace] = i
+			limited = append(limited, calculator.PodCost{Name: Other, Namespace: p.Namespace})
+		}
+		addPodCost(&limited[i], p)
+	}
+	return limited, len(ranked) - limit
+}
+
+// addPodCost adds a pod's costs to sum
+func addPodCost(sum *calculator.PodCost, p calculator.PodCost) {
+	sum.Hourly = sum.Hourly.Add(p.Hourly)
+	sum.Daily = sum.Daily.Add(p.Daily)
+	sum.Monthly = sum.Monthly.Add(p.Monthly)
+}
+
+type label struct {
+	name, value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeFamily writes a metric's HELP and TYPE lines and its samples, sorted by
+// label values so output is stable between scrapes. Families without samples are
+// left out.
+func writeFamily(w io.Writer, name, kind, help string, samples []sample) {
+	if len(samples) == 0 {
+		return
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		a, b := samples[i].labels, samples[j].labels
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k].value != b[k].value {
+				return a[k].value < b[k].value
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for _, s := range samples {
+		fmt.Fprint(w, name)
+		if len(s.labels) > 0 {
+			pairs := make([]string, len(s.labels))
+			for i, l := range s.labels {
+				pairs[i] = fmt.Sprintf(`%s="%s"`, l.name, labelEscaper.Replace(l.value))
+			}
+			fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+		}
+		fmt.Fprintf(w, " %s\n", strconv.FormatFloat(s.value, 'g', -1, 64))
+	}
+}
@@ -0,0 +1,186 @@
+package exporter
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func testSnapshot() Snapshot {
+	pods := []calculator.PodCost{
+		{Name: "web-1", Namespace: "shop", Hourly: calculator.ResourceCost{CPUCost: 0.03, MemoryCost: 0.01, TotalCost: 0.04}, Monthly: calculator.ResourceCost{TotalCost: 30}},
+		{Name: "web-2", Namespace: "shop", Hourly: calculator.ResourceCost{CPUCost: 0.02, MemoryCost: 0.01, TotalCost: 0.03}, Monthly: calculator.ResourceCost{TotalCost: 20}},
+		{Name: "train", Namespace: "ml", Hourly: calculator.ResourceCost{CPUCost: 0.1, MemoryCost: 0.05, StorageCost: 0.01, Extended: map[string]float64{"nvidia.com/gpu": 2}, TotalCost: 2.16}, Monthly: calculator.ResourceCost{TotalCost: 1500}},
+		{Name: "job", Namespace: "batch", Hourly: calculator.ResourceCost{CPUCost: 0.01, TotalCost: 0.01}, Monthly: calculator.ResourceCost{TotalCost: 10}},
+	}
+	return Snapshot{
+		Pods:      pods,
+		Summaries: analyzer.AggregateByNamespace(pods),
+		Rates:     calculator.Rates{CPUPerCorePerHour: 0.03, MemoryPerGBPerHour: 0.004},
+		Time:      time.Unix(1700000000, 0),
+		Duration:  1500 * time.Millisecond,
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		limits  Limits
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "no limits",
+			want: []string{
+				"# HELP kcost_pod_hourly_cost ",
+				"# TYPE kcost_pod_hourly_cost gauge\n",
+				`kcost_pod_hourly_cost{namespace="shop",pod="web-1",resource="cpu"} 0.03` + "\n",
+				`kcost_pod_hourly_cost{namespace="ml",pod="train",resource="storage"} 0.01` + "\n",
+				`kcost_pod_hourly_cost{namespace="ml",pod="train",resource="nvidia.com/gpu"} 2` + "\n",
+				`kcost_pod_monthly_cost{namespace="ml",pod="train"} 1500` + "\n",
+				`kcost_namespace_monthly_cost{namespace="shop"} 50` + "\n",
+				`kcost_namespace_pods{namespace="shop"} 2` + "\n",
+				"kcost_total_monthly_cost 1560\n",
+				"kcost_rate_cpu_core_hour 0.03\n",
+				`kcost_series_folded{kind="pod"} 0` + "\n",
+				"kcost_last_refresh_timestamp_seconds 1.7e+09\n",
+				"kcost_refresh_duration_seconds 1.5\n",
+			},
+			notWant: []string{
+				`pod="web-1",resource="storage"`,
+				`"_other"`,
+				"kcost_namespace_orphaned_storage_monthly_cost",
+			},
+		},
+		{
+			name:   "pod limit",
+			limits: Limits{MaxPods: 2},
+			want: []string{
+				`kcost_pod_monthly_cost{namespace="ml",pod="train"} 1500` + "\n",
+				`kcost_pod_monthly_cost{namespace="shop",pod="web-1"} 30` + "\n",
+				`kcost_pod_monthly_cost{namespace="shop",pod="_other"} 20` + "\n",
+				`kcost_pod_monthly_cost{namespace="batch",pod="_other"} 10` + "\n",
+				`kcost_series_folded{kind="pod"} 2` + "\n",
+			},
+			notWant: []string{`pod="web-2"`, `pod="job"`},
+		},
+		{
+			name:   "namespace limit",
+			limits: Limits{MaxNamespaces: 2},
+			want: []string{
+				`kcost_namespace_monthly_cost{namespace="_other"} 10` + "\n",
+				`kcost_namespace_monthly_cost{namespace="shop"} 50` + "\n",
+				`kcost_pod_monthly_cost{namespace="_other",pod="_other"} 10` + "\n",
+				`kcost_series_folded{kind="namespace"} 1` + "\n",
+				"kcost_total_monthly_cost 1560\n",
+			},
+			notWant: []string{`namespace="batch"`, `pod="job"`},
+		},
+		{
+			name:   "namespace and pod limits",
+			limits: Limits{MaxPods: 1, MaxNamespaces: 2},
+			want: []string{
+				`kcost_pod_monthly_cost{namespace="ml",pod="train"} 1500` + "\n",
+				`kcost_pod_monthly_cost{namespace="shop",pod="_other"} 50` + "\n",
+				`kcost_pod_monthly_cost{namespace="_other",pod="_other"} 10` + "\n",
+				`kcost_series_folded{kind="pod"} 2` + "\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := Write(&buf, testSnapshot(), tt.limits); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFamilyEscapesLabels(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	writeFamily(&buf, "kcost_test", "gauge", "Test.", []sample{{labels: []label{{"name", "a\"b\\c\nd"}}, value: 1}})
+	if got, want := buf.String(), "# HELP kcost_test Test.\n# TYPE kcost_test gauge\nkcost_test{name=\"a\\\"b\\\\c\\nd\"} 1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExporterServeHTTP(t *testing.T) {
+	t.Parallel()
+	e := New(Limits{})
+	e.RecordFailure()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if e.Ready() {
+		t.Error("exporter ready before the first update")
+	}
+	if got, want := rec.Body.String(), "# HELP kcost_refresh_failures_total Cost refreshes that failed since the exporter started.\n# TYPE kcost_refresh_failures_total counter\nkcost_refresh_failures_total 1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	e.Update(testSnapshot())
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !e.Ready() {
+		t.Error("exporter not ready after an update")
+	}
+	if !strings.Contains(rec.Body.String(), "kcost_total_monthly_cost 1560\n") {
+		t.Errorf("metrics missing total:\n%s", rec.Body.String())
+	}
+}
+
+func TestWriteFoldsNamespacePods(t *testing.T) {
+	t.Parallel()
+	pods := []calculator.PodCost{
+		{Name: "web-1", Namespace: "shop", Hourly: calculator.ResourceCost{CPUCost: 1, TotalCost: 1}, Monthly: calculator.ResourceCost{TotalCost: 730}},
+		{Name: "redis-0", Namespace: "cache-a", Hourly: calculator.ResourceCost{MemoryCost: 0.25, TotalCost: 0.25}, Monthly: calculator.ResourceCost{TotalCost: 20}},
+		{Name: "redis-0", Namespace: "cache-b", Hourly: calculator.ResourceCost{MemoryCost: 0.5, TotalCost: 0.5}, Monthly: calculator.ResourceCost{TotalCost: 10}},
+	}
+	s := Snapshot{Pods: pods, Summaries: analyzer.AggregateByNamespace(pods)}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, s, Limits{MaxNamespaces: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		`kcost_pod_monthly_cost{namespace="_other",pod="_other"} 30` + "\n",
+		`kcost_pod_hourly_cost{namespace="_other",pod="_other",resource="memory"} 0.75` + "\n",
+		`kcost_namespace_monthly_cost{namespace="_other"} 30` + "\n",
+		`kcost_series_folded{kind="namespace"} 2` + "\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, `kcost_pod_monthly_cost{namespace="_other"`); n != 1 {
+		t.Errorf("got %d pod series in _other, want 1:\n%s", n, got)
+	}
+	if strings.Contains(got, "redis-0") {
+		t.Errorf("output contains a pod of a folded namespace:\n%s", got)
+	}
+}
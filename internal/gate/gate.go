@@ -0,0 +1,290 @@
+// Package gate checks costs against budgets, for blocking changes in CI
+package gate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Levels of a budget violation, in increasing severity
+const (
+	LevelWarn = "warn"
+	LevelFail = "fail"
+)
+
+// Rules a budget can be violated by
+const (
+	RuleMaxMonthly  = "max_monthly"
+	RuleMaxIncrease = "max_increase"
+)
+
+// Scopes a budget applies to
+const (
+	ScopeTotal     = "total"
+	ScopeNamespace = "namespace"
+	ScopeWorkload  = "workload"
+)
+
+// Default is the key of the budget that applies to namespaces or workloads
+// without their own
+const Default = "*"
+
+// Limit is a pair of thresholds; zero disables a threshold
+type Limit struct {
+	Warn float64 `yaml:"warn,omitempty"`
+	Fail float64 `yaml:"fail,omitempty"`
+}
+
+// Budget limits the monthly cost of a scope and, against a baseline, its increase
+// in percent
+type Budget struct {
+	MaxMonthly  Limit `yaml:"max_monthly,omitempty"`
+	MaxIncrease Limit `yaml:"max_increase,omitempty"`
+}
+
+// Budgets is the budgets file schema. Workloads are keyed namespace/Kind/name;
+// the "*" key of Namespaces or Workloads applies to those not listed.
+type Budgets struct {
+	Total      Budget            `yaml:"total,omitempty"`
+	Namespaces map[string]Budget `yaml:"namespaces,omitempty"`
+	Workloads  map[string]Budget `yaml:"workloads,omitempty"`
+}
+
+// LoadBudgets reads budgets from a YAML file
+func LoadBudgets(path string) (Budgets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Budgets{}, fmt.Errorf("failed to read budgets file: %w", err)
+	}
+	budgets, err := ParseBudgets(data)
+	if err != nil {
+		return Budgets{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return budgets, nil
+}
+
+// ParseBudgets decodes and validates budgets. Unknown fields are rejected so that
+// typos do not silently disable a budget.
+func ParseBudgets(data []byte) (Budgets, error) {
+	var budgets Budgets
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&budgets); err != nil {
+		return Budgets{}, fmt.Errorf("failed to parse budgets YAML: %w", err)
+	}
+
+	if err := budgets.Validate(); err != nil {
+		return Budgets{}, err
+	}
+	return budgets, nil
+}
+
+// Validate rejects negative thresholds and warning thresholds above failure ones
+func (b Budgets) Validate() error {
+	var errs []error
+	check := func(scope string, b Budget) {
+		for rule, l := range map[string]Limit{RuleMaxMonthly: b.MaxMonthly, RuleMaxIncrease: b.MaxIncrease} {
+			if err := l.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", scope, rule, err))
+			}
+		}
+	}
+	check(ScopeTotal, b.Total)
+	for name, budget := range b.Namespaces {
+		check(ScopeNamespace+" "+name, budget)
+	}
+	for name, budget := range b.Workloads {
+		check(ScopeWorkload+" "+name, budget)
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return fmt.Errorf("invalid budgets: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// HasIncrease reports whether any budget limits increases, which need a baseline
+func (b Budgets) HasIncrease() bool {
+	set := func(b Budget) bool { return b.MaxIncrease.Warn > 0 || b.MaxIncrease.Fail > 0 }
+	if set(b.Total) {
+		return true
+	}
+	for _, group := range []map[string]Budget{b.Namespaces, b.Workloads} {
+		for _, budget := range group {
+			if set(budget) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (l Limit) validate() error {
+	switch {
+	case l.Warn < 0 || l.Fail < 0:
+		return errors.New("thresholds must not be negative")
+	case l.Warn > 0 && l.Fail > 0 && l.Warn > l.Fail:
+		return fmt.Errorf("warn threshold %g is above fail threshold %g", l.Warn, l.Fail)
+	}
+	return nil
+}
+
+// Costs are the monthly costs a gate checks, keyed as in Budgets
+type Costs struct {
+	Total      float64
+	Namespaces map[string]float64
+	Workloads  map[string]float64
+}
+
+// WorkloadKey is the key of a workload in Costs and Budgets
+func WorkloadKey(namespace, kind, name string) string {
+	return namespace + "/" + kind + "/" + name
+}
+
+// Violation is a cost that exceeds a budget threshold
+type Violation struct {
+	Level string
+	Scope string
+	Name  string
+	Rule  string
+	// Monthly is the current monthly cost and Baseline the baseline's, if any
+	Monthly  float64
+	Baseline float64
+	// Actual is the value compared with Limit: the monthly cost, or the increase
+	// in percent for max_increase
+	Actual float64
+	Limit  float64
+}
+
+// Evaluate checks costs against budgets, and increases against baseline when it is
+// not nil. Namespaces and workloads missing from the baseline are new and only
+// checked against max_monthly. Violations are ordered failures first, then by
+// scope, name and rule.
+func Evaluate(costs Costs, baseline *Costs, budgets Budgets) []Violation {
+	var violations []Violation
+	check := func(scope, name string, monthly float64, base *float64, b Budget) {
+		if v, ok := b.MaxMonthly.check(monthly); ok {
+			violations = append(violations, Violation{
+				Level: v.level, Scope: scope, Name: name, Rule: RuleMaxMonthly,
+				Monthly: monthly, Actual: monthly, Limit: v.limit,
+			})
+		}
+		if base == nil {
+			return
+		}
+		increase := percentIncrease(*base, monthly)
+		if v, ok := b.MaxIncrease.check(increase); ok {
+			violations = append(violations, Violation{
+				Level: v.level, Scope: scope, Name: name, Rule: RuleMaxIncrease,
+				Monthly: monthly, Baseline: *base, Actual: increase, Limit: v.limit,
+			})
+		}
+	}
+
+	var baseTotal *float64
+	if baseline != nil {
+		baseTotal = &baseline.Total
+	}
+	check(ScopeTotal, "", costs.Total, baseTotal, budgets.Total)
+
+	evaluateScope(ScopeNamespace, costs.Namespaces, baselineOf(baseline, func(c *Costs) map[string]float64 { return c.Namespaces }), budgets.Namespaces, check)
+	evaluateScope(ScopeWorkload, costs.Workloads, baselineOf(baseline, func(c *Costs) map[string]float64 { return c.Workloads }), budgets.Workloads, check)
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Level != b.Level {
+			return a.Level == LevelFail
+		}
+		if a.Scope != b.Scope {
+			return scopeOrder(a.Scope) < scopeOrder(b.Scope)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Rule < b.Rule
+	})
+	return violations
+}
+
+func evaluateScope(scope string, costs, baseline map[string]float64, budgets map[string]Budget, check func(scope, name string, monthly float64, base *float64, b Budget)) {
+	if len(budgets) == 0 {
+		return
+	}
+	for name, monthly := range costs {
+		b, ok := budgets[name]
+		if !ok {
+			if b, ok = budgets[Default]; !ok {
+				continue
+			}
+		}
+		var base *float64
+		if v, ok := baseline[name]; ok {
+			base = &v
+		}
+		check(scope, name, monthly, base, b)
+	}
+}
+
+func baselineOf(baseline *Costs, field func(*Costs) map[string]float64) map[string]float64 {
+	if baseline == nil {
+		return nil
+	}
+	return field(baseline)
+}
+
+func scopeOrder(scope string) int {
+	switch scope {
+	case ScopeTotal:
+		return 0
+	case ScopeNamespace:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type breach struct {
+	level string
+	limit float64
+}
+
+// check returns the most severe threshold value exceeds
+func (l Limit) check(value float64) (breach, bool) {
+	switch {
+	case l.Fail > 0 && value > l.Fail:
+		return breach{LevelFail, l.Fail}, true
+	case l.Warn > 0 && value > l.Warn:
+		return breach{LevelWarn, l.Warn}, true
+	}
+	return breach{}, false
+}
+
+// percentIncrease returns the increase from base to current in percent; any
+// increase from zero is infinite
+func percentIncrease(base, current float64) float64 {
+	if base <= 0 {
+		if current > 0 {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	return (current - base) / base * 100
+}
+
+// Worst returns the most severe level among violations, or "" when there are none
+func Worst(violations []Violation) string {
+	worst := ""
+	for _, v := range violations {
+		if v.Level == LevelFail {
+			return LevelFail
+		}
+		worst = LevelWarn
+	}
+	return worst
+}
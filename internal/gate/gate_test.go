@@ -0,0 +1,163 @@
+package gate
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseBudgets(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "total", yaml: "total:\n  max_monthly: {warn: 800, fail: 1000}\n"},
+		{name: "namespaces and workloads", yaml: "namespaces:\n  shop: {max_increase: {fail: 10}}\n  \"*\": {max_monthly: {fail: 200}}\nworkloads:\n  shop/Deployment/web: {max_monthly: {warn: 50}}\n"},
+		{name: "negative", yaml: "total:\n  max_monthly: {fail: -1}\n", wantErr: "must not be negative"},
+		{name: "warn above fail", yaml: "namespaces:\n  shop: {max_monthly: {warn: 300, fail: 200}}\n", wantErr: "namespace shop max_monthly: warn threshold 300 is above fail threshold 200"},
+		{name: "unknown field", yaml: "total:\n  max_montly: {fail: 1}\n", wantErr: "field max_montly not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseBudgets([]byte(tt.yaml))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+	costs := Costs{
+		Total:      600,
+		Namespaces: map[string]float64{"shop": 400, "batch": 150, "new": 50},
+		Workloads: map[string]float64{
+			WorkloadKey("shop", "Deployment", "web"): 300,
+			WorkloadKey("shop", "Deployment", "api"): 100,
+		},
+	}
+	baseline := &Costs{
+		Total:      500,
+		Namespaces: map[string]float64{"shop": 300, "batch": 150},
+		Workloads:  map[string]float64{WorkloadKey("shop", "Deployment", "web"): 300},
+	}
+
+	tests := []struct {
+		name     string
+		budgets  Budgets
+		baseline *Costs
+		want     []string
+	}{
+		{
+			name:    "within budget",
+			budgets: Budgets{Total: Budget{MaxMonthly: Limit{Warn: 700, Fail: 1000}}},
+			want:    nil,
+		},
+		{
+			name:    "total warning",
+			budgets: Budgets{Total: Budget{MaxMonthly: Limit{Warn: 500, Fail: 1000}}},
+			want:    []string{"warn total  max_monthly"},
+		},
+		{
+			name:     "increase without baseline is skipped",
+			budgets:  Budgets{Total: Budget{MaxIncrease: Limit{Fail: 10}}},
+			baseline: nil,
+			want:     nil,
+		},
+		{
+			name:     "total increase",
+			budgets:  Budgets{Total: Budget{MaxIncrease: Limit{Warn: 10, Fail: 25}}},
+			baseline: baseline,
+			want:     []string{"warn total  max_increase"},
+		},
+		{
+			name: "default namespace budget, failures first",
+			budgets: Budgets{Namespaces: map[string]Budget{
+				"shop": {MaxMonthly: Limit{Warn: 350}},
+				"*":    {MaxMonthly: Limit{Fail: 100}},
+			}},
+			want: []string{"fail namespace batch max_monthly", "warn namespace shop max_monthly"},
+		},
+		{
+			name: "new namespace only checked against max_monthly",
+			budgets: Budgets{Namespaces: map[string]Budget{
+				"*": {MaxIncrease: Limit{Fail: 20}},
+			}},
+			baseline: baseline,
+			want:     []string{"fail namespace shop max_increase"},
+		},
+		{
+			name: "workloads",
+			budgets: Budgets{Workloads: map[string]Budget{
+				"*": {MaxMonthly: Limit{Fail: 250}, MaxIncrease: Limit{Fail: 0.5}},
+			}},
+			baseline: baseline,
+			want:     []string{"fail workload shop/Deployment/web max_monthly"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			violations := Evaluate(costs, tt.baseline, tt.budgets)
+			var got []string
+			for _, v := range violations {
+				got = append(got, v.Level+" "+v.Scope+" "+v.Name+" "+v.Rule)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("violations: got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateIncrease(t *testing.T) {
+	t.Parallel()
+	budgets := Budgets{Total: Budget{MaxIncrease: Limit{Fail: 10}}}
+	violations := Evaluate(Costs{Total: 600}, &Costs{Total: 500}, budgets)
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	v := violations[0]
+	if v.Actual != 20 || v.Limit != 10 || v.Baseline != 500 || v.Monthly != 600 {
+		t.Errorf("got %+v, want a 20%% increase from 500 to 600 over a 10%% limit", v)
+	}
+
+	violations = Evaluate(Costs{Total: 100}, &Costs{Total: 0}, budgets)
+	if len(violations) != 1 || !math.IsInf(violations[0].Actual, 1) {
+		t.Errorf("increase from zero: got %+v, want an infinite increase", violations)
+	}
+}
+
+func TestWorst(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		violations []Violation
+		want       string
+	}{
+		{name: "none", want: ""},
+		{name: "warnings", violations: []Violation{{Level: LevelWarn}, {Level: LevelWarn}}, want: LevelWarn},
+		{name: "failure", violations: []Violation{{Level: LevelWarn}, {Level: LevelFail}}, want: LevelFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Worst(tt.violations); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Kinds a Cache reports events for besides the workload kinds
+const (
+	KindNode                  = "Node"
+	KindPersistentVolumeClaim = "PersistentVolumeClaim"
+)
+
+// EventType is the kind of change an Event reports
+type EventType string
+
+const (
+	EventAdded   EventType = "added"
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted"
+)
+
+// Event reports a change to an object in a Cache
+type Event struct {
+	Type      EventType
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// eventBuffer is how many events a Cache holds for a slow consumer before
+// dropping new ones
+const eventBuffer = 1024
+
+// Cache keeps the pods, nodes, workload controllers and claims of a set of
+// namespaces in memory with shared informers. After the initial lists it follows
+// changes through watches, so long-running commands read current state without
+// listing the cluster again on every refresh.
+type Cache struct {
+	// namespaces holds an informer factory per namespace, or one for all namespaces
+	namespaces []informers.SharedInformerFactory
+	nodes      informers.SharedInformerFactory
+	synced     []cache.InformerSynced
+	events     chan Event
+	// watchErrors counts the lists and watches that failed
+	watchErrors atomic.Int64
+}
+
+// NewCache sets up informers for the given namespaces (all namespaces when empty).
+// Nothing is listed until Start. A non-zero resync periodically replays every
+// object to the informers; unchanged objects do not produce events.
+func NewCache(client kubernetes.Interface, namespaces []string, resync time.Duration) (*Cache, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	c := &Cache{
+		nodes:  informers.NewSharedInformerFactory(client, resync),
+		events: make(chan Event, eventBuffer),
+	}
+	if err := c.watch(KindNode, c.nodes.Core().V1().Nodes().Informer()); err != nil {
+		return nil, err
+	}
+	for _, ns := range namespaces {
+		f := informers.NewSharedInformerFactoryWithOptions(client, resync, informers.WithNamespace(ns))
+		for kind, informer := range map[string]cache.SharedIndexInformer{
+			KindPod:                   f.Core().V1().Pods().Informer(),
+			KindPersistentVolumeClaim: f.Core().V1().PersistentVolumeClaims().Informer(),
+			KindDeployment:            f.Apps().V1().Deployments().Informer(),
+			KindStatefulSet:           f.Apps().V1().StatefulSets().Informer(),
+			KindDaemonSet:             f.Apps().V1().DaemonSets().Informer(),
+			KindReplicaSet:            f.Apps().V1().ReplicaSets().Informer(),
+			KindJob:                   f.Batch().V1().Jobs().Informer(),
+			KindCronJob:               f.Batch().V1().CronJobs().Informer(),
+		} {
+			if err := c.watch(kind, informer); err != nil {
+				return nil, err
+			}
+		}
+		c.namespaces = append(c.namespaces, f)
+	}
+	return c, nil
+}
+
+// Start runs the informers until ctx is done and waits for their initial lists.
+// It fails if they have not completed within timeout, e.g. because the user may
+// not list or watch one of the resources.
+func (c *Cache) Start(ctx context.Context, timeout time.Duration) error {
+	c.nodes.Start(ctx.Done())
+	for _, f := range c.namespaces {
+		f.Start(ctx.Done())
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), c.synced...) {
+		return fmt.Errorf("failed to sync cache within %s; check that pods, nodes, workloads and persistent volume claims can be listed and watched", timeout)
+	}
+	return nil
+}
+
+// Events delivers changes to cached objects, including the adds of the initial
+// lists. When the consumer falls behind, new events are dropped rather than
+// blocking the informers; a pending event already signals that state changed.
+func (c *Cache) Events() <-chan Event {
+	return c.events
+}
+
+// WatchErrors returns how many lists and watches have failed since the cache was
+// created. While they fail, the informers retry with backoff and the cached
+// objects go stale, so a rising count means reads may not reflect the cluster.
+func (c *Cache) WatchErrors() int64 {
+	return c.watchErrors.Load()
+}
+
+// watch registers an informer and forwards its changes as events
+func (c *Cache) watch(kind string, informer cache.SharedIndexInformer) error {
+	c.synced = append(c.synced, informer.HasSynced)
+	err := informer.SetWatchErrorHandlerWithContext(func(ctx context.Context, r *cache.Reflector, err error) {
+		c.watchFailed(err)
+		cache.DefaultWatchErrorHandler(ctx, r, err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", kind, err)
+	}
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) { c.notify(EventAdded, kind, obj) },
+		UpdateFunc: func(old, obj any) {
+			// Resyncs replay objects that have not changed
+			if resourceVersion(old) != resourceVersion(obj) {
+				c.notify(EventUpdated, kind, obj)
+			}
+		},
+		DeleteFunc: func(obj any) { c.notify(EventDeleted, kind, obj) },
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", kind, err)
+	}
+	return nil
+}
+
+// watchFailed counts a failed list or watch. Watches the server closes normally or
+// that expired and are resumed with a fresh list are not failures.
+func (c *Cache) watchFailed(err error) {
+	if errors.Is(err, io.EOF) || apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+		return
+	}
+	c.watchErrors.Add(1)
+}
+
+func (c *Cache) notify(eventType EventType, kind string, obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	select {
+	case c.events <- Event{Type: eventType, Kind: kind, Namespace: m.GetNamespace(), Name: m.GetName()}:
+	default:
+	}
+}
+
+func resourceVersion(obj any) string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return m.GetResourceVersion()
+}
+
+// Pods returns the cached pods sorted by namespace and name. They share maps and
+// slices with the cache and must not be modified.
+func (c *Cache) Pods() []corev1.Pod {
+	var pods []*corev1.Pod
+	for _, f := range c.namespaces {
+		list, _ := f.Core().V1().Pods().Lister().List(labels.Everything())
+		pods = append(pods, list...)
+	}
+	return derefSorted(pods, func(p *corev1.Pod) metav1.Object { return p })
+}
+
+// Nodes returns the cached nodes sorted by name
+func (c *Cache) Nodes() []corev1.Node {
+	nodes, _ := c.nodes.Core().V1().Nodes().Lister().List(labels.Everything())
+	return derefSorted(nodes, func(n *corev1.Node) metav1.Object { return n })
+}
+
+// Workloads returns the cached workload controllers with their desired replicas,
+// following the same rules as FetchWorkloads
+func (c *Cache) Workloads() []Workload {
+	nodes := c.Nodes()
+	var workloads []Workload
+	for _, f := range c.namespaces {
+		workloads = append(workloads, cachedWorkloadObjects(f).workloads(nodes)...)
+	}
+	return workloads
+}
+
+// OwnerIndex indexes the cached ReplicaSets and Jobs as FetchOwnerIndex does
+func (c *Cache) OwnerIndex() OwnerIndex {
+	index := make(OwnerIndex)
+	for _, f := range c.namespaces {
+		objects := cachedWorkloadObjects(f)
+		for _, rs := range objects.replicaSets {
+			index.add(KindReplicaSet, rs.ObjectMeta)
+		}
+		for _, j := range objects.jobs {
+			index.add(KindJob, j.ObjectMeta)
+		}
+	}
+	return index
+}
+
+// Claims returns the cached bound claims. Volumes are not cached, so capacity and
+// StorageClass come from each claim's status and spec.
+func (c *Cache) Claims() []Claim {
+	var pvcs []*corev1.PersistentVolumeClaim
+	for _, f := range c.namespaces {
+		list, _ := f.Core().V1().PersistentVolumeClaims().Lister().List(labels.Everything())
+		pvcs = append(pvcs, list...)
+	}
+	return ResolveClaims(derefSorted(pvcs, func(pvc *corev1.PersistentVolumeClaim) metav1.Object { return pvc }), nil)
+}
+
+// cachedWorkloadObjects copies the workload controllers cached by a factory
+func cachedWorkloadObjects(f informers.SharedInformerFactory) workloadObjects {
+	apps, batch := f.Apps().V1(), f.Batch().V1()
+	var o workloadObjects
+	deployments, _ := apps.Deployments().Lister().List(labels.Everything())
+	o.deployments = derefSorted(deployments, func(d *appsv1.Deployment) metav1.Object { return d })
+	statefulSets, _ := apps.StatefulSets().Lister().List(labels.Everything())
+	o.statefulSets = derefSorted(statefulSets, func(s *appsv1.StatefulSet) metav1.Object { return s })
+	daemonSets, _ := apps.DaemonSets().Lister().List(labels.Everything())
+	o.daemonSets = derefSorted(daemonSets, func(ds *appsv1.DaemonSet) metav1.Object { return ds })
+	replicaSets, _ := apps.ReplicaSets().Lister().List(labels.Everything())
+	o.replicaSets = derefSorted(replicaSets, func(rs *appsv1.ReplicaSet) metav1.Object { return rs })
+	jobs, _ := batch.Jobs().Lister().List(labels.Everything())
+	o.jobs = derefSorted(jobs, func(j *batchv1.Job) metav1.Object { return j })
+	cronJobs, _ := batch.CronJobs().Lister().List(labels.Everything())
+	o.cronJobs = derefSorted(cronJobs, func(cj *batchv1.CronJob) metav1.Object { return cj })
+	return o
+}
+
+// derefSorted copies cached objects out of their pointers, sorted by namespace and
+// name for stable output
+func derefSorted[T any](list []*T, object func(*T) metav1.Object) []T {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := object(list[i]), object(list[j])
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+	out := make([]T, len(list))
+	for i, v := range list {
+		out[i] = *v
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// newTestCache returns a cache for one namespace whose informers are never
+// started; tests fill their stores directly
+func newTestCache(t *testing.T) *Cache {
+	t.Helper()
+	c, err := NewCache(nil, []string{"shop"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+func addToStore(t *testing.T, informer cache.SharedIndexInformer, objects ...any) {
+	t.Helper()
+	for _, obj := range objects {
+		if err := informer.GetIndexer().Add(obj); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestCacheLists(t *testing.T) {
+	t.Parallel()
+	c := newTestCache(t)
+	f := c.namespaces[0]
+	addToStore(t, f.Core().V1().Pods().Informer(),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}},
+	)
+	addToStore(t, c.nodes.Core().V1().Nodes().Informer(),
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+	)
+	addToStore(t, f.Apps().V1().Deployments().Informer(),
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"}, Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)}},
+	)
+	addToStore(t, f.Apps().V1().ReplicaSets().Informer(),
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f8b", Namespace: "shop", OwnerReferences: controlledBy(KindDeployment, "web")}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "shop"}, Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(1)}},
+	)
+
+	pods := c.Pods()
+	if len(pods) != 2 || pods[0].Name != "web-1" || pods[1].Name != "web-2" {
+		t.Errorf("got pods %v, want web-1 and web-2 in order", pods)
+	}
+	nodes := c.Nodes()
+	if len(nodes) != 2 || nodes[0].Name != "node-a" {
+		t.Errorf("got nodes %v, want node-a and node-b in order", nodes)
+	}
+
+	var got []string
+	for _, w := range c.Workloads() {
+		got = append(got, w.Kind+"/"+w.Name)
+	}
+	if want := []string{"Deployment/web", "ReplicaSet/legacy"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	owner := c.OwnerIndex().Owner(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f8b-x", Namespace: "shop", OwnerReferences: controlledBy(KindReplicaSet, "web-7d9f8b")}})
+	if want := (Owner{KindDeployment, "web"}); owner != want {
+		t.Errorf("got %s/%s, want %s/%s", owner.Kind, owner.Name, want.Kind, want.Name)
+	}
+}
+
+func TestCacheNotify(t *testing.T) {
+	t.Parallel()
+	c := &Cache{events: make(chan Event, 1)}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "shop"}}
+
+	c.notify(EventDeleted, KindPod, cache.DeletedFinalStateUnknown{Key: "shop/web-1", Obj: pod})
+	// The buffer is full, so this event is dropped rather than blocking
+	c.notify(EventAdded, KindPod, pod)
+
+	if got, want := <-c.Events(), (Event{Type: EventDeleted, Kind: KindPod, Namespace: "shop", Name: "web-1"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	select {
+	case e := <-c.Events():
+		t.Errorf("got unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestCacheWatchErrors(t *testing.T) {
+	t.Parallel()
+	c := newTestCache(t)
+	c.watchFailed(io.EOF)
+	c.watchFailed(apierrors.NewResourceExpired("too old resource version"))
+	c.watchFailed(apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("denied")))
+	c.watchFailed(io.ErrUnexpectedEOF)
+
+	if got := c.WatchErrors(); got != 2 {
+		t.Errorf("got %d, want 2", got)
+	}
+}
@@ -2,22 +2,35 @@ package k8s
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"strconv"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-// NewClient tries in-cluster config first, then falls back to kubeconfig
-func NewClient() (*kubernetes.Clientset, error) {
-	config, err := rest.InClusterConfig()
+// ClientOptions selects which kubeconfig, context and credentials to connect with.
+// Zero values fall back to the kubeconfig loading rules and the current context.
+type ClientOptions struct {
+	Kubeconfig     string
+	Context        string
+	Cluster        string
+	User           string
+	RequestTimeout string
+}
+
+// explicit reports whether any kubeconfig selection was requested
+func (o ClientOptions) explicit() bool {
+	return o.Kubeconfig != "" || o.Context != "" || o.Cluster != "" || o.User != ""
+}
+
+// NewClient tries in-cluster config first, then falls back to kubeconfig.
+// In-cluster config is skipped when the options select a kubeconfig, context, cluster or user.
+func NewClient(opts ClientOptions) (*kubernetes.Clientset, error) {
+	config, err := BuildConfig(opts)
 	if err != nil {
-		config, err = buildConfigFromKubeconfig()
-		if err != nil {
-			return nil, fmt.Errorf("failed to build config: %w", err)
-		}
+		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -28,20 +41,54 @@ func NewClient() (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
-func buildConfigFromKubeconfig() (*rest.Config, error) {
-	kubeconfigPath := os.Getenv("KUBECONFIG")
-	if kubeconfigPath == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+// BuildConfig resolves a REST config from the in-cluster environment or kubeconfig
+func BuildConfig(opts ClientOptions) (*rest.Config, error) {
+	if !opts.explicit() {
+		if config, err := rest.InClusterConfig(); err == nil {
+			if err := applyTimeout(config, opts.RequestTimeout); err != nil {
+				return nil, err
+			}
+			return config, nil
 		}
-		kubeconfigPath = filepath.Join(home, ".kube", "config")
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	return buildConfigFromKubeconfig(opts)
+}
+
+func buildConfigFromKubeconfig(opts ClientOptions) (*rest.Config, error) {
+	// Default rules honour $KUBECONFIG (including path lists) and ~/.kube/config
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.Kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: opts.Context,
+		Timeout:        opts.RequestTimeout,
+	}
+	overrides.Context.Cluster = opts.Cluster
+	overrides.Context.AuthInfo = opts.User
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	config, err := clientConfig.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config from kubeconfig: %w", err)
 	}
 
 	return config, nil
 }
+
+func applyTimeout(config *rest.Config, timeout string) error {
+	if timeout == "" {
+		return nil
+	}
+	// Match clientcmd: bare integers are seconds
+	if secs, err := strconv.Atoi(timeout); err == nil {
+		config.Timeout = time.Duration(secs) * time.Second
+		return nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid request timeout %q: %w", timeout, err)
+	}
+	config.Timeout = d
+	return nil
+}
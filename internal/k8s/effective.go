@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Rules reported by EffectiveResources for the value of each resource
+const (
+	// RuleContainers: the sum of the app containers
+	RuleContainers = "containers"
+	// RuleSidecars: the sum of the app containers and restartable (sidecar) init containers
+	RuleSidecars = "containers+sidecars"
+	// RuleInitPrefix, followed by a container name: that init container, plus the
+	// sidecars started before it, needs more than the running pod
+	RuleInitPrefix = "init:"
+	// RuleOverheadSuffix is appended when the RuntimeClass adds pod overhead
+	RuleOverheadSuffix = "+overhead"
+	// RuleOverhead: only the pod overhead requests the resource
+	RuleOverhead = "overhead"
+)
+
+// EffectiveResources computes what the scheduler reserves for a pod, per resource,
+// from the per-container values returned by perContainer:
+//
+//	max(sum(app containers) + sum(sidecars), max over init containers of
+//	    (init container + sidecars started before it)) + pod overhead
+//
+// Sidecars are init containers with restartPolicy Always; they keep running
+// alongside the app containers, while regular init containers run one at a time
+// before them. The second result names the rule that set each value.
+func EffectiveResources(spec corev1.PodSpec, perContainer func(corev1.Container) corev1.ResourceList) (corev1.ResourceList, map[corev1.ResourceName]string) {
+	total := make(corev1.ResourceList)
+	rules := make(map[corev1.ResourceName]string)
+
+	for _, c := range spec.Containers {
+		addResources(total, perContainer(c))
+	}
+	for name := range total {
+		rules[name] = RuleContainers
+	}
+
+	sidecars := make(corev1.ResourceList)
+	initPeak := make(corev1.ResourceList)
+	initRules := make(map[corev1.ResourceName]string)
+	for _, c := range spec.InitContainers {
+		// perContainer may return a container's own, possibly nil, list
+		running := make(corev1.ResourceList)
+		if isSidecar(c) {
+			addResources(sidecars, perContainer(c))
+		} else {
+			addResources(running, perContainer(c))
+		}
+		addResources(running, sidecars)
+
+		for name, qty := range running {
+			if peak, ok := initPeak[name]; !ok || qty.Cmp(peak) > 0 {
+				initPeak[name] = qty
+				initRules[name] = RuleInitPrefix + c.Name
+			}
+		}
+	}
+
+	for name, qty := range sidecars {
+		if qty.IsZero() {
+			continue
+		}
+		sum := total[name]
+		sum.Add(qty)
+		total[name] = sum
+		rules[name] = RuleSidecars
+	}
+	for name, peak := range initPeak {
+		if peak.Cmp(total[name]) > 0 {
+			total[name] = peak
+			rules[name] = initRules[name]
+		}
+	}
+
+	for name, qty := range spec.Overhead {
+		if qty.IsZero() {
+			continue
+		}
+		sum := total[name]
+		sum.Add(qty)
+		total[name] = sum
+		if rules[name] == "" {
+			rules[name] = RuleOverhead
+		} else {
+			rules[name] += RuleOverheadSuffix
+		}
+	}
+
+	return total, rules
+}
+
+// isSidecar reports whether an init container keeps running alongside the app containers
+func isSidecar(c corev1.Container) bool {
+	return c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+func addResources(into, from corev1.ResourceList) {
+	for name, qty := range from {
+		sum := into[name]
+		sum.Add(qty)
+		into[name] = sum
+	}
+}
@@ -0,0 +1,171 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func container(name, cpu, memory string) corev1.Container {
+	requests := corev1.ResourceList{}
+	if cpu != "" {
+		requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		requests[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Requests: requests}}
+}
+
+func sidecar(name, cpu, memory string) corev1.Container {
+	c := container(name, cpu, memory)
+	always := corev1.ContainerRestartPolicyAlways
+	c.RestartPolicy = &always
+	return c
+}
+
+func TestEffectiveResources(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		spec           corev1.PodSpec
+		wantCPU        string
+		wantMemory     string
+		wantCPURule    string
+		wantMemoryRule string
+	}{
+		{
+			name:           "app containers are summed",
+			spec:           corev1.PodSpec{Containers: []corev1.Container{container("app", "250m", "256Mi"), container("proxy", "50m", "64Mi")}},
+			wantCPU:        "300m",
+			wantMemory:     "320Mi",
+			wantCPURule:    RuleContainers,
+			wantMemoryRule: RuleContainers,
+		},
+		{
+			name: "large init container wins per resource",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("migrate", "2", "128Mi")},
+				Containers:     []corev1.Container{container("app", "500m", "512Mi")},
+			},
+			wantCPU:        "2",
+			wantMemory:     "512Mi",
+			wantCPURule:    "init:migrate",
+			wantMemoryRule: RuleContainers,
+		},
+		{
+			name: "sidecars add to app containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{sidecar("istio-proxy", "100m", "128Mi")},
+				Containers:     []corev1.Container{container("app", "500m", "512Mi")},
+			},
+			wantCPU:        "600m",
+			wantMemory:     "640Mi",
+			wantCPURule:    RuleSidecars,
+			wantMemoryRule: RuleSidecars,
+		},
+		{
+			name: "init container runs alongside earlier sidecars only",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					sidecar("log-shipper", "200m", "64Mi"),
+					container("warmup", "1", "64Mi"),
+					sidecar("proxy", "2", "64Mi"),
+				},
+				Containers: []corev1.Container{container("app", "100m", "64Mi")},
+			},
+			// app+sidecars = 100m+200m+2 = 2300m beats warmup+log-shipper = 1200m
+			wantCPU:        "2300m",
+			wantMemory:     "192Mi",
+			wantCPURule:    RuleSidecars,
+			wantMemoryRule: RuleSidecars,
+		},
+		{
+			name: "pod overhead is added",
+			spec: corev1.PodSpec{
+				Containers: []corev1.Container{container("app", "500m", "")},
+				Overhead: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("250m"),
+					corev1.ResourceMemory: resource.MustParse("120Mi"),
+				},
+			},
+			wantCPU:        "750m",
+			wantMemory:     "120Mi",
+			wantCPURule:    RuleContainers + RuleOverheadSuffix,
+			wantMemoryRule: RuleOverhead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, rules := EffectiveResources(tt.spec, containerRequests)
+
+			cpu := got[corev1.ResourceCPU]
+			if want := resource.MustParse(tt.wantCPU); cpu.Cmp(want) != 0 {
+				t.Errorf("CPU: got %s, want %s", cpu.String(), tt.wantCPU)
+			}
+			memory := got[corev1.ResourceMemory]
+			if want := resource.MustParse(tt.wantMemory); memory.Cmp(want) != 0 {
+				t.Errorf("memory: got %s, want %s", memory.String(), tt.wantMemory)
+			}
+			if rules[corev1.ResourceCPU] != tt.wantCPURule {
+				t.Errorf("CPU rule: got %q, want %q", rules[corev1.ResourceCPU], tt.wantCPURule)
+			}
+			if rules[corev1.ResourceMemory] != tt.wantMemoryRule {
+				t.Errorf("memory rule: got %q, want %q", rules[corev1.ResourceMemory], tt.wantMemoryRule)
+			}
+		})
+	}
+}
+
+func TestEffectiveResourcesLimits(t *testing.T) {
+	t.Parallel()
+	proxy := sidecar("proxy", "", "")
+	proxy.Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("200m"),
+		corev1.ResourceMemory: resource.MustParse("128Mi"),
+	}
+	app := container("app", "", "")
+	app.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+	spec := corev1.PodSpec{
+		// A sidecar with limits followed by an init container without any
+		InitContainers: []corev1.Container{proxy, container("migrate", "100m", "")},
+		Containers:     []corev1.Container{app},
+	}
+
+	got, _ := EffectiveResources(spec, containerLimits)
+
+	cpu := got[corev1.ResourceCPU]
+	if want := resource.MustParse("1200m"); cpu.Cmp(want) != 0 {
+		t.Errorf("CPU: got %s, want 1200m", cpu.String())
+	}
+	memory := got[corev1.ResourceMemory]
+	if want := resource.MustParse("128Mi"); memory.Cmp(want) != 0 {
+		t.Errorf("memory: got %s, want 128Mi", memory.String())
+	}
+	if proxy.Resources.Limits.Cpu().String() != "200m" {
+		t.Errorf("sidecar limits modified: got %s, want 200m", proxy.Resources.Limits.Cpu().String())
+	}
+}
+
+func TestPodResourcesRequestRule(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		cpuRule, memoryRule string
+		want                string
+	}{
+		{cpuRule: RuleContainers, memoryRule: RuleContainers, want: RuleContainers},
+		{cpuRule: "init:migrate", memoryRule: RuleContainers, want: "cpu=init:migrate, memory=containers"},
+		{cpuRule: RuleSidecars, want: "cpu=containers+sidecars"},
+		{want: ""},
+	}
+
+	for _, tt := range tests {
+		got := PodResources{CPURule: tt.cpuRule, MemoryRule: tt.memoryRule}.RequestRule()
+		if got != tt.want {
+			t.Errorf("RequestRule(%q, %q): got %q, want %q", tt.cpuRule, tt.memoryRule, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// metricsAPIPath is the metrics.k8s.io API served by metrics-server
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// ContainerUsage is a container's measured CPU and memory
+type ContainerUsage struct {
+	Name   string
+	CPU    resource.Quantity
+	Memory resource.Quantity
+}
+
+// PodUsage is a pod's measured usage, as reported by metrics-server
+type PodUsage struct {
+	Name       string
+	Namespace  string
+	Timestamp  time.Time
+	Window     time.Duration
+	Containers []ContainerUsage
+}
+
+// Total sums the CPU and memory usage of the pod's containers
+func (u PodUsage) Total() (cpu, memory resource.Quantity) {
+	for _, c := range u.Containers {
+		cpu.Add(c.CPU)
+		memory.Add(c.Memory)
+	}
+	return cpu, memory
+}
+
+// podMetricsList mirrors the parts of metrics.k8s.io/v1beta1 PodMetricsList kcost reads
+type podMetricsList struct {
+	Items []struct {
+		Metadata   metav1.ObjectMeta `json:"metadata"`
+		Timestamp  metav1.Time       `json:"timestamp"`
+		Window     metav1.Duration   `json:"window"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// FetchPodUsage queries metrics-server for the usage of pods in the given namespaces
+// (all namespaces when empty), keyed by namespace/name. Pods that metrics-server has
+// not sampled yet are missing from the result.
+func FetchPodUsage(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (map[string]PodUsage, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	usage := make(map[string]PodUsage)
+	for _, ns := range namespaces {
+		path := metricsAPIPath + "/pods"
+		if ns != metav1.NamespaceAll {
+			path = metricsAPIPath + "/namespaces/" + ns + "/pods"
+		}
+
+		data, err := client.Discovery().RESTClient().Get().AbsPath(path).Do(ctx).Raw()
+		if err != nil {
+			return nil, fmt.Errorf("failed to query pod metrics (is metrics-server installed?): %w", err)
+		}
+
+		pods, err := ParsePodMetrics(data)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pods {
+			usage[p.Namespace+"/"+p.Name] = p
+		}
+	}
+	return usage, nil
+}
+
+// ParsePodMetrics decodes a metrics.k8s.io PodMetricsList
+func ParsePodMetrics(data []byte) ([]PodUsage, error) {
+	var list podMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode pod metrics: %w", err)
+	}
+
+	pods := make([]PodUsage, len(list.Items))
+	for i, item := range list.Items {
+		pod := PodUsage{
+			Name:      item.Metadata.Name,
+			Namespace: item.Metadata.Namespace,
+			Timestamp: item.Timestamp.Time,
+			Window:    item.Window.Duration,
+		}
+		for _, c := range item.Containers {
+			pod.Containers = append(pod.Containers, ContainerUsage{
+				Name:   c.Name,
+				CPU:    c.Usage[corev1.ResourceCPU],
+				Memory: c.Usage[corev1.ResourceMemory],
+			})
+		}
+		pods[i] = pod
+	}
+	return pods, nil
+}
@@ -0,0 +1,65 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePodMetrics(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{
+  "kind": "PodMetricsList",
+  "apiVersion": "metrics.k8s.io/v1beta1",
+  "items": [
+    {
+      "metadata": {"name": "web-7d9f", "namespace": "shop"},
+      "timestamp": "2025-01-10T12:00:00Z",
+      "window": "15.123s",
+      "containers": [
+        {"name": "app", "usage": {"cpu": "153487217n", "memory": "201764Ki"}},
+        {"name": "istio-proxy", "usage": {"cpu": "4m", "memory": "48Mi"}}
+      ]
+    },
+    {
+      "metadata": {"name": "idle", "namespace": "shop"},
+      "timestamp": "2025-01-10T12:00:00Z",
+      "window": "30s",
+      "containers": []
+    }
+  ]
+}`)
+
+	pods, err := ParsePodMetrics(data)
+	if err != nil {
+		t.Fatalf("ParsePodMetrics failed: %v", err)
+	}
+	if len(pods) != 2 {
+		t.Fatalf("pods: got %d, want 2", len(pods))
+	}
+
+	tests := []struct {
+		name       string
+		wantCPU    int64 // millicores
+		wantMemory int64 // bytes
+		wantWindow time.Duration
+	}{
+		{name: "web-7d9f", wantCPU: 158, wantMemory: 201764*1024 + 48*1024*1024, wantWindow: 15123 * time.Millisecond},
+		{name: "idle", wantWindow: 30 * time.Second},
+	}
+	for i, tt := range tests {
+		pod := pods[i]
+		if pod.Name != tt.name || pod.Namespace != "shop" {
+			t.Errorf("pod %d: got %s/%s, want shop/%s", i, pod.Namespace, pod.Name, tt.name)
+		}
+		cpu, memory := pod.Total()
+		if got := cpu.MilliValue(); got != tt.wantCPU {
+			t.Errorf("%s CPU: got %dm, want %dm", tt.name, got, tt.wantCPU)
+		}
+		if got := memory.Value(); got != tt.wantMemory {
+			t.Errorf("%s memory: got %d, want %d", tt.name, got, tt.wantMemory)
+		}
+		if pod.Window != tt.wantWindow {
+			t.Errorf("%s window: got %s, want %s", tt.name, pod.Window, tt.wantWindow)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FetchNodes retrieves all nodes in the cluster
+func FetchNodes(ctx context.Context, client *kubernetes.Clientset) ([]corev1.Node, error) {
+	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	return nodes.Items, nil
+}
+
+// Well-known node labels describing the underlying machine
+const (
+	LabelInstanceType     = "node.kubernetes.io/instance-type"
+	LabelInstanceTypeBeta = "beta.kubernetes.io/instance-type"
+	LabelRegion           = "topology.kubernetes.io/region"
+	LabelRegionBeta       = "failure-domain.beta.kubernetes.io/region"
+)
+
+// providerIDSchemes maps spec.providerID schemes to short provider names
+var providerIDSchemes = map[string]string{
+	"aws":   "aws",
+	"gce":   "gcp",
+	"azure": "azure",
+}
+
+// NodeInstance reads the cloud provider, region and instance type of a node from its
+// providerID and well-known labels. Missing values are returned empty.
+func NodeInstance(node corev1.Node) (provider, region, instanceType string) {
+	if scheme, _, ok := strings.Cut(node.Spec.ProviderID, "://"); ok {
+		provider = providerIDSchemes[scheme]
+		if provider == "" {
+			provider = scheme
+		}
+	}
+
+	region = firstLabel(node.Labels, LabelRegion, LabelRegionBeta)
+	instanceType = firstLabel(node.Labels, LabelInstanceType, LabelInstanceTypeBeta)
+	return provider, region, instanceType
+}
+
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v := labels[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// NodeAllocation is a node's allocatable CPU and memory and the requests of the pods
+// scheduled on it
+type NodeAllocation struct {
+	Name              string
+	InstanceType      string
+	Pods              int
+	CPUAllocatable    resource.Quantity
+	MemoryAllocatable resource.Quantity
+	CPURequested      resource.Quantity
+	MemoryRequested   resource.Quantity
+}
+
+// NodeAllocations sums the effective requests (see EffectiveResources) of the pods
+// scheduled on each node, in the order of nodes. Finished pods no longer hold their
+// requests and are skipped, as are pods on nodes not in the list.
+func NodeAllocations(nodes []corev1.Node, pods []corev1.Pod) []NodeAllocation {
+	allocs := make([]NodeAllocation, len(nodes))
+	index := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		_, _, instanceType := NodeInstance(node)
+		allocs[i] = NodeAllocation{
+			Name:              node.Name,
+			InstanceType:      instanceType,
+			CPUAllocatable:    node.Status.Allocatable[corev1.ResourceCPU],
+			MemoryAllocatable: node.Status.Allocatable[corev1.ResourceMemory],
+		}
+		index[node.Name] = i
+	}
+
+	for _, pod := range pods {
+		i, ok := index[pod.Spec.NodeName]
+		if !ok || PodFinished(pod) {
+			continue
+		}
+		requests, _ := EffectiveResources(pod.Spec, containerRequests)
+		allocs[i].Pods++
+		allocs[i].CPURequested.Add(requests[corev1.ResourceCPU])
+		allocs[i].MemoryRequested.Add(requests[corev1.ResourceMemory])
+	}
+	return allocs
+}
+
+// PodFinished reports whether a pod has succeeded or failed, releasing its node's resources
+func PodFinished(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeAllocations(t *testing.T) {
+	t.Parallel()
+	node := func(name, cpu, memory string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{LabelInstanceType: "m5.large"}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	pod := func(nodeName, cpu, memory string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	nodes := []corev1.Node{node("a", "1930m", "7Gi"), node("b", "4", "16Gi")}
+	pods := []corev1.Pod{
+		pod("a", "500m", "1Gi", corev1.PodRunning),
+		pod("a", "250m", "512Mi", corev1.PodPending),
+		pod("a", "1", "4Gi", corev1.PodSucceeded),
+		pod("", "1", "1Gi", corev1.PodPending),
+		pod("gone", "1", "1Gi", corev1.PodRunning),
+	}
+
+	got := NodeAllocations(nodes, pods)
+
+	want := []struct {
+		name, cpuAlloc, cpuReq, memReq string
+		pods                           int
+	}{
+		{name: "a", cpuAlloc: "1930m", cpuReq: "750m", memReq: "1536Mi", pods: 2},
+		{name: "b", cpuAlloc: "4", cpuReq: "0", memReq: "0", pods: 0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("node count: got %d, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Name != w.name || g.InstanceType != "m5.large" {
+			t.Errorf("node %d: got %s (%s), want %s (m5.large)", i, g.Name, g.InstanceType, w.name)
+		}
+		if g.Pods != w.pods {
+			t.Errorf("%s pods: got %d, want %d", w.name, g.Pods, w.pods)
+		}
+		if g.CPUAllocatable.Cmp(resource.MustParse(w.cpuAlloc)) != 0 {
+			t.Errorf("%s cpu allocatable: got %q, want %q", w.name, g.CPUAllocatable.String(), w.cpuAlloc)
+		}
+		if g.CPURequested.Cmp(resource.MustParse(w.cpuReq)) != 0 {
+			t.Errorf("%s cpu requested: got %q, want %q", w.name, g.CPURequested.String(), w.cpuReq)
+		}
+		if g.MemoryRequested.Cmp(resource.MustParse(w.memReq)) != 0 {
+			t.Errorf("%s memory requested: got %q, want %q", w.name, g.MemoryRequested.String(), w.memReq)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Owner is the top-level controller a pod belongs to
+type Owner struct {
+	Kind string
+	Name string
+}
+
+// OwnerIndex maps ReplicaSets and Jobs to their controllers, keyed by
+// kind/namespace/name, so pods can be followed up to their Deployment or CronJob
+type OwnerIndex map[string]metav1.OwnerReference
+
+// FetchOwnerIndex lists the ReplicaSets and Jobs in the given namespaces (all
+// namespaces when empty) and records the controller of each one that has one
+func FetchOwnerIndex(ctx context.Context, client *kubernetes.Clientset, namespaces []string) (OwnerIndex, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	index := make(OwnerIndex)
+	for _, ns := range namespaces {
+		replicaSets, err := client.AppsV1().ReplicaSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list replicasets: %w", err)
+		}
+		for _, rs := range replicaSets.Items {
+			index.add(KindReplicaSet, rs.ObjectMeta)
+		}
+
+		jobs, err := client.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs: %w", err)
+		}
+		for _, j := range jobs.Items {
+			index.add(KindJob, j.ObjectMeta)
+		}
+	}
+	return index, nil
+}
+
+func (idx OwnerIndex) add(kind string, meta metav1.ObjectMeta) {
+	if ref := metav1.GetControllerOfNoCopy(&meta); ref != nil {
+		idx[kind+"/"+meta.Namespace+"/"+meta.Name] = *ref
+	}
+}
+
+// Owner follows a pod's controller reference up to the top-level controller:
+// ReplicaSet to Deployment and Job to CronJob. Pods without a controller are their
+// own owner (kind Pod). With a nil index, e.g. because ReplicaSets could not be
+// listed, a ReplicaSet is still attributed to its Deployment when the pod carries
+// the pod-template-hash label that Deployments put into ReplicaSet names.
+func (idx OwnerIndex) Owner(pod corev1.Pod) Owner {
+	ref := metav1.GetControllerOfNoCopy(&pod)
+	if ref == nil {
+		return Owner{Kind: KindPod, Name: pod.Name}
+	}
+
+	owner := Owner{Kind: ref.Kind, Name: ref.Name}
+	if owner.Kind != KindReplicaSet && owner.Kind != KindJob {
+		return owner
+	}
+
+	if parent, ok := idx[owner.Kind+"/"+pod.Namespace+"/"+owner.Name]; ok {
+		return Owner{Kind: parent.Kind, Name: parent.Name}
+	}
+	if idx == nil && owner.Kind == KindReplicaSet {
+		hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+		if deployment, ok := strings.CutSuffix(owner.Name, "-"+hash); ok && hash != "" {
+			return Owner{Kind: KindDeployment, Name: deployment}
+		}
+	}
+	return owner
+}
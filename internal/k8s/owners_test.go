@@ -0,0 +1,50 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func TestOwnerIndexOwner(t *testing.T) {
+	t.Parallel()
+	index := make(OwnerIndex)
+	index.add(KindReplicaSet, metav1.ObjectMeta{Name: "api-7d9f8b", Namespace: "shop", OwnerReferences: controlledBy(KindDeployment, "api")})
+	index.add(KindJob, metav1.ObjectMeta{Name: "report-29000000", Namespace: "shop", OwnerReferences: controlledBy(KindCronJob, "report")})
+	index.add(KindReplicaSet, metav1.ObjectMeta{Name: "standalone-x2k", Namespace: "shop"})
+
+	pod := func(name string, refs []metav1.OwnerReference, labels map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", OwnerReferences: refs, Labels: labels}}
+	}
+	hashed := map[string]string{"pod-template-hash": "7d9f8b"}
+
+	tests := []struct {
+		name  string
+		index OwnerIndex
+		pod   corev1.Pod
+		want  Owner
+	}{
+		{name: "deployment through replicaset", index: index, pod: pod("api-7d9f8b-abcde", controlledBy(KindReplicaSet, "api-7d9f8b"), hashed), want: Owner{KindDeployment, "api"}},
+		{name: "cronjob through job", index: index, pod: pod("report-29000000-q7x", controlledBy(KindJob, "report-29000000"), nil), want: Owner{KindCronJob, "report"}},
+		{name: "standalone replicaset", index: index, pod: pod("standalone-x2k-p1", controlledBy(KindReplicaSet, "standalone-x2k"), nil), want: Owner{KindReplicaSet, "standalone-x2k"}},
+		{name: "statefulset", index: index, pod: pod("db-0", controlledBy(KindStatefulSet, "db"), nil), want: Owner{KindStatefulSet, "db"}},
+		{name: "bare pod", index: index, pod: pod("debug", nil, nil), want: Owner{KindPod, "debug"}},
+		{name: "deployment inferred without index", pod: pod("api-7d9f8b-abcde", controlledBy(KindReplicaSet, "api-7d9f8b"), hashed), want: Owner{KindDeployment, "api"}},
+		{name: "job kept without index", pod: pod("report-29000000-q7x", controlledBy(KindJob, "report-29000000"), nil), want: Owner{KindJob, "report-29000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.index.Owner(tt.pod); got != tt.want {
+				t.Errorf("got %s/%s, want %s/%s", got.Kind, got.Name, tt.want.Kind, tt.want.Name)
+			}
+		})
+	}
+}
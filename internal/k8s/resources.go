@@ -5,18 +5,39 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 type PodResources struct {
-	Name         string
-	Namespace    string
-	CPURequest   string
+	Name          string
+	Namespace     string
+	CPURequest    string
 	MemoryRequest string
-	CPULimit     string
-	MemoryLimit  string
+	CPULimit      string
+	MemoryLimit   string
+	// Extended holds requests for extended resources such as nvidia.com/gpu or
+	// hugepages-2Mi, keyed by resource name
+	Extended map[string]string
+	// CPURule and MemoryRule name the rule that set each request (see EffectiveResources)
+	CPURule    string
+	MemoryRule string
+}
+
+// RequestRule describes which rule set the pod's requests, naming CPU and memory
+// separately when they differ
+func (r PodResources) RequestRule() string {
+	switch {
+	case r.CPURule == r.MemoryRule:
+		return r.CPURule
+	case r.CPURule == "":
+		return "memory=" + r.MemoryRule
+	case r.MemoryRule == "":
+		return "cpu=" + r.CPURule
+	default:
+		return "cpu=" + r.CPURule + ", memory=" + r.MemoryRule
+	}
 }
 
 // FetchPods retrieves all pods from the specified namespace
@@ -28,37 +49,105 @@ func FetchPods(ctx context.Context, client *kubernetes.Clientset, namespace stri
 	return pods.Items, nil
 }
 
-// ExtractResources parses resource requests and limits from a pod
-func ExtractResources(pod corev1.Pod) PodResources {
-	var cpuRequest, memoryRequest, cpuLimit, memoryLimit resource.Quantity
-
-	for _, container := range pod.Spec.Containers {
-		if container.Resources.Requests != nil {
-			if cpu, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-				cpuRequest.Add(cpu)
-			}
-			if mem, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-				memoryRequest.Add(mem)
-			}
-		}
-		if container.Resources.Limits != nil {
-			if cpu, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
-				cpuLimit.Add(cpu)
-			}
-			if mem, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
-				memoryLimit.Add(mem)
-			}
+// FetchPodsInNamespaces retrieves pods from each of the given namespaces.
+// An empty list or metav1.NamespaceAll fetches pods across the whole cluster.
+func FetchPodsInNamespaces(ctx context.Context, client *kubernetes.Clientset, namespaces []string) ([]corev1.Pod, error) {
+	if len(namespaces) == 0 {
+		return FetchPods(ctx, client, metav1.NamespaceAll)
+	}
+
+	var all []corev1.Pod
+	for _, ns := range namespaces {
+		pods, err := FetchPods(ctx, client, ns)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s: %w", ns, err)
 		}
+		all = append(all, pods...)
 	}
+	return all, nil
+}
+
+// FetchNamespaces retrieves all namespaces with their labels and annotations
+func FetchNamespaces(ctx context.Context, client *kubernetes.Clientset) ([]corev1.Namespace, error) {
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return list.Items, nil
+}
 
-	return PodResources{
+// ListNamespaces returns the names of namespaces matching a label selector.
+// An empty selector matches every namespace.
+func ListNamespaces(ctx context.Context, client *kubernetes.Clientset, selector string) ([]string, error) {
+	list, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	names := make([]string, len(list.Items))
+	for i, ns := range list.Items {
+		names[i] = ns.Name
+	}
+	return names, nil
+}
+
+// ExtractResources computes a pod's effective requests and limits the way the
+// scheduler does (see EffectiveResources) and records which rule set each request.
+// Extended resources may only be set as limits, in which case the API server
+// defaults the request to the limit; the same is done here for manifests.
+func ExtractResources(pod corev1.Pod) PodResources {
+	requests, rules := EffectiveResources(pod.Spec, containerRequests)
+	limits, _ := EffectiveResources(pod.Spec, containerLimits)
+
+	res := PodResources{
 		Name:          pod.Name,
 		Namespace:     pod.Namespace,
-		CPURequest:    formatQuantity(cpuRequest),
-		MemoryRequest: formatQuantity(memoryRequest),
-		CPULimit:      formatQuantity(cpuLimit),
-		MemoryLimit:   formatQuantity(memoryLimit),
+		CPURequest:    formatQuantity(requests[corev1.ResourceCPU]),
+		MemoryRequest: formatQuantity(requests[corev1.ResourceMemory]),
+		CPULimit:      formatQuantity(limits[corev1.ResourceCPU]),
+		MemoryLimit:   formatQuantity(limits[corev1.ResourceMemory]),
+		CPURule:       rules[corev1.ResourceCPU],
+		MemoryRule:    rules[corev1.ResourceMemory],
 	}
+	for name, qty := range requests {
+		if !IsExtendedResource(name) || qty.IsZero() {
+			continue
+		}
+		if res.Extended == nil {
+			res.Extended = make(map[string]string)
+		}
+		res.Extended[string(name)] = qty.String()
+	}
+	return res
+}
+
+// IsExtendedResource reports whether a resource is priced separately from CPU and
+// memory: device plugin resources such as nvidia.com/gpu and hugepages-*
+func IsExtendedResource(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage, corev1.ResourceStorage, corev1.ResourcePods:
+		return false
+	}
+	return true
+}
+
+// containerRequests returns a container's requests, falling back to the limit for
+// extended resources that only set one
+func containerRequests(c corev1.Container) corev1.ResourceList {
+	out := make(corev1.ResourceList)
+	for name, qty := range c.Resources.Limits {
+		if IsExtendedResource(name) {
+			out[name] = qty
+		}
+	}
+	for name, qty := range c.Resources.Requests {
+		out[name] = qty
+	}
+	return out
+}
+
+func containerLimits(c corev1.Container) corev1.ResourceList {
+	return c.Resources.Limits
 }
 
 func formatQuantity(q resource.Quantity) string {
@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestExtractResourcesExtended(t *testing.T) {
+	t.Parallel()
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "trainer",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:              resource.MustParse("2"),
+							corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+						},
+						// Extended resources are commonly set as limits only
+						Limits: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("1"),
+						},
+					},
+				},
+				{
+					Name: "sidecar",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("1"),
+							"hugepages-2Mi":  resource.MustParse("256Mi"),
+						},
+						Limits: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("1"),
+							"hugepages-2Mi":  resource.MustParse("256Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	res := ExtractResources(pod)
+
+	want := map[string]string{
+		"nvidia.com/gpu": "2",
+		"hugepages-2Mi":  "256Mi",
+	}
+	if len(res.Extended) != len(want) {
+		t.Fatalf("extended resources: got %v, want %v", res.Extended, want)
+	}
+	for name, qty := range want {
+		if got := res.Extended[name]; got != qty {
+			t.Errorf("%s: got %q, want %q", name, got, qty)
+		}
+	}
+	if res.CPURequest != "2" {
+		t.Errorf("CPU request: got %q, want %q", res.CPURequest, "2")
+	}
+}
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Claim is a bound PersistentVolumeClaim with the storage it holds
+type Claim struct {
+	Name         string
+	Namespace    string
+	StorageClass string
+	Capacity     resource.Quantity
+}
+
+// FetchClaims retrieves the PersistentVolumeClaims in the given namespaces (all
+// namespaces when empty) and resolves the capacity and StorageClass of the volumes
+// they are bound to. Pending claims hold no storage and are left out. If volumes
+// cannot be listed, the claim's own status and spec are used instead.
+func FetchClaims(ctx context.Context, client *kubernetes.Clientset, namespaces []string) ([]Claim, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var pvcs []corev1.PersistentVolumeClaim
+	for _, ns := range namespaces {
+		list, err := client.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+		}
+		pvcs = append(pvcs, list.Items...)
+	}
+	if len(pvcs) == 0 {
+		return nil, nil
+	}
+
+	// Volumes are cluster-scoped; without permission to list them, fall back to claims
+	var pvs []corev1.PersistentVolume
+	if list, err := client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{}); err == nil {
+		pvs = list.Items
+	}
+
+	return ResolveClaims(pvcs, pvs), nil
+}
+
+// ResolveClaims pairs bound claims with their volumes. Capacity and StorageClass
+// come from the volume when it is known, otherwise from the claim's status and spec.
+func ResolveClaims(pvcs []corev1.PersistentVolumeClaim, pvs []corev1.PersistentVolume) []Claim {
+	volumes := make(map[string]corev1.PersistentVolume, len(pvs))
+	for _, pv := range pvs {
+		volumes[pv.Name] = pv
+	}
+
+	claims := make([]Claim, 0, len(pvcs))
+	for _, pvc := range pvcs {
+		if pvc.Status.Phase != corev1.ClaimBound {
+			continue
+		}
+
+		claim := Claim{
+			Name:      pvc.Name,
+			Namespace: pvc.Namespace,
+			Capacity:  pvc.Status.Capacity[corev1.ResourceStorage],
+		}
+		if pvc.Spec.StorageClassName != nil {
+			claim.StorageClass = *pvc.Spec.StorageClassName
+		}
+		if pv, ok := volumes[pvc.Spec.VolumeName]; ok {
+			if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+				claim.Capacity = capacity
+			}
+			if pv.Spec.StorageClassName != "" {
+				claim.StorageClass = pv.Spec.StorageClassName
+			}
+		}
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// PodClaimNames returns the names of the claims a pod mounts, including the claims
+// created for its generic ephemeral volumes
+func PodClaimNames(pod corev1.Pod) []string {
+	var names []string
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			names = append(names, v.PersistentVolumeClaim.ClaimName)
+		case v.Ephemeral != nil:
+			names = append(names, pod.Name+"-"+v.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveClaims(t *testing.T) {
+	t.Parallel()
+	standard := "standard"
+	pvcs := []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "db"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data", StorageClassName: &standard},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Phase:    corev1.ClaimBound,
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "db"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-missing", StorageClassName: &standard},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Phase:    corev1.ClaimBound,
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "db"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+	}
+	pvs := []corev1.PersistentVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+				StorageClassName: "fast-ssd",
+			},
+		},
+	}
+
+	claims := ResolveClaims(pvcs, pvs)
+
+	tests := []struct {
+		name         string
+		wantClass    string
+		wantCapacity string
+	}{
+		{name: "data", wantClass: "fast-ssd", wantCapacity: "20Gi"},
+		{name: "cache", wantClass: "standard", wantCapacity: "5Gi"},
+	}
+	if len(claims) != len(tests) {
+		t.Fatalf("claims: got %d, want %d (pending claims hold no storage)", len(claims), len(tests))
+	}
+	for i, tt := range tests {
+		c := claims[i]
+		if c.Name != tt.name {
+			t.Errorf("claim %d: got %q, want %q", i, c.Name, tt.name)
+		}
+		if c.StorageClass != tt.wantClass {
+			t.Errorf("%s storage class: got %q, want %q", tt.name, c.StorageClass, tt.wantClass)
+		}
+		if got := c.Capacity.String(); got != tt.wantCapacity {
+			t.Errorf("%s capacity: got %q, want %q", tt.name, got, tt.wantCapacity)
+		}
+	}
+}
+
+func TestPodClaimNames(t *testing.T) {
+	t.Parallel()
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0"},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-web-0"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{}}},
+			},
+		},
+	}
+
+	got := PodClaimNames(pod)
+	want := []string{"data-web-0", "web-0-scratch"}
+	if len(got) != len(want) {
+		t.Fatalf("claim names: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("claim %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Workload kinds reported by FetchWorkloads; bare pods are only reported from manifests
+const (
+	KindPod         = "Pod"
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindReplicaSet  = "ReplicaSet"
+	KindJob         = "Job"
+	KindCronJob     = "CronJob"
+)
+
+// Workload is a pod controller together with the number of replicas it wants running
+type Workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Replicas  int32
+	Template  corev1.PodTemplateSpec
+	// Claims holds the storage each replica claims from volume claim templates
+	Claims []Claim
+}
+
+// Pod returns a pod built from the workload's template, for resource extraction
+func (w Workload) Pod() corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: w.Template.ObjectMeta,
+		Spec:       w.Template.Spec,
+	}
+	pod.Name = w.Name
+	pod.Namespace = w.Namespace
+	return pod
+}
+
+// FetchWorkloads lists workload controllers in the given namespaces and resolves their
+// desired replica counts. An empty list fetches workloads across the whole cluster.
+// ReplicaSets owned by a Deployment and Jobs owned by a CronJob are skipped so that
+// each pod template is only counted once.
+func FetchWorkloads(ctx context.Context, client *kubernetes.Clientset, namespaces []string) ([]Workload, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	nodes, err := FetchNodes(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []Workload
+	for _, ns := range namespaces {
+		found, err := fetchNamespaceWorkloads(ctx, client, ns, nodes)
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, found...)
+	}
+	return workloads, nil
+}
+
+func fetchNamespaceWorkloads(ctx context.Context, client *kubernetes.Clientset, namespace string, nodes []corev1.Node) ([]Workload, error) {
+	var objects workloadObjects
+	opts := metav1.ListOptions{}
+
+	deployments, err := client.AppsV1().Deployments(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	objects.deployments = deployments.Items
+
+	statefulSets, err := client.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	objects.statefulSets = statefulSets.Items
+
+	daemonSets, err := client.AppsV1().DaemonSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	objects.daemonSets = daemonSets.Items
+
+	replicaSets, err := client.AppsV1().ReplicaSets(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replicasets: %w", err)
+	}
+	objects.replicaSets = replicaSets.Items
+
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	objects.jobs = jobs.Items
+
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cronjobs: %w", err)
+	}
+	objects.cronJobs = cronJobs.Items
+
+	return objects.workloads(nodes), nil
+}
+
+// workloadObjects holds workload controllers, whether listed or cached
+type workloadObjects struct {
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	daemonSets   []appsv1.DaemonSet
+	replicaSets  []appsv1.ReplicaSet
+	jobs         []batchv1.Job
+	cronJobs     []batchv1.CronJob
+}
+
+// workloads converts the controllers to workloads, skipping ReplicaSets and Jobs
+// that have a controller of their own
+func (o workloadObjects) workloads(nodes []corev1.Node) []Workload {
+	var workloads []Workload
+	for _, d := range o.deployments {
+		workloads = append(workloads, DeploymentWorkload(d))
+	}
+	for _, s := range o.statefulSets {
+		workloads = append(workloads, StatefulSetWorkload(s))
+	}
+	for _, ds := range o.daemonSets {
+		workloads = append(workloads, DaemonSetWorkload(ds, DaemonSetNodeCount(ds, nodes)))
+	}
+	for _, rs := range o.replicaSets {
+		if metav1.GetControllerOf(&rs) != nil {
+			continue
+		}
+		workloads = append(workloads, ReplicaSetWorkload(rs))
+	}
+	for _, j := range o.jobs {
+		if metav1.GetControllerOf(&j) != nil {
+			continue
+		}
+		workloads = append(workloads, JobWorkload(j))
+	}
+	for _, cj := range o.cronJobs {
+		workloads = append(workloads, CronJobWorkload(cj))
+	}
+	return workloads
+}
+
+// PodWorkload converts a bare pod into a one-replica workload
+func PodWorkload(p corev1.Pod) Workload {
+	return Workload{
+		Kind:      KindPod,
+		Name:      p.Name,
+		Namespace: p.Namespace,
+		Replicas:  1,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: p.ObjectMeta,
+			Spec:       p.Spec,
+		},
+	}
+}
+
+// DeploymentWorkload converts a Deployment using its desired replicas (default 1)
+func DeploymentWorkload(d appsv1.Deployment) Workload {
+	return Workload{
+		Kind:      KindDeployment,
+		Name:      d.Name,
+		Namespace: d.Namespace,
+		Replicas:  replicasOrDefault(d.Spec.Replicas),
+		Template:  d.Spec.Template,
+	}
+}
+
+// StatefulSetWorkload converts a StatefulSet using its desired replicas (default 1)
+func StatefulSetWorkload(s appsv1.StatefulSet) Workload {
+	return Workload{
+		Kind:      KindStatefulSet,
+		Name:      s.Name,
+		Namespace: s.Namespace,
+		Replicas:  replicasOrDefault(s.Spec.Replicas),
+		Template:  s.Spec.Template,
+		Claims:    templateClaims(s),
+	}
+}
+
+// templateClaims returns the claim each replica of a StatefulSet gets from its
+// volume claim templates, sized at the requested storage
+func templateClaims(s appsv1.StatefulSet) []Claim {
+	var claims []Claim
+	for _, t := range s.Spec.VolumeClaimTemplates {
+		claim := Claim{
+			Name:      t.Name,
+			Namespace: s.Namespace,
+			Capacity:  t.Spec.Resources.Requests[corev1.ResourceStorage],
+		}
+		if t.Spec.StorageClassName != nil {
+			claim.StorageClass = *t.Spec.StorageClassName
+		}
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// ReplicaSetWorkload converts a ReplicaSet using its desired replicas (default 1)
+func ReplicaSetWorkload(rs appsv1.ReplicaSet) Workload {
+	return Workload{
+		Kind:      KindReplicaSet,
+		Name:      rs.Name,
+		Namespace: rs.Namespace,
+		Replicas:  replicasOrDefault(rs.Spec.Replicas),
+		Template:  rs.Spec.Template,
+	}
+}
+
+// DaemonSetWorkload converts a DaemonSet that runs one pod on each of nodeCount nodes
+func DaemonSetWorkload(ds appsv1.DaemonSet, nodeCount int32) Workload {
+	return Workload{
+		Kind:      KindDaemonSet,
+		Name:      ds.Name,
+		Namespace: ds.Namespace,
+		Replicas:  nodeCount,
+		Template:  ds.Spec.Template,
+	}
+}
+
+// JobWorkload converts a Job using its parallelism, capped by the completions still
+// outstanding. Finished jobs want no pods.
+func JobWorkload(j batchv1.Job) Workload {
+	var replicas int32
+	if !jobFinished(j) {
+		replicas = replicasOrDefault(j.Spec.Parallelism)
+		if j.Spec.Completions != nil {
+			remaining := *j.Spec.Completions - j.Status.Succeeded
+			if remaining < replicas {
+				replicas = max(remaining, 0)
+			}
+		}
+	}
+
+	return Workload{
+		Kind:      KindJob,
+		Name:      j.Name,
+		Namespace: j.Namespace,
+		Replicas:  replicas,
+		Template:  j.Spec.Template,
+	}
+}
+
+// CronJobWorkload converts a CronJob using the parallelism of its job template.
+// The cost therefore describes a running job, not an average over the schedule.
+// Suspended CronJobs want no pods.
+func CronJobWorkload(cj batchv1.CronJob) Workload {
+	var replicas int32
+	if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+		replicas = replicasOrDefault(cj.Spec.JobTemplate.Spec.Parallelism)
+	}
+
+	return Workload{
+		Kind:      KindCronJob,
+		Name:      cj.Name,
+		Namespace: cj.Namespace,
+		Replicas:  replicas,
+		Template:  cj.Spec.JobTemplate.Spec.Template,
+	}
+}
+
+// daemonSetTolerations are added to every DaemonSet pod by the DaemonSet controller
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: "node.kubernetes.io/not-ready", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: "node.kubernetes.io/unreachable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: "node.kubernetes.io/disk-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/memory-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/pid-pressure", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: "node.kubernetes.io/unschedulable", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// DaemonSetNodeCount counts the nodes a DaemonSet would place a pod on, honouring
+// its nodeSelector, required node affinity and tolerations for NoSchedule/NoExecute taints
+func DaemonSetNodeCount(ds appsv1.DaemonSet, nodes []corev1.Node) int32 {
+	spec := *ds.Spec.Template.Spec.DeepCopy()
+	spec.Tolerations = append(spec.Tolerations, daemonSetTolerations...)
+
+	var count int32
+	for _, node := range nodes {
+		if nodeRunsPodSpec(node, spec) {
+			count++
+		}
+	}
+	return count
+}
+
+func nodeRunsPodSpec(node corev1.Node, spec corev1.PodSpec) bool {
+	if len(spec.NodeSelector) > 0 && !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	if spec.Affinity != nil && spec.Affinity.NodeAffinity != nil {
+		required := spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+		if required != nil && !nodeMatchesTerms(node, required.NodeSelectorTerms) {
+			return false
+		}
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !toleratesTaint(spec.Tolerations, taint) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nodeMatchesTerms reports whether any of the terms (ORed) matches the node
+func nodeMatchesTerms(node corev1.Node, terms []corev1.NodeSelectorTerm) bool {
+	for _, term := range terms {
+		if nodeMatchesTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeMatchesTerm(node corev1.Node, term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+
+	for _, expr := range term.MatchExpressions {
+		if !requirementMatches(expr, node.Labels) {
+			return false
+		}
+	}
+
+	// metadata.name is the only supported field selector for nodes
+	for _, expr := range term.MatchFields {
+		if expr.Key != "metadata.name" {
+			return false
+		}
+		if !requirementMatches(corev1.NodeSelectorRequirement{
+			Key:      "name",
+			Operator: expr.Operator,
+			Values:   expr.Values,
+		}, map[string]string{"name": node.Name}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+func requirementMatches(expr corev1.NodeSelectorRequirement, set map[string]string) bool {
+	op, ok := nodeSelectorOperators[expr.Operator]
+	if !ok {
+		return false
+	}
+	req, err := labels.NewRequirement(expr.Key, op, expr.Values)
+	if err != nil {
+		return false
+	}
+	return req.Matches(labels.Set(set))
+}
+
+func toleratesTaint(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func jobFinished(j batchv1.Job) bool {
+	for _, c := range j.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,161 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func TestJobWorkload(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		job          batchv1.Job
+		wantReplicas int32
+	}{
+		{
+			name:         "defaults to one pod",
+			job:          batchv1.Job{},
+			wantReplicas: 1,
+		},
+		{
+			name: "parallelism capped by remaining completions",
+			job: batchv1.Job{
+				Spec:   batchv1.JobSpec{Parallelism: int32Ptr(5), Completions: int32Ptr(10)},
+				Status: batchv1.JobStatus{Succeeded: 8},
+			},
+			wantReplicas: 2,
+		},
+		{
+			name: "finished job wants nothing",
+			job: batchv1.Job{
+				Spec: batchv1.JobSpec{Parallelism: int32Ptr(5)},
+				Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+				}},
+			},
+			wantReplicas: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w := JobWorkload(tt.job)
+			if w.Replicas != tt.wantReplicas {
+				t.Errorf("replicas: got %d, want %d", w.Replicas, tt.wantReplicas)
+			}
+		})
+	}
+}
+
+func TestStatefulSetWorkloadClaims(t *testing.T) {
+	t.Parallel()
+	fast := "gp3"
+	s := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "cache"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(3),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: &fast,
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+						},
+					},
+				},
+				{ObjectMeta: metav1.ObjectMeta{Name: "logs"}},
+			},
+		},
+	}
+
+	w := StatefulSetWorkload(s)
+	if w.Replicas != 3 {
+		t.Errorf("replicas: got %d, want 3", w.Replicas)
+	}
+	if len(w.Claims) != 2 {
+		t.Fatalf("claims: got %d, want 2", len(w.Claims))
+	}
+	data := w.Claims[0]
+	if data.Name != "data" || data.Namespace != "cache" || data.StorageClass != "gp3" || data.Capacity.String() != "10Gi" {
+		t.Errorf("data claim: got %s/%s %s %s, want cache/data gp3 10Gi", data.Namespace, data.Name, data.StorageClass, data.Capacity.String())
+	}
+	if logs := w.Claims[1]; logs.StorageClass != "" || !logs.Capacity.IsZero() {
+		t.Errorf("logs claim: got %q %s, want default class and no capacity", logs.StorageClass, logs.Capacity.String())
+	}
+}
+
+func TestDaemonSetNodeCount(t *testing.T) {
+	t.Parallel()
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"pool": "general"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"pool": "gpu"}}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "control-plane", Labels: map[string]string{"pool": "system"}},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "node-role.kubernetes.io/control-plane", Effect: corev1.TaintEffectNoSchedule},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cordoned", Labels: map[string]string{"pool": "general"}},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		spec      corev1.PodSpec
+		wantCount int32
+	}{
+		{
+			name:      "untainted and cordoned nodes",
+			spec:      corev1.PodSpec{},
+			wantCount: 3,
+		},
+		{
+			name: "tolerates control plane",
+			spec: corev1.PodSpec{Tolerations: []corev1.Toleration{
+				{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.TolerationOpExists},
+			}},
+			wantCount: 4,
+		},
+		{
+			name:      "node selector",
+			spec:      corev1.PodSpec{NodeSelector: map[string]string{"pool": "gpu"}},
+			wantCount: 1,
+		},
+		{
+			name: "required node affinity",
+			spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"}},
+						},
+					}},
+				},
+			}}},
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ds := appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: tt.spec}}}
+			if got := DaemonSetNodeCount(ds, nodes); got != tt.wantCount {
+				t.Errorf("node count: got %d, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
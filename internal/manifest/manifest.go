@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// Stdin is the path that reads manifests from standard input
+const Stdin = "-"
+
+// Options control how decoded objects are turned into workloads
+type Options struct {
+	// Namespace is assigned to objects that do not set one
+	Namespace string
+	// DaemonSetNodes is the node count assumed for every DaemonSet
+	DaemonSetNodes int32
+}
+
+// Load reads Kubernetes objects from files, directories (walked recursively for
+// .yaml, .yml and .json files) or Stdin. Multi-document YAML and v1 Lists are
+// expanded; kinds unknown to the client-go scheme, such as custom resources, are skipped.
+func Load(paths []string, stdin io.Reader) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	for _, path := range paths {
+		if path == Stdin {
+			objs, err := Decode(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("stdin: %w", err)
+			}
+			objects = append(objects, objs...)
+			continue
+		}
+
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			objs, err := decodeFile(file)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, objs...)
+		}
+	}
+	return objects, nil
+}
+
+// Decode reads every YAML or JSON document from r
+func Decode(r io.Reader) ([]runtime.Object, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	decoder := scheme.Codecs.UniversalDeserializer()
+
+	var objects []runtime.Object
+	for doc := 1; ; doc++ {
+		data, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %d: %w", doc, err)
+		}
+
+		if isEmptyDocument(data) {
+			continue
+		}
+
+		obj, _, err := decoder.Decode(data, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode document %d: %w", doc, err)
+		}
+
+		expanded, err := expandList(obj)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", doc, err)
+		}
+		objects = append(objects, expanded...)
+	}
+	return objects, nil
+}
+
+// Workloads converts decoded objects into workloads. Bare Pods become one-replica
+// workloads of kind Pod; ReplicaSets and Jobs with a controller owner are skipped,
+// as are objects that do not run pods.
+func Workloads(objects []runtime.Object, opts Options) []k8s.Workload {
+	var workloads []k8s.Workload
+	for _, obj := range objects {
+		var w k8s.Workload
+		switch o := obj.(type) {
+		case *corev1.Pod:
+			w = k8s.PodWorkload(*o)
+		case *appsv1.Deployment:
+			w = k8s.DeploymentWorkload(*o)
+		case *appsv1.StatefulSet:
+			w = k8s.StatefulSetWorkload(*o)
+		case *appsv1.DaemonSet:
+			w = k8s.DaemonSetWorkload(*o, opts.DaemonSetNodes)
+		case *appsv1.ReplicaSet:
+			if metav1.GetControllerOf(o) != nil {
+				continue
+			}
+			w = k8s.ReplicaSetWorkload(*o)
+		case *batchv1.Job:
+			if metav1.GetControllerOf(o) != nil {
+				continue
+			}
+			w = k8s.JobWorkload(*o)
+		case *batchv1.CronJob:
+			w = k8s.CronJobWorkload(*o)
+		default:
+			continue
+		}
+
+		if w.Namespace == "" {
+			w.Namespace = opts.Namespace
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads
+}
+
+func decodeFile(path string) ([]runtime.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	objs, err := Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return objs, nil
+}
+
+// manifestFiles expands a path into the manifest files it contains
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk manifest directory: %w", err)
+	}
+	return files, nil
+}
+
+// expandList flattens v1 List objects (as produced by kubectl get -o yaml)
+func expandList(obj runtime.Object) ([]runtime.Object, error) {
+	list, ok := obj.(*corev1.List)
+	if !ok {
+		return []runtime.Object{obj}, nil
+	}
+
+	var objects []runtime.Object
+	for i, item := range list.Items {
+		items, err := Decode(bytes.NewReader(item.Raw))
+		if err != nil {
+			return nil, fmt.Errorf("list item %d: %w", i, err)
+		}
+		objects = append(objects, items...)
+	}
+	return objects, nil
+}
+
+// isEmptyDocument reports whether a document holds nothing but whitespace and comments
+func isEmptyDocument(data []byte) bool {
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+)
+
+const multiDocYAML = `# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web}
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        resources:
+          requests: {cpu: 250m, memory: 256Mi}
+---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# only a comment
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: custom
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+  namespace: monitoring
+spec:
+  selector:
+    matchLabels: {app: agent}
+  template:
+    metadata:
+      labels: {app: agent}
+    spec:
+      containers:
+      - name: agent
+        image: agent
+`
+
+const podListJSON = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "Pod",
+      "metadata": {"name": "debug", "namespace": "tools"},
+      "spec": {"containers": [{"name": "sh", "image": "busybox"}]}
+    }
+  ]
+}`
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		input     string
+		wantKinds []string
+		wantErr   bool
+	}{
+		{
+			name:      "multi-document YAML skips services, comments and custom resources",
+			input:     multiDocYAML,
+			wantKinds: []string{"Deployment", "DaemonSet"},
+		},
+		{
+			name:      "JSON list is expanded",
+			input:     podListJSON,
+			wantKinds: []string{"Pod"},
+		},
+		{
+			name:    "malformed document",
+			input:   "apiVersion: apps/v1\nkind: Deployment\nspec: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			objects, err := Decode(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode failed: %v", err)
+			}
+
+			workloads := Workloads(objects, Options{Namespace: "default", DaemonSetNodes: 1})
+			if len(workloads) != len(tt.wantKinds) {
+				t.Fatalf("workload count: got %d, want %d", len(workloads), len(tt.wantKinds))
+			}
+			for i, kind := range tt.wantKinds {
+				if workloads[i].Kind != kind {
+					t.Errorf("workload %d kind: got %q, want %q", i, workloads[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkloads(t *testing.T) {
+	t.Parallel()
+	objects, err := Decode(strings.NewReader(multiDocYAML))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+
+	workloads := Workloads(objects, Options{Namespace: "staging", DaemonSetNodes: 4})
+	want := []k8s.Workload{
+		{Kind: "Deployment", Name: "web", Namespace: "staging", Replicas: 3},
+		{Kind: "DaemonSet", Name: "agent", Namespace: "monitoring", Replicas: 4},
+	}
+
+	if len(workloads) != len(want) {
+		t.Fatalf("workload count: got %d, want %d", len(workloads), len(want))
+	}
+	for i, w := range want {
+		got := workloads[i]
+		if got.Kind != w.Kind || got.Name != w.Name || got.Namespace != w.Namespace || got.Replicas != w.Replicas {
+			t.Errorf("workload %d: got %s %s/%s x%d, want %s %s/%s x%d",
+				i, got.Kind, got.Namespace, got.Name, got.Replicas, w.Kind, w.Namespace, w.Name, w.Replicas)
+		}
+	}
+
+	res := k8s.ExtractResources(workloads[0].Pod())
+	if res.CPURequest != "250m" || res.MemoryRequest != "256Mi" {
+		t.Errorf("template requests: got %s/%s, want 250m/256Mi", res.CPURequest, res.MemoryRequest)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "base")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(dir, "app.yaml"):     multiDocYAML,
+		filepath.Join(nested, "pods.json"): podListJSON,
+		filepath.Join(nested, "README.md"): "not a manifest",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	stdin := strings.NewReader(podListJSON)
+	objects, err := Load([]string{dir, Stdin}, stdin)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Deployment, Service and DaemonSet from app.yaml, Pod from pods.json, Pod from stdin
+	if len(objects) != 5 {
+		t.Errorf("object count: got %d, want 5", len(objects))
+	}
+
+	if _, err := Load([]string{filepath.Join(dir, "missing.yaml")}, nil); err == nil {
+		t.Error("expected error for missing path, got nil")
+	}
+}
@@ -0,0 +1,265 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// awsOffer is the part of an AWS Price List bulk offer file (AmazonEC2) that is used
+type awsOffer struct {
+	PublicationDate string                `json:"publicationDate"`
+	Products        map[string]awsProduct `json:"products"`
+	Terms           struct {
+		OnDemand map[string]map[string]awsTerm `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+type awsProduct struct {
+	ProductFamily string            `json:"productFamily"`
+	Attributes    map[string]string `json:"attributes"`
+}
+
+type awsTerm struct {
+	PriceDimensions map[string]struct {
+		Unit         string            `json:"unit"`
+		PricePerUnit map[string]string `json:"pricePerUnit"`
+	} `json:"priceDimensions"`
+}
+
+// awsCSVColumns maps the bulk CSV headers to the attribute names of the JSON format
+var awsCSVColumns = map[string]string{
+	"Instance Type":     "instanceType",
+	"vCPU":              "vcpu",
+	"Memory":            "memory",
+	"GPU":               "gpu",
+	"Region Code":       "regionCode",
+	"Tenancy":           "tenancy",
+	"Operating System":  "operatingSystem",
+	"Pre Installed S/W": "preInstalledSw",
+	"CapacityStatus":    "capacitystatus",
+}
+
+func importAWSJSON(r io.Reader, opts Options) (calculator.Rates, error) {
+	var offer awsOffer
+	if err := json.NewDecoder(r).Decode(&offer); err != nil {
+		return calculator.Rates{}, fmt.Errorf("failed to decode AWS offer file: %w", err)
+	}
+
+	c := newAWSCollector(opts)
+	for sku, product := range offer.Products {
+		if product.ProductFamily != "Compute Instance" {
+			continue
+		}
+		for _, term := range offer.Terms.OnDemand[sku] {
+			for _, dim := range term.PriceDimensions {
+				for currency, price := range dim.PricePerUnit {
+					if err := c.add(product.Attributes, dim.Unit, currency, price); err != nil {
+						return calculator.Rates{}, fmt.Errorf("sku %s: %w", sku, err)
+					}
+				}
+			}
+		}
+	}
+
+	return c.rates(offer.PublicationDate)
+}
+
+func importAWSCSV(r io.Reader, opts Options) (calculator.Rates, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	// The header row is preceded by metadata rows such as "Publication Date","..."
+	var publicationDate string
+	var columns map[string]int
+	for columns == nil {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return calculator.Rates{}, fmt.Errorf("no header row found in AWS price list CSV")
+		}
+		if err != nil {
+			return calculator.Rates{}, fmt.Errorf("failed to read AWS price list CSV: %w", err)
+		}
+		switch {
+		case len(record) >= 2 && record[0] == "Publication Date":
+			publicationDate = record[1]
+		case len(record) > 0 && record[0] == "SKU":
+			columns = make(map[string]int, len(record))
+			for i, name := range record {
+				columns[name] = i
+			}
+		}
+	}
+	for _, name := range []string{"TermType", "Unit", "PricePerUnit", "Currency", "Product Family", "Instance Type"} {
+		if _, ok := columns[name]; !ok {
+			return calculator.Rates{}, fmt.Errorf("AWS price list CSV has no %q column", name)
+		}
+	}
+
+	c := newAWSCollector(opts)
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return calculator.Rates{}, fmt.Errorf("failed to read AWS price list CSV: %w", err)
+		}
+		if field(record, "TermType") != "OnDemand" || field(record, "Product Family") != "Compute Instance" {
+			continue
+		}
+
+		attrs := make(map[string]string, len(awsCSVColumns))
+		for header, attr := range awsCSVColumns {
+			attrs[attr] = field(record, header)
+		}
+		if err := c.add(attrs, field(record, "Unit"), field(record, "Currency"), field(record, "PricePerUnit")); err != nil {
+			line, _ := reader.FieldPos(0)
+			return calculator.Rates{}, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return c.rates(publicationDate)
+}
+
+// awsCollector keeps the Linux, shared-tenancy, on-demand hourly price of each
+// instance type and region
+type awsCollector struct {
+	opts      Options
+	currency  string
+	seen      map[calculator.Instance]bool
+	instances []calculator.InstancePrice
+}
+
+func newAWSCollector(opts Options) *awsCollector {
+	return &awsCollector{opts: opts, seen: make(map[calculator.Instance]bool)}
+}
+
+func (c *awsCollector) add(attrs map[string]string, unit, currency, price string) error {
+	if unit != "Hrs" ||
+		attrs["operatingSystem"] != "Linux" ||
+		attrs["tenancy"] != "Shared" ||
+		attrs["preInstalledSw"] != "NA" {
+		return nil
+	}
+	// Older price lists have no capacity status; newer ones also list reservations
+	if status := attrs["capacitystatus"]; status != "" && status != "Used" {
+		return nil
+	}
+	region := attrs["regionCode"]
+	if c.opts.Region != "" && region != c.opts.Region {
+		return nil
+	}
+
+	hourly, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price %q", price)
+	}
+	if hourly <= 0 {
+		return nil
+	}
+	if c.currency != "" && currency != c.currency {
+		return fmt.Errorf("mixed currencies %s and %s", c.currency, currency)
+	}
+	c.currency = currency
+
+	inst := calculator.Instance{Provider: "aws", Region: region, InstanceType: attrs["instanceType"]}
+	if inst.InstanceType == "" || c.seen[inst] {
+		return nil
+	}
+
+	vcpu, err := strconv.ParseFloat(attrs["vcpu"], 64)
+	if err != nil {
+		return fmt.Errorf("%s: invalid vcpu %q", inst.InstanceType, attrs["vcpu"])
+	}
+	memory, err := parseAWSMemory(attrs["memory"])
+	if err != nil {
+		return fmt.Errorf("%s: %w", inst.InstanceType, err)
+	}
+	gpu, _ := strconv.Atoi(attrs["gpu"])
+
+	c.seen[inst] = true
+	c.instances = append(c.instances, calculator.InstancePrice{
+		Provider:     inst.Provider,
+		Region:       inst.Region,
+		InstanceType: inst.InstanceType,
+		VCPU:         vcpu,
+		MemoryGB:     memory,
+		GPU:          gpu,
+		HourlyPrice:  hourly,
+	})
+	return nil
+}
+
+func (c *awsCollector) rates(publicationDate string) (calculator.Rates, error) {
+	if len(c.instances) == 0 {
+		return calculator.Rates{}, fmt.Errorf("no Linux on-demand instance prices found")
+	}
+
+	sort.Slice(c.instances, func(i, j int) bool {
+		a, b := c.instances[i], c.instances[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.InstanceType < b.InstanceType
+	})
+
+	rates := calculator.Rates{
+		Currency:  c.currency,
+		Source:    "AWS Price List, EC2 Linux on-demand",
+		Instances: c.instances,
+	}
+	if c.opts.Region != "" {
+		rates.Source += " (" + c.opts.Region + ")"
+	}
+	if t, err := parseAWSDate(publicationDate); err == nil {
+		rates.LastUpdated = calculator.Date{Time: t}
+	}
+
+	ref := calculator.Instance{Provider: "aws", Region: c.opts.Region, InstanceType: c.opts.ReferenceInstance}
+	if ref.Region == "" {
+		first, last := c.instances[0].Region, c.instances[len(c.instances)-1].Region
+		if first != last {
+			return calculator.Rates{}, fmt.Errorf("pricing data covers several regions; choose one with --region")
+		}
+		ref.Region = first
+	}
+	if err := flatRatesFrom(&rates, ref); err != nil {
+		return calculator.Rates{}, err
+	}
+	rates.Source += ", flat rates from " + ref.InstanceType + " in " + ref.Region
+	return rates, nil
+}
+
+// parseAWSMemory parses memory attributes such as "8 GiB" or "1,952 GiB"
+func parseAWSMemory(value string) (float64, error) {
+	number, unit, _ := strings.Cut(strings.ReplaceAll(value, ",", ""), " ")
+	memory, err := strconv.ParseFloat(number, 64)
+	if err != nil || (unit != "GiB" && unit != "") {
+		return 0, fmt.Errorf("invalid memory %q", value)
+	}
+	return memory, nil
+}
+
+func parseAWSDate(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return truncateDay(t), nil
+}
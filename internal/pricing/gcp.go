@@ -0,0 +1,176 @@
+package pricing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// gcpSKUPage is one page of the Cloud Billing Catalog API services.skus.list
+// response for Compute Engine
+type gcpSKUPage struct {
+	SKUs []gcpSKU `json:"skus"`
+}
+
+type gcpSKU struct {
+	Description string `json:"description"`
+	Category    struct {
+		ResourceFamily string `json:"resourceFamily"`
+		UsageType      string `json:"usageType"`
+	} `json:"category"`
+	ServiceRegions []string `json:"serviceRegions"`
+	PricingInfo    []struct {
+		PricingExpression struct {
+			UsageUnit   string `json:"usageUnit"`
+			TieredRates []struct {
+				StartUsageAmount float64 `json:"startUsageAmount"`
+				UnitPrice        struct {
+					CurrencyCode string `json:"currencyCode"`
+					Units        string `json:"units"`
+					Nanos        int64  `json:"nanos"`
+				} `json:"unitPrice"`
+			} `json:"tieredRates"`
+		} `json:"pricingExpression"`
+	} `json:"pricingInfo"`
+}
+
+// gcpSKUDescription matches predefined vCPU and memory SKUs such as
+// "E2 Instance Core running in Iowa" or "N1 Predefined Instance Ram running in Americas"
+var gcpSKUDescription = regexp.MustCompile(`^(\S+)(?: Predefined)? Instance (Core|Ram) running in `)
+
+// gcpPrices holds the per-vCPU and per-GiB hourly prices of a machine family in a region
+type gcpPrices struct {
+	core, ram float64
+}
+
+// importGCPSKUs reads one or more concatenated SKU list pages. GCP prices vCPUs and
+// memory separately per machine family, so the result is flat rates without a catalog.
+func importGCPSKUs(r io.Reader, opts Options) (calculator.Rates, error) {
+	prices := make(map[string]map[string]*gcpPrices) // family -> region -> prices
+	var currency string
+
+	dec := json.NewDecoder(r)
+	for page := 1; ; page++ {
+		var p gcpSKUPage
+		err := dec.Decode(&p)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return calculator.Rates{}, fmt.Errorf("failed to decode GCP SKU page %d: %w", page, err)
+		}
+
+		for _, sku := range p.SKUs {
+			m := gcpSKUDescription.FindStringSubmatch(sku.Description)
+			if m == nil || sku.Category.ResourceFamily != "Compute" || sku.Category.UsageType != "OnDemand" || len(sku.PricingInfo) == 0 {
+				continue
+			}
+			family, resource := strings.ToUpper(m[1]), m[2]
+
+			// The last pricing info is the current one; the first tier starts at zero usage
+			expr := sku.PricingInfo[len(sku.PricingInfo)-1].PricingExpression
+			if len(expr.TieredRates) == 0 {
+				continue
+			}
+			unitPrice := expr.TieredRates[0].UnitPrice
+			if currency != "" && unitPrice.CurrencyCode != currency {
+				return calculator.Rates{}, fmt.Errorf("mixed currencies %s and %s", currency, unitPrice.CurrencyCode)
+			}
+			currency = unitPrice.CurrencyCode
+
+			units, err := strconv.ParseFloat(unitPrice.Units, 64)
+			if unitPrice.Units != "" && err != nil {
+				return calculator.Rates{}, fmt.Errorf("%s: invalid price units %q", sku.Description, unitPrice.Units)
+			}
+			price := units + float64(unitPrice.Nanos)/1e9
+
+			wantUnit := map[string]string{"Core": "h", "Ram": "GiBy.h"}[resource]
+			if expr.UsageUnit != wantUnit {
+				return calculator.Rates{}, fmt.Errorf("%s: unexpected usage unit %q, want %q", sku.Description, expr.UsageUnit, wantUnit)
+			}
+
+			for _, region := range sku.ServiceRegions {
+				if opts.Region != "" && region != opts.Region {
+					continue
+				}
+				if prices[family] == nil {
+					prices[family] = make(map[string]*gcpPrices)
+				}
+				if prices[family][region] == nil {
+					prices[family][region] = &gcpPrices{}
+				}
+				if resource == "Core" {
+					prices[family][region].core = price
+				} else {
+					prices[family][region].ram = price
+				}
+			}
+		}
+	}
+
+	family, err := pickGCPFamily(prices, opts.MachineFamily)
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+	region, err := pickGCPRegion(prices[family], opts.Region)
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+	p := prices[family][region]
+	if p.core == 0 || p.ram == 0 {
+		return calculator.Rates{}, fmt.Errorf("%s in %s is missing a vCPU or memory price", family, region)
+	}
+
+	return calculator.Rates{
+		Currency:           currency,
+		Source:             fmt.Sprintf("GCP Cloud Billing Catalog, %s on-demand (%s)", family, region),
+		CPUPerCorePerHour:  p.core,
+		MemoryPerGBPerHour: p.ram,
+	}, nil
+}
+
+func pickGCPFamily(prices map[string]map[string]*gcpPrices, want string) (string, error) {
+	if len(prices) == 0 {
+		return "", fmt.Errorf("no on-demand vCPU or memory prices found")
+	}
+	if want != "" {
+		want = strings.ToUpper(want)
+		if _, ok := prices[want]; !ok {
+			return "", fmt.Errorf("machine family %s not found (found: %s)", want, strings.Join(sortedKeys(prices), ", "))
+		}
+		return want, nil
+	}
+	if len(prices) > 1 {
+		return "", fmt.Errorf("pricing data covers several machine families (%s); choose one with --machine-family", strings.Join(sortedKeys(prices), ", "))
+	}
+	return sortedKeys(prices)[0], nil
+}
+
+func pickGCPRegion(prices map[string]*gcpPrices, want string) (string, error) {
+	if want != "" {
+		if _, ok := prices[want]; !ok {
+			return "", fmt.Errorf("region %s not found", want)
+		}
+		return want, nil
+	}
+	if len(prices) > 1 {
+		return "", fmt.Errorf("pricing data covers several regions; choose one with --region")
+	}
+	return sortedKeys(prices)[0], nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
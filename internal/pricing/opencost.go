@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// openCostPricing is the custom pricing file read by OpenCost and Kubecost
+// (configs/default.json). Prices are strings; CPU is per core-hour and RAM per GiB-hour.
+type openCostPricing struct {
+	Provider    string `json:"provider"`
+	Description string `json:"description"`
+	CPU         string `json:"CPU"`
+	RAM         string `json:"RAM"`
+	GPU         string `json:"GPU"`
+	Storage     string `json:"storage"`
+	Currency    string `json:"currencyCode"`
+}
+
+func importOpenCost(r io.Reader) (calculator.Rates, error) {
+	var p openCostPricing
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return calculator.Rates{}, fmt.Errorf("failed to decode OpenCost pricing: %w", err)
+	}
+
+	cpu, err := parsePrice("CPU", p.CPU)
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+	ram, err := parsePrice("RAM", p.RAM)
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+
+	source := "OpenCost custom pricing"
+	if p.Description != "" {
+		source += ": " + p.Description
+	}
+	rates := calculator.Rates{
+		Currency:           p.Currency,
+		Source:             source,
+		CPUPerCorePerHour:  cpu,
+		MemoryPerGBPerHour: ram,
+	}
+
+	// OpenCost has a single GPU price; kcost prices GPUs by device plugin resource
+	if p.GPU != "" {
+		gpu, err := parsePrice("GPU", p.GPU)
+		if err != nil {
+			return calculator.Rates{}, err
+		}
+		rates.ExtendedResources = map[string]float64{"nvidia.com/gpu": gpu}
+	}
+
+	// OpenCost prices storage per GB-hour; rates files use GB-month
+	if p.Storage != "" {
+		storage, err := parsePrice("storage", p.Storage)
+		if err != nil {
+			return calculator.Rates{}, err
+		}
+		rates.StoragePerGBPerMonth = storage * calculator.HoursPerMonth
+	}
+	return rates, nil
+}
+
+func parsePrice(field, value string) (float64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("missing %s price", field)
+	}
+	price, err := strconv.ParseFloat(value, 64)
+	if err != nil || price < 0 {
+		return 0, fmt.Errorf("invalid %s price %q", field, value)
+	}
+	return price, nil
+}
@@ -0,0 +1,119 @@
+// Package pricing converts pricing dumps downloaded from cloud providers and
+// OpenCost into kcost rates files
+package pricing
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported import formats
+const (
+	FormatOpenCost = "opencost"
+	FormatAWSJSON  = "aws-json"
+	FormatAWSCSV   = "aws-csv"
+	FormatGCPSKUs  = "gcp-skus"
+)
+
+// Formats lists the supported import formats
+var Formats = []string{FormatOpenCost, FormatAWSJSON, FormatAWSCSV, FormatGCPSKUs}
+
+// DefaultReferenceInstance is the AWS instance type the flat rates are derived from
+const DefaultReferenceInstance = "m5.large"
+
+// Options narrow down what is imported from a pricing dump
+type Options struct {
+	// Region keeps only prices for this region; empty keeps all regions (AWS)
+	// or requires the dump to cover a single region (GCP)
+	Region string
+	// ReferenceInstance is the instance type the flat rates are derived from (AWS)
+	ReferenceInstance string
+	// MachineFamily selects the GCP machine family, e.g. E2 or N2
+	MachineFamily string
+	// Now dates imports whose dump carries no publication date
+	Now time.Time
+}
+
+// Import reads a pricing dump in the given format and returns normalized rates
+func Import(format string, r io.Reader, opts Options) (calculator.Rates, error) {
+	if opts.ReferenceInstance == "" {
+		opts.ReferenceInstance = DefaultReferenceInstance
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	var (
+		rates calculator.Rates
+		err   error
+	)
+	switch format {
+	case FormatOpenCost:
+		rates, err = importOpenCost(r)
+	case FormatAWSJSON:
+		rates, err = importAWSJSON(r, opts)
+	case FormatAWSCSV:
+		rates, err = importAWSCSV(r, opts)
+	case FormatGCPSKUs:
+		rates, err = importGCPSKUs(r, opts)
+	default:
+		return calculator.Rates{}, fmt.Errorf("unsupported import format: %s (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return calculator.Rates{}, err
+	}
+
+	rates.APIVersion = calculator.RatesAPIVersion
+	if rates.Currency == "" {
+		rates.Currency = "USD"
+	}
+	if rates.LastUpdated.IsZero() {
+		rates.LastUpdated = calculator.Date{Time: truncateDay(opts.Now)}
+	}
+	return rates, nil
+}
+
+// Write encodes rates as a rates file, preceded by a comment header
+func Write(w io.Writer, rates calculator.Rates, header string) error {
+	if header = strings.TrimSpace(header); header != "" {
+		for _, line := range strings.Split(header, "\n") {
+			if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(rates); err != nil {
+		return fmt.Errorf("failed to encode rates: %w", err)
+	}
+	return enc.Close()
+}
+
+// flatRatesFrom sets the flat rates from the catalog price of a reference instance,
+// split between CPU and memory in the same ratio as the built-in default rates
+func flatRatesFrom(rates *calculator.Rates, ref calculator.Instance) error {
+	defaults := calculator.DefaultRates()
+	defaults.Instances = rates.Instances
+
+	derived, ok := defaults.ForInstance(ref)
+	if !ok {
+		return fmt.Errorf("reference instance %s not found in pricing data; choose another with --reference-instance", ref.InstanceType)
+	}
+	rates.CPUPerCorePerHour = derived.CPUPerCorePerHour
+	rates.MemoryPerGBPerHour = derived.MemoryPerGBPerHour
+	return nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
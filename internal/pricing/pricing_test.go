@@ -0,0 +1,200 @@
+package pricing
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+const openCostFixture = `{
+  "provider": "custom",
+  "description": "Default prices based on GCP us-central1",
+  "CPU": "0.031611",
+  "spotCPU": "0.006655",
+  "RAM": "0.004237",
+  "GPU": "0.95",
+  "storage": "0.00005479452"
+}`
+
+const awsJSONFixture = `{
+  "formatVersion": "v1.0",
+  "offerCode": "AmazonEC2",
+  "publicationDate": "2025-11-20T20:15:23Z",
+  "products": {
+    "SKU1": {"sku": "SKU1", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "m5.large", "vcpu": "2", "memory": "8 GiB", "regionCode": "us-east-1",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU2": {"sku": "SKU2", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "m5.large", "vcpu": "2", "memory": "8 GiB", "regionCode": "us-east-1",
+      "operatingSystem": "Windows", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU3": {"sku": "SKU3", "productFamily": "Compute Instance", "attributes": {
+      "instanceType": "p3.2xlarge", "vcpu": "8", "memory": "61 GiB", "gpu": "1", "regionCode": "us-east-1",
+      "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "capacitystatus": "Used"}},
+    "SKU4": {"sku": "SKU4", "productFamily": "Storage", "attributes": {"volumeType": "gp3"}}
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU1": {"SKU1.JRTCKXETXF": {"priceDimensions": {"SKU1.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0960000000"}}}}},
+      "SKU2": {"SKU2.JRTCKXETXF": {"priceDimensions": {"SKU2.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "0.1880000000"}}}}},
+      "SKU3": {"SKU3.JRTCKXETXF": {"priceDimensions": {"SKU3.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "3.0600000000"}}}}}
+    }
+  }
+}`
+
+const awsCSVFixture = `"FormatVersion","v1.0"
+"Disclaimer","This pricing list is for informational purposes only."
+"Publication Date","2025-11-20T20:15:23Z"
+"Version","20251120201523"
+"OfferCode","AmazonEC2"
+"SKU","OfferTermCode","RateCode","TermType","PriceDescription","Unit","PricePerUnit","Currency","Product Family","Instance Type","vCPU","Memory","GPU","Region Code","Tenancy","Operating System","Pre Installed S/W","CapacityStatus"
+"SKU1","JRTCKXETXF","SKU1.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.096 per On Demand Linux m5.large Instance Hour","Hrs","0.0960000000","USD","Compute Instance","m5.large","2","8 GiB","","us-east-1","Shared","Linux","NA","Used"
+"SKU1","4NA7Y494T4","SKU1.4NA7Y494T4.6YS6EN2CT7","Reserved","Linux/UNIX (Amazon VPC), m5.large reserved instance","Hrs","0.0600000000","USD","Compute Instance","m5.large","2","8 GiB","","us-east-1","Shared","Linux","NA","Used"
+"SKU5","JRTCKXETXF","SKU5.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.00 per Reservation Linux m5.large Instance Hour","Hrs","0.0000000000","USD","Compute Instance","m5.large","2","8 GiB","","us-east-1","Shared","Linux","NA","AllocatedCapacityReservation"
+"SKU6","JRTCKXETXF","SKU6.JRTCKXETXF.6YS6EN2CT7","OnDemand","$6.669 per On Demand Linux x1.16xlarge Instance Hour","Hrs","6.6690000000","USD","Compute Instance","x1.16xlarge","64","976 GiB","","us-east-1","Shared","Linux","NA","Used"
+`
+
+const gcpFixture = `{"skus": [
+  {"description": "E2 Instance Core running in Iowa", "category": {"resourceFamily": "Compute", "usageType": "OnDemand"}, "serviceRegions": ["us-central1"],
+   "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 21811590}}]}}]},
+  {"description": "E2 Instance Ram running in Iowa", "category": {"resourceFamily": "Compute", "usageType": "OnDemand"}, "serviceRegions": ["us-central1"],
+   "pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 2923390}}]}}]},
+  {"description": "Spot Preemptible E2 Instance Core running in Iowa", "category": {"resourceFamily": "Compute", "usageType": "Preemptible"}, "serviceRegions": ["us-central1"],
+   "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 6543000}}]}}]}
+]}
+{"skus": [
+  {"description": "N1 Predefined Instance Core running in Americas", "category": {"resourceFamily": "Compute", "usageType": "OnDemand"}, "serviceRegions": ["us-central1"],
+   "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 31611000}}]}}]},
+  {"description": "N1 Predefined Instance Ram running in Americas", "category": {"resourceFamily": "Compute", "usageType": "OnDemand"}, "serviceRegions": ["us-central1"],
+   "pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 4237000}}]}}]}
+]}`
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		format        string
+		input         string
+		opts          Options
+		wantCPU       float64
+		wantMemory    float64
+		wantInstances int
+		wantDate      string
+		wantErr       string
+	}{
+		{name: "OpenCost custom pricing", format: FormatOpenCost, input: openCostFixture, wantCPU: 0.031611, wantMemory: 0.004237, wantDate: "2026-01-15"},
+		{name: "OpenCost missing RAM", format: FormatOpenCost, input: `{"CPU": "0.03"}`, wantErr: "missing RAM price"},
+		{name: "AWS JSON", format: FormatAWSJSON, input: awsJSONFixture, wantInstances: 2, wantDate: "2025-11-20"},
+		{name: "AWS CSV", format: FormatAWSCSV, input: awsCSVFixture, opts: Options{Region: "us-east-1"}, wantInstances: 2, wantDate: "2025-11-20"},
+		{name: "AWS unknown reference instance", format: FormatAWSJSON, input: awsJSONFixture, opts: Options{ReferenceInstance: "t3.micro"}, wantErr: "reference instance t3.micro not found"},
+		{name: "AWS region filter leaves nothing", format: FormatAWSCSV, input: awsCSVFixture, opts: Options{Region: "eu-west-1"}, wantErr: "no Linux on-demand instance prices found"},
+		{name: "GCP machine family", format: FormatGCPSKUs, input: gcpFixture, opts: Options{MachineFamily: "e2"}, wantCPU: 0.02181159, wantMemory: 0.00292339, wantDate: "2026-01-15"},
+		{name: "GCP needs a machine family", format: FormatGCPSKUs, input: gcpFixture, wantErr: "several machine families (E2, N1)"},
+		{name: "unknown format", format: "azure", input: "{}", wantErr: "unsupported import format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.opts.Now = now
+			rates, err := Import(tt.format, strings.NewReader(tt.input), tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+
+			if tt.wantCPU != 0 && math.Abs(rates.CPUPerCorePerHour-tt.wantCPU) > 1e-9 {
+				t.Errorf("CPU rate: got %.8f, want %.8f", rates.CPUPerCorePerHour, tt.wantCPU)
+			}
+			if tt.wantMemory != 0 && math.Abs(rates.MemoryPerGBPerHour-tt.wantMemory) > 1e-9 {
+				t.Errorf("memory rate: got %.8f, want %.8f", rates.MemoryPerGBPerHour, tt.wantMemory)
+			}
+			if tt.format == FormatOpenCost {
+				if got := rates.ExtendedResources["nvidia.com/gpu"]; got != 0.95 {
+					t.Errorf("GPU rate: got %v, want 0.95", got)
+				}
+				if got := rates.StoragePerGBPerMonth; math.Abs(got-0.04) > 1e-6 {
+					t.Errorf("storage rate: got %.6f, want 0.040000", got)
+				}
+			}
+			if len(rates.Instances) != tt.wantInstances {
+				t.Errorf("instances: got %d, want %d", len(rates.Instances), tt.wantInstances)
+			}
+			if got := rates.LastUpdated.Format(calculator.DateLayout); got != tt.wantDate {
+				t.Errorf("last_updated: got %q, want %q", got, tt.wantDate)
+			}
+
+			// Every import must produce a file that kcost accepts
+			var buf bytes.Buffer
+			if err := Write(&buf, rates, "test header"); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if errs := calculator.ValidateRates(buf.Bytes()); len(errs) > 0 {
+				t.Errorf("written rates are invalid: %v\n%s", errs, buf.String())
+			}
+		})
+	}
+}
+
+func TestImportAWSInstances(t *testing.T) {
+	t.Parallel()
+	rates, err := Import(FormatAWSJSON, strings.NewReader(awsJSONFixture), Options{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	want := []calculator.InstancePrice{
+		{Provider: "aws", Region: "us-east-1", InstanceType: "m5.large", VCPU: 2, MemoryGB: 8, HourlyPrice: 0.096},
+		{Provider: "aws", Region: "us-east-1", InstanceType: "p3.2xlarge", VCPU: 8, MemoryGB: 61, GPU: 1, HourlyPrice: 3.06},
+	}
+	if len(rates.Instances) != len(want) {
+		t.Fatalf("instances: got %d, want %d", len(rates.Instances), len(want))
+	}
+	for i, w := range want {
+		if rates.Instances[i] != w {
+			t.Errorf("instance %d: got %+v, want %+v", i, rates.Instances[i], w)
+		}
+	}
+
+	// The flat rates price the reference instance at its catalog price
+	if got := 2*rates.CPUPerCorePerHour + 8*rates.MemoryPerGBPerHour; math.Abs(got-0.096) > 1e-9 {
+		t.Errorf("m5.large at flat rates: got %.6f, want 0.096000", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	rates := calculator.Rates{CPUPerCorePerHour: 0.05, MemoryPerGBPerHour: 0.005}
+	tests := []struct {
+		name       string
+		header     string
+		wantPrefix string
+	}{
+		{name: "header", header: "Imported from aws-json\nregion us-east-1\n", wantPrefix: "# Imported from aws-json\n# region us-east-1\n\n"},
+		{name: "no header", header: "", wantPrefix: "cpu_per_core_per_hour: 0.05\n"},
+		{name: "blank header", header: " \n", wantPrefix: "cpu_per_core_per_hour: 0.05\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := Write(&buf, rates, tt.header); err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.wantPrefix) {
+				t.Errorf("got %q, want prefix %q", buf.String(), tt.wantPrefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package recommend
+
+import (
+	"math"
+	"sort"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/usage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Options control how observed usage is turned into recommended requests
+type Options struct {
+	// Percentile (0-100) of the observed samples that requests are sized for
+	Percentile float64
+	// Headroom is added on top of the percentile as a fraction, e.g. 0.15 for 15%
+	Headroom float64
+	// MinCPU and MinMemory floor the recommendations for idle containers
+	MinCPU    resource.Quantity
+	MinMemory resource.Quantity
+}
+
+// DefaultOptions size requests for the 95th percentile plus 15% headroom
+func DefaultOptions() Options {
+	return Options{
+		Percentile: 95,
+		Headroom:   0.15,
+		MinCPU:     resource.MustParse("10m"),
+		MinMemory:  resource.MustParse("32Mi"),
+	}
+}
+
+// Recommendation proposes requests and limits for one container and prices the
+// container at its current and recommended requests
+type Recommendation struct {
+	Namespace string
+	Pod       string
+	Container string
+	// Samples is the number of usage samples the recommendation is based on, the
+	// smaller of the CPU and memory sample counts
+	Samples int
+
+	CurrentCPU         resource.Quantity
+	CurrentMemory      resource.Quantity
+	CurrentCPULimit    resource.Quantity
+	CurrentMemoryLimit resource.Quantity
+
+	CPU         resource.Quantity
+	Memory      resource.Quantity
+	CPULimit    resource.Quantity
+	MemoryLimit resource.Quantity
+
+	CurrentMonthly     float64
+	RecommendedMonthly float64
+}
+
+// MonthlySaving is the projected monthly saving; negative when the container is
+// under-provisioned and the recommendation raises its requests
+func (r Recommendation) MonthlySaving() float64 {
+	return r.CurrentMonthly - r.RecommendedMonthly
+}
+
+// Recommend proposes requests for every app and sidecar container with both CPU
+// and memory usage samples; CPU and memory may come from separate queries, and a
+// container missing either is skipped rather than sized from no data. Limits keep their current ratio to the request; containers without
+// limits get none. Results are ordered by saving (largest first).
+func Recommend(pods []corev1.Pod, series map[usage.ContainerKey]*usage.Series, opts Options, ratesFor func(corev1.Pod) calculator.Rates) []Recommendation {
+	var recs []Recommendation
+	for _, pod := range pods {
+		rates := ratesFor(pod)
+		for _, c := range runningContainers(pod) {
+			s, ok := series[usage.ContainerKey{Namespace: pod.Namespace, Pod: pod.Name, Container: c.Name}]
+			if !ok || len(s.CPU) == 0 || len(s.Memory) == 0 {
+				continue
+			}
+
+			rec := Recommendation{
+				Namespace:          pod.Namespace,
+				Pod:                pod.Name,
+				Container:          c.Name,
+				Samples:            min(len(s.CPU), len(s.Memory)),
+				CurrentCPU:         c.Resources.Requests[corev1.ResourceCPU],
+				CurrentMemory:      c.Resources.Requests[corev1.ResourceMemory],
+				CurrentCPULimit:    c.Resources.Limits[corev1.ResourceCPU],
+				CurrentMemoryLimit: c.Resources.Limits[corev1.ResourceMemory],
+			}
+
+			headroom := 1 + opts.Headroom
+			rec.CPU = maxQuantity(millicores(usage.Percentile(s.CPU, opts.Percentile)*headroom), opts.MinCPU)
+			rec.Memory = maxQuantity(mebibytes(usage.Percentile(s.Memory, opts.Percentile)*headroom), opts.MinMemory)
+			rec.CPULimit = scaleLimit(rec.CurrentCPULimit, rec.CurrentCPU, rec.CPU, millicores)
+			rec.MemoryLimit = scaleLimit(rec.CurrentMemoryLimit, rec.CurrentMemory, rec.Memory, mebibytes)
+
+			rec.CurrentMonthly = calculator.CalculatePodCost(pod.Name, pod.Namespace, rec.CurrentCPU, rec.CurrentMemory, rates).Monthly.TotalCost
+			rec.RecommendedMonthly = calculator.CalculatePodCost(pod.Name, pod.Namespace, rec.CPU, rec.Memory, rates).Monthly.TotalCost
+			recs = append(recs, rec)
+		}
+	}
+
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].MonthlySaving() > recs[j].MonthlySaving()
+	})
+	return recs
+}
+
+// TotalMonthlySaving sums the projected savings of the recommendations
+func TotalMonthlySaving(recs []Recommendation) float64 {
+	var total float64
+	for _, r := range recs {
+		total += r.MonthlySaving()
+	}
+	return total
+}
+
+// runningContainers returns the containers metrics-server reports on: app
+// containers and sidecars (restartable init containers)
+func runningContainers(pod corev1.Pod) []corev1.Container {
+	var containers []corev1.Container
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			containers = append(containers, c)
+		}
+	}
+	return append(containers, pod.Spec.Containers...)
+}
+
+// scaleLimit keeps a limit's ratio to its request. Without a current request the
+// limit is kept as is, raised to the new request if needed.
+func scaleLimit(limit, request, recommended resource.Quantity, round func(float64) resource.Quantity) resource.Quantity {
+	if limit.IsZero() {
+		return resource.Quantity{}
+	}
+	if request.IsZero() {
+		return maxQuantity(limit, recommended)
+	}
+	ratio := limit.AsApproximateFloat64() / request.AsApproximateFloat64()
+	return maxQuantity(round(recommended.AsApproximateFloat64()*ratio), recommended)
+}
+
+// millicores rounds cores up to whole millicores
+func millicores(cores float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Ceil(cores*1000)), resource.DecimalSI)
+}
+
+// mebibytes rounds bytes up to whole MiB
+func mebibytes(bytes float64) resource.Quantity {
+	return *resource.NewQuantity(int64(math.Ceil(bytes/(1<<20)))<<20, resource.BinarySI)
+}
+
+func maxQuantity(a, b resource.Quantity) resource.Quantity {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,119 @@
+package recommend
+
+import (
+	"math"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/usage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecommend(t *testing.T) {
+	t.Parallel()
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-7d9f", Namespace: "shop"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("1"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+						Limits: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("2"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+				{
+					Name: "idle",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+					},
+				},
+				{Name: "unsampled"},
+				{
+					Name: "cpu-only",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("4Gi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	series := map[usage.ContainerKey]*usage.Series{
+		{Namespace: "shop", Pod: "api-7d9f", Container: "app"}: {
+			CPU:    []float64{0.1, 0.2, 0.15, 0.2},
+			Memory: []float64{200 << 20, 256 << 20, 240 << 20},
+		},
+		{Namespace: "shop", Pod: "api-7d9f", Container: "idle"}: {
+			CPU:    []float64{0},
+			Memory: []float64{1 << 20},
+		},
+		// CPU and memory come from separate queries; memory may be missing
+		{Namespace: "shop", Pod: "api-7d9f", Container: "cpu-only"}: {
+			CPU: []float64{0.1, 0.1},
+		},
+	}
+	rates := calculator.Rates{CPUPerCorePerHour: 0.04, MemoryPerGBPerHour: 0.005}
+
+	opts := DefaultOptions()
+	opts.Headroom = 0.25
+	recs := Recommend([]corev1.Pod{pod}, series, opts, func(corev1.Pod) calculator.Rates { return rates })
+
+	tests := []struct {
+		container       string
+		wantCPU         string
+		wantMemory      string
+		wantCPULimit    string
+		wantMemoryLimit string
+	}{
+		// p95 of four CPU and three memory samples is the maximum: 200m and 256Mi, plus 25%
+		{container: "app", wantCPU: "250m", wantMemory: "320Mi", wantCPULimit: "500m", wantMemoryLimit: "320Mi"},
+		// floored at the minimums, and no limits since there were none
+		{container: "idle", wantCPU: "10m", wantMemory: "32Mi", wantCPULimit: "0", wantMemoryLimit: "0"},
+	}
+	if len(recs) != len(tests) {
+		t.Fatalf("recommendations: got %d, want %d (containers without CPU or memory samples are skipped)", len(recs), len(tests))
+	}
+	for i, tt := range tests {
+		r := recs[i]
+		if r.Container != tt.container {
+			t.Fatalf("recommendation %d: got container %q, want %q", i, r.Container, tt.container)
+		}
+		for _, q := range []struct {
+			what string
+			got  resource.Quantity
+			want string
+		}{
+			{"CPU", r.CPU, tt.wantCPU},
+			{"memory", r.Memory, tt.wantMemory},
+			{"CPU limit", r.CPULimit, tt.wantCPULimit},
+			{"memory limit", r.MemoryLimit, tt.wantMemoryLimit},
+		} {
+			if q.got.Cmp(resource.MustParse(q.want)) != 0 {
+				t.Errorf("%s %s: got %s, want %s", tt.container, q.what, q.got.String(), q.want)
+			}
+		}
+	}
+
+	app := recs[0]
+	if app.Samples != 3 {
+		t.Errorf("app samples: got %d, want 3", app.Samples)
+	}
+	wantSaving := ((1-0.25)*0.04 + (1-320.0/1024)*0.005) * calculator.HoursPerMonth
+	if math.Abs(app.MonthlySaving()-wantSaving) > 0.0001 {
+		t.Errorf("app monthly saving: got %.4f, want %.4f", app.MonthlySaving(), wantSaving)
+	}
+	if got := TotalMonthlySaving(recs); math.Abs(got-(app.MonthlySaving()+recs[1].MonthlySaving())) > 0.0001 {
+		t.Errorf("total monthly saving: got %.4f", got)
+	}
+}
@@ -2,24 +2,352 @@ package reporter
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"sort"
+	"strings"
 	"text/tabwriter"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 )
 
-// PrintCostTable displays pod costs in a formatted table
-func PrintCostTable(costs []calculator.PodCost) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	defer w.Flush()
+// PrintCostTable displays pod costs in a formatted table.
+// A NAMESPACE column is added when the pods span more than one namespace, a
+// STORAGE column when any pod mounts priced volumes, a monthly cost column
+// for each extended resource (e.g. GPUs) any pod requests, and requested vs
+// used CPU and memory with the usage cost and efficiency when usage was sampled.
+func PrintCostTable(w io.Writer, costs []calculator.PodCost) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
 
-	fmt.Fprintln(w, "POD\tHOURLY\tDAILY\tMONTHLY")
+	showNamespace := spansNamespaces(costs)
+	showStorage := hasStorage(costs)
+	showRule := false
 	for _, c := range costs {
-		fmt.Fprintf(w, "%s\t$%.4f\t$%.2f\t$%.2f\n",
+		showRule = showRule || c.RequestRule != ""
+	}
+	showUsage := hasUsage(costs)
+	extended := extendedNames(costs, func(c calculator.PodCost) map[string]float64 { return c.Monthly.Extended })
+	if showNamespace {
+		fmt.Fprint(tw, "NAMESPACE\t")
+	}
+	fmt.Fprint(tw, "POD\tHOURLY\tDAILY\tMONTHLY")
+	if showStorage {
+		fmt.Fprint(tw, "\tSTORAGE MONTHLY")
+	}
+	fmt.Fprint(tw, extendedHeader(extended))
+	if showUsage {
+		fmt.Fprint(tw, "\tCPU REQ\tCPU USED\tMEM REQ\tMEM USED\tUSAGE MONTHLY\tEFFICIENCY")
+	}
+	if showRule {
+		fmt.Fprint(tw, "\tREQUEST RULE")
+	}
+	fmt.Fprintln(tw)
+	for _, c := range costs {
+		if showNamespace {
+			fmt.Fprintf(tw, "%s\t", c.Namespace)
+		}
+		fmt.Fprintf(tw, "%s\t$%.4f\t$%.2f\t$%.2f",
+			c.Name,
+			c.Hourly.TotalCost,
+			c.Daily.TotalCost,
+			c.Monthly.TotalCost,
+		)
+		if showStorage {
+			fmt.Fprintf(tw, "\t$%.2f", c.Monthly.StorageCost)
+		}
+		fmt.Fprint(tw, extendedCells(extended, c.Monthly))
+		if showUsage {
+			fmt.Fprint(tw, usageCells(c))
+		}
+		if showRule {
+			fmt.Fprintf(tw, "\t%s", requestRuleCell(c.RequestRule))
+		}
+		fmt.Fprintln(tw)
+	}
+}
+
+// usageCells renders requested and used CPU and memory, the usage cost and the
+// efficiency of a pod, "-" throughout when metrics-server had no sample for it
+func usageCells(c calculator.PodCost) string {
+	if c.Usage == nil {
+		return strings.Repeat("\t-", 6)
+	}
+	u := c.Usage
+	efficiency, ok := c.Efficiency()
+	return fmt.Sprintf("\t%s\t%s\t%s\t%s\t$%.2f\t%s",
+		formatCores(u.CPURequestCores),
+		formatCores(u.CPUUsageCores),
+		formatGB(u.MemoryRequestGB),
+		formatGB(u.MemoryUsageGB),
+		u.Monthly.TotalCost,
+		formatEfficiency(efficiency, ok),
+	)
+}
+
+// formatCores renders cores as millicores, matching kubectl top
+func formatCores(cores float64) string {
+	return fmt.Sprintf("%.0fm", cores*1000)
+}
+
+// formatGB renders GB (GiB) as MiB, matching kubectl top
+func formatGB(gb float64) string {
+	return fmt.Sprintf("%.0fMi", gb*1024)
+}
+
+func formatEfficiency(efficiency float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", efficiency*100)
+}
+
+// PrintOrphanedClaims lists bound PVCs that no pod mounts
+func PrintOrphanedClaims(w io.Writer, orphans []calculator.ClaimCost) {
+	if len(orphans) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nOrphaned Storage (PVCs not mounted by any pod):\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "NAMESPACE\tCLAIM\tSTORAGE CLASS\tSIZE (GB)\tMONTHLY")
+	for _, o := range orphans {
+		storageClass := o.StorageClass
+		if storageClass == "" {
+			storageClass = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\t$%.2f\n", o.Namespace, o.Name, storageClass, o.CapacityGB, o.Monthly)
+	}
+}
+
+// PrintWorkloadCostTable displays workload costs in a formatted table, with the
+// same optional STORAGE, extended resource and REQUEST RULE columns as PrintCostTable
+func PrintWorkloadCostTable(w io.Writer, costs []calculator.WorkloadCost) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	extended := extendedNames(costs, func(c calculator.WorkloadCost) map[string]float64 { return c.Monthly.Extended })
+	showRule, showStorage := false, false
+	for _, c := range costs {
+		showRule = showRule || c.RequestRule != ""
+		showStorage = showStorage || c.Hourly.StorageCost > 0
+	}
+	fmt.Fprint(tw, "NAMESPACE\tKIND\tNAME\tREPLICAS\tHOURLY\tDAILY\tMONTHLY")
+	if showStorage {
+		fmt.Fprint(tw, "\tSTORAGE MONTHLY")
+	}
+	fmt.Fprint(tw, extendedHeader(extended))
+	if showRule {
+		fmt.Fprint(tw, "\tREQUEST RULE")
+	}
+	fmt.Fprintln(tw)
+	for _, c := range costs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t$%.4f\t$%.2f\t$%.2f",
+			c.Namespace,
+			c.Kind,
 			c.Name,
+			c.Replicas,
 			c.Hourly.TotalCost,
 			c.Daily.TotalCost,
 			c.Monthly.TotalCost,
 		)
+		if showStorage {
+			fmt.Fprintf(tw, "\t$%.2f", c.Monthly.StorageCost)
+		}
+		fmt.Fprint(tw, extendedCells(extended, c.Monthly))
+		if showRule {
+			fmt.Fprintf(tw, "\t%s", requestRuleCell(c.RequestRule))
+		}
+		fmt.Fprintln(tw)
+	}
+}
+
+// PrintNamespaceSummary displays per-namespace totals followed by the grand total.
+// A single namespace is shown as a short summary block instead of a table. Shares
+// of idle node capacity, when distributed, get their own line or column, as do the
+// direct cost and the allocated share of shared namespaces' cost.
+func PrintNamespaceSummary(w io.Writer, summaries []analyzer.NamespaceSummary) {
+	total := analyzer.Total(summaries)
+
+	if len(summaries) <= 1 {
+		fmt.Fprintf(w, "\nNamespace Summary:\n")
+		fmt.Fprintf(w, "  Total Pods: %d\n", total.TotalPods)
+		if total.OrphanedClaims > 0 {
+			fmt.Fprintf(w, "  Orphaned Storage: %d PVCs, $%.2f/month\n", total.OrphanedClaims, total.OrphanedMonthlyCost)
+		}
+		fmt.Fprintf(w, "  Estimated Monthly Cost: $%.2f\n", total.MonthlyCost)
+		if efficiency, ok := total.Efficiency(); ok {
+			fmt.Fprintf(w, "  Usage Monthly Cost: $%.2f (%.1f%% of requested CPU and memory)\n", total.UsageMonthlyCost, efficiency*100)
+		}
+		if total.IdleMonthlyCost > 0 {
+			fmt.Fprintf(w, "  Idle Capacity Share: $%.2f/month (included above)\n", total.IdleMonthlyCost)
+		}
+		if total.SharedMonthlyCost > 0 {
+			fmt.Fprintf(w, "  Direct: $%.2f/month, Shared Allocation: $%.2f/month (included above)\n", total.DirectMonthlyCost(), total.SharedMonthlyCost)
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "\nNamespace Summary:\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	showOrphaned := total.OrphanedClaims > 0
+	_, showUsage := total.Efficiency()
+	showIdle := total.IdleMonthlyCost > 0
+	showShared := total.SharedMonthlyCost > 0
+	fmt.Fprint(tw, "NAMESPACE\tPODS\tHOURLY\tDAILY\tMONTHLY")
+	if showOrphaned {
+		fmt.Fprint(tw, "\tORPHANED STORAGE")
+	}
+	if showIdle {
+		fmt.Fprint(tw, "\tIDLE SHARE")
+	}
+	if showShared {
+		fmt.Fprint(tw, "\tDIRECT\tSHARED")
+	}
+	if showUsage {
+		fmt.Fprint(tw, "\tUSAGE MONTHLY\tEFFICIENCY")
+	}
+	fmt.Fprintln(tw)
+	row := func(name string, s analyzer.NamespaceSummary) {
+		fmt.Fprintf(tw, "%s\t%d\t$%.4f\t$%.2f\t$%.2f", name, s.TotalPods, s.HourlyCost, s.DailyCost, s.MonthlyCost)
+		if showOrphaned {
+			fmt.Fprintf(tw, "\t$%.2f", s.OrphanedMonthlyCost)
+		}
+		if showIdle {
+			fmt.Fprintf(tw, "\t$%.2f", s.IdleMonthlyCost)
+		}
+		if showShared {
+			fmt.Fprintf(tw, "\t$%.2f\t$%.2f", s.DirectMonthlyCost(), s.SharedMonthlyCost)
+		}
+		if showUsage {
+			efficiency, ok := s.Efficiency()
+			fmt.Fprintf(tw, "\t$%.2f\t%s", s.UsageMonthlyCost, formatEfficiency(efficiency, ok))
+		}
+		fmt.Fprintln(tw)
+	}
+	for _, s := range summaries {
+		row(s.Namespace, s)
+	}
+	row("TOTAL", total)
+}
+
+// PrintSharedNamespaces lists the shared namespaces whose cost was allocated to the
+// others, or says why it could not be
+func PrintSharedNamespaces(w io.Writer, shared *analyzer.Shared) {
+	if shared == nil {
+		return
+	}
+	if len(shared.Pool) == 0 {
+		fmt.Fprintf(w, "\nShared Costs: no pods found in the shared namespaces\n")
+		return
+	}
+
+	total := analyzer.Total(shared.Pool)
+	if len(shared.Shares) == 0 {
+		fmt.Fprintf(w, "\nShared Costs: $%.2f/month could not be allocated; no namespace qualifies for a %s share\n", total.MonthlyCost, shared.Strategy)
+		return
+	}
+
+	fmt.Fprintf(w, "\nShared Costs (allocated %s across %d namespaces):\n", describeStrategy(shared.Strategy), len(shared.Shares))
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "NAMESPACE\tPODS\tMONTHLY")
+	for _, s := range shared.Pool {
+		fmt.Fprintf(tw, "%s\t%d\t$%.2f\n", s.Namespace, s.TotalPods, s.MonthlyCost)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t$%.2f\n", total.TotalPods, total.MonthlyCost)
+}
+
+func describeStrategy(strategy string) string {
+	switch strategy {
+	case analyzer.ShareEven:
+		return "evenly"
+	case analyzer.ShareProportional:
+		return "in proportion to cost"
+	default:
+		return "by weight"
+	}
+}
+
+// requestRuleCell shows the default rule (sum of app containers) as "containers",
+// in both resource and cost tables
+func requestRuleCell(rule string) string {
+	if rule == "" {
+		return "containers"
+	}
+	return rule
+}
+
+// hasStorage reports whether any pod is charged for persistent volumes
+func hasStorage(costs []calculator.PodCost) bool {
+	for _, c := range costs {
+		if c.Hourly.StorageCost > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUsage reports whether any pod has a usage sample
+func hasUsage(costs []calculator.PodCost) bool {
+	for _, c := range costs {
+		if c.Usage != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// spansNamespaces reports whether the costs cover more than one namespace
+func spansNamespaces(costs []calculator.PodCost) bool {
+	for _, c := range costs {
+		if c.Namespace != costs[0].Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// extendedNames returns the sorted names of the extended resources in any row's
+// extended map
+func extendedNames[T, V any](rows []T, extended func(T) map[string]V) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range rows {
+		for name := range extended(row) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// extendedHeader renders one monthly cost column header per extended resource
+func extendedHeader(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "\t%s MONTHLY", strings.ToUpper(name))
+	}
+	return b.String()
+}
+
+// extendedCells renders the monthly cost of each extended resource, "-" when not requested
+func extendedCells(names []string, monthly calculator.ResourceCost) string {
+	var b strings.Builder
+	for _, name := range names {
+		if cost, ok := monthly.Extended[name]; ok {
+			fmt.Fprintf(&b, "\t$%.2f", cost)
+		} else {
+			b.WriteString("\t-")
+		}
 	}
+	return b.String()
 }
@@ -3,51 +3,360 @@ package reporter
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
+	"io"
+	"strconv"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 )
 
-// PrintCostCSV outputs pod costs in CSV format
-func PrintCostCSV(costs []calculator.PodCost) error {
-	w := csv.NewWriter(os.Stdout)
-	defer w.Flush()
+// CSV row types distinguish pod and workload rows from the summary rows that follow them
+const (
+	csvRowPod       = "pod"
+	csvRowWorkload  = "workload"
+	csvRowNamespace = "namespace"
+	csvRowTotal     = "total"
+	csvRowOrphan    = "orphaned_claim"
+	// csvRowSharedNamespace is a namespace whose cost was allocated to the others
+	csvRowSharedNamespace = "shared_namespace"
+)
+
+var csvCostHeader = []string{
+	"hourly_cpu_cost",
+	"hourly_memory_cost",
+	"hourly_total_cost",
+	"daily_cpu_cost",
+	"daily_memory_cost",
+	"daily_total_cost",
+	"monthly_cpu_cost",
+	"monthly_memory_cost",
+	"monthly_total_cost",
+}
+
+// PrintCostCSV outputs a report's pod costs in CSV format.
+// Pod rows are followed by one row per orphaned claim (claim name in pod_name,
+// pod_count 0), one row per namespace and a grand total row; summary rows leave
+// pod_name empty and report the pod count in pod_count.
+// Storage columns are added when any storage is priced, hourly, daily and
+// monthly columns for each extended resource any pod requests, and requested vs
+// used CPU and memory, usage cost and efficiency when usage was sampled.
+// With idle capacity, one idle_node row per node follows the orphaned claims (node
+// name in pod_name, the idle remainder as its cost); distributed shares are included
+// in the namespace totals and broken out in a monthly_idle_cost column. With shared
+// costs, one shared_namespace row per shared namespace precedes the namespace rows,
+// whose totals include their allocated share, broken out with the direct cost in
+// monthly_direct_cost and monthly_shared_cost columns.
+func PrintCostCSV(w io.Writer, r Report) error {
+	costs, orphans, alloc := r.Pods, r.Orphans, r.Allocations
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	showStorage := hasStorage(costs) || len(orphans) > 0
+	showUsage := hasUsage(costs)
+	extended := extendedNames(costs, func(c calculator.PodCost) map[string]float64 { return c.Hourly.Extended })
 
 	// Write header
-	header := []string{
-		"pod_name",
-		"hourly_cpu_cost",
-		"hourly_memory_cost",
-		"hourly_total_cost",
-		"daily_cpu_cost",
-		"daily_memory_cost",
-		"daily_total_cost",
-		"monthly_cpu_cost",
-		"monthly_memory_cost",
-		"monthly_total_cost",
-	}
-	if err := w.Write(header); err != nil {
+	header := append([]string{"row_type", "namespace", "pod_name", "pod_count"}, csvCostHeader...)
+	if showStorage {
+		header = append(header, csvStorageHeader...)
+	}
+	header = append(header, extendedCSVHeader(extended)...)
+	if showUsage {
+		header = append(header, csvUsageHeader...)
+	}
+	summary := csvSummaryColumns{
+		usage:  showUsage,
+		idle:   alloc.Idle != nil && alloc.Idle.Shares != nil,
+		shared: alloc.Shared != nil,
+	}
+	header = append(header, summary.header()...)
+	if err := cw.Write(header); err != nil {
 		return fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
+	// Columns after the standard cost columns on rows that leave them empty
+	extraColumns := 3 * len(extended)
+	if showStorage {
+		extraColumns += len(csvStorageHeader)
+	}
+	trailingColumns := len(summary.header())
+	// Idle and shared cost columns, which pod rows leave empty
+	allocColumns := trailingColumns
+	if showUsage {
+		allocColumns -= len(csvUsageHeader)
+	}
+
 	// Write data rows
 	for _, c := range costs {
-		row := []string{
-			c.Name,
-			fmt.Sprintf("%.4f", c.Hourly.CPUCost),
-			fmt.Sprintf("%.4f", c.Hourly.MemoryCost),
-			fmt.Sprintf("%.4f", c.Hourly.TotalCost),
-			fmt.Sprintf("%.2f", c.Daily.CPUCost),
-			fmt.Sprintf("%.2f", c.Daily.MemoryCost),
-			fmt.Sprintf("%.2f", c.Daily.TotalCost),
-			fmt.Sprintf("%.2f", c.Monthly.CPUCost),
-			fmt.Sprintf("%.2f", c.Monthly.MemoryCost),
-			fmt.Sprintf("%.2f", c.Monthly.TotalCost),
-		}
-		if err := w.Write(row); err != nil {
+		row := append([]string{csvRowPod, c.Namespace, c.Name, "1"}, costCSVColumns(c.Hourly, c.Daily, c.Monthly)...)
+		if showStorage {
+			row = append(row, storageCSVColumns(c.Hourly.StorageCost, c.Daily.StorageCost, c.Monthly.StorageCost)...)
+		}
+		row = append(row, extendedCSVColumns(extended, c.Hourly, c.Daily, c.Monthly)...)
+		if showUsage {
+			row = append(row, usageCSVColumns(c)...)
+		}
+		row = append(row, make([]string, allocColumns)...)
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	for _, o := range orphans {
+		row := orphanCSVRow(o, []string{o.Name}, 3*len(extended))
+		row = append(row, make([]string, trailingColumns)...)
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	if alloc.Idle != nil {
+		for _, n := range alloc.Idle.Nodes {
+			row := append([]string{csvRowIdleNode, "", n.Name, strconv.Itoa(n.Pods)}, costCSVColumns(n.IdleHourly, n.IdleDaily, n.IdleMonthly)...)
+			row = append(row, make([]string, extraColumns+trailingColumns)...)
+			if err := cw.Write(row); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+		}
+	}
+
+	var pool []analyzer.NamespaceSummary
+	if alloc.Shared != nil && len(alloc.Shared.Shares) > 0 {
+		pool = alloc.Shared.Pool
+	}
+	return writeSummaryCSVRows(cw, pool, r.Summaries, 1, extraColumns, summary)
+}
+
+// PrintWorkloadCostCSV outputs a report's workload costs in CSV format.
+// Workload rows are followed by one row per orphaned claim (kind
+// PersistentVolumeClaim, replicas 0), one row per namespace and a grand total row;
+// summary rows leave kind and workload_name empty and report pods in replicas.
+// Storage and extended resource columns are added as for PrintCostCSV.
+func PrintWorkloadCostCSV(w io.Writer, r Report) error {
+	costs, orphans := r.Workloads, r.Orphans
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	showStorage := len(orphans) > 0
+	for _, c := range costs {
+		showStorage = showStorage || c.Hourly.StorageCost > 0
+	}
+	extended := extendedNames(costs, func(c calculator.WorkloadCost) map[string]float64 { return c.Hourly.Extended })
+
+	header := append([]string{"row_type", "namespace", "kind", "workload_name", "replicas"}, csvCostHeader...)
+	if showStorage {
+		header = append(header, csvStorageHeader...)
+	}
+	header = append(header, extendedCSVHeader(extended)...)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, c := range costs {
+		row := append([]string{csvRowWorkload, c.Namespace, c.Kind, c.Name, strconv.Itoa(int(c.Replicas))},
+			costCSVColumns(c.Hourly, c.Daily, c.Monthly)...)
+		if showStorage {
+			row = append(row, storageCSVColumns(c.Hourly.StorageCost, c.Daily.StorageCost, c.Monthly.StorageCost)...)
+		}
+		row = append(row, extendedCSVColumns(extended, c.Hourly, c.Daily, c.Monthly)...)
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	for _, o := range orphans {
+		if err := cw.Write(orphanCSVRow(o, []string{csvKindClaim, o.Name}, 3*len(extended))); err != nil {
 			return fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
+	extraColumns := 3 * len(extended)
+	if showStorage {
+		extraColumns += len(csvStorageHeader)
+	}
+	return writeSummaryCSVRows(cw, nil, r.Summaries, 2, extraColumns, csvSummaryColumns{})
+}
+
+// csvKindClaim is the kind reported for orphaned claims in workload CSV output
+const csvKindClaim = "PersistentVolumeClaim"
+
+// orphanCSVRow renders an orphaned claim with the given identifying columns after
+// the namespace, a zero pod count, its cost as the total and storage cost, and
+// extendedColumns empty extended resource columns
+func orphanCSVRow(o calculator.ClaimCost, names []string, extendedColumns int) []string {
+	row := append([]string{csvRowOrphan, o.Namespace}, names...)
+	row = append(row, "0",
+		"", "", fmt.Sprintf("%.4f", o.Hourly),
+		"", "", fmt.Sprintf("%.2f", o.Daily),
+		"", "", fmt.Sprintf("%.2f", o.Monthly),
+	)
+	row = append(row, storageCSVColumns(o.Hourly, o.Daily, o.Monthly)...)
+	return append(row, make([]string, extendedColumns)...)
+}
+
+func costCSVColumns(hourly, daily, monthly calculator.ResourceCost) []string {
+	return []string{
+		fmt.Sprintf("%.4f", hourly.CPUCost),
+		fmt.Sprintf("%.4f", hourly.MemoryCost),
+		fmt.Sprintf("%.4f", hourly.TotalCost),
+		fmt.Sprintf("%.2f", daily.CPUCost),
+		fmt.Sprintf("%.2f", daily.MemoryCost),
+		fmt.Sprintf("%.2f", daily.TotalCost),
+		fmt.Sprintf("%.2f", monthly.CPUCost),
+		fmt.Sprintf("%.2f", monthly.MemoryCost),
+		fmt.Sprintf("%.2f", monthly.TotalCost),
+	}
+}
+
+// extendedCSVHeader names the hourly, daily and monthly cost columns of each extended resource
+func extendedCSVHeader(names []string) []string {
+	header := make([]string, 0, 3*len(names))
+	for _, name := range names {
+		header = append(header, "hourly_"+name+"_cost", "daily_"+name+"_cost", "monthly_"+name+"_cost")
+	}
+	return header
+}
+
+func extendedCSVColumns(names []string, hourly, daily, monthly calculator.ResourceCost) []string {
+	columns := make([]string, 0, 3*len(names))
+	for _, name := range names {
+		columns = append(columns,
+			fmt.Sprintf("%.4f", hourly.Extended[name]),
+			fmt.Sprintf("%.2f", daily.Extended[name]),
+			fmt.Sprintf("%.2f", monthly.Extended[name]),
+		)
+	}
+	return columns
+}
+
+var csvStorageHeader = []string{
+	"hourly_storage_cost",
+	"daily_storage_cost",
+	"monthly_storage_cost",
+}
+
+func storageCSVColumns(hourly, daily, monthly float64) []string {
+	return []string{
+		fmt.Sprintf("%.4f", hourly),
+		fmt.Sprintf("%.2f", daily),
+		fmt.Sprintf("%.2f", monthly),
+	}
+}
+
+var csvUsageHeader = []string{
+	"cpu_request_cores",
+	"cpu_usage_cores",
+	"memory_request_gb",
+	"memory_usage_gb",
+	"monthly_usage_cost",
+	"efficiency",
+}
+
+// usageCSVColumns leaves the usage columns empty for pods without a sample
+func usageCSVColumns(c calculator.PodCost) []string {
+	if c.Usage == nil {
+		return make([]string, len(csvUsageHeader))
+	}
+	efficiency, ok := c.Efficiency()
+	return []string{
+		fmt.Sprintf("%.3f", c.Usage.CPURequestCores),
+		fmt.Sprintf("%.3f", c.Usage.CPUUsageCores),
+		fmt.Sprintf("%.3f", c.Usage.MemoryRequestGB),
+		fmt.Sprintf("%.3f", c.Usage.MemoryUsageGB),
+		fmt.Sprintf("%.2f", c.Usage.Monthly.TotalCost),
+		csvEfficiency(efficiency, ok),
+	}
+}
+
+// csvAmount renders a monthly amount, or an empty cell when ok is false
+func csvAmount(amount float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func csvEfficiency(efficiency float64, ok bool) string {
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.4f", efficiency)
+}
+
+// csvSummaryColumns selects the optional columns that summary rows fill in, after
+// the storage and extended resource columns
+type csvSummaryColumns struct {
+	usage  bool
+	idle   bool
+	shared bool
+}
+
+func (c csvSummaryColumns) header() []string {
+	var header []string
+	if c.usage {
+		header = append(header, csvUsageHeader...)
+	}
+	if c.idle {
+		header = append(header, "monthly_idle_cost")
+	}
+	if c.shared {
+		header = append(header, "monthly_direct_cost", "monthly_shared_cost")
+	}
+	return header
+}
+
+// writeSummaryCSVRows writes shared namespace, namespace and total rows. nameColumns
+// is the number of identifying columns between namespace and the pod count, which
+// stay empty. CPU, memory, storage and extended resource splits are only tracked per
+// row, so summary rows leave them empty; extraColumns is the number of storage and
+// extended resource columns after the standard cost columns. With usage, summary
+// rows fill in the usage cost and efficiency and leave the per-pod quantities empty.
+// Shared namespace rows leave the idle and shared cost columns empty.
+func writeSummaryCSVRows(w *csv.Writer, pool, summaries []analyzer.NamespaceSummary, nameColumns, extraColumns int, columns csvSummaryColumns) error {
+	row := func(rowType string, s analyzer.NamespaceSummary) []string {
+		r := append(summaryCSVRow(rowType, s, nameColumns), make([]string, extraColumns)...)
+		if columns.usage {
+			efficiency, ok := s.Efficiency()
+			r = append(r, "", "", "", "", fmt.Sprintf("%.2f", s.UsageMonthlyCost), csvEfficiency(efficiency, ok))
+		}
+		pooled := rowType == csvRowSharedNamespace
+		if columns.idle {
+			r = append(r, csvAmount(s.IdleMonthlyCost, !pooled))
+		}
+		if columns.shared {
+			r = append(r, csvAmount(s.DirectMonthlyCost(), !pooled), csvAmount(s.SharedMonthlyCost, !pooled))
+		}
+		return r
+	}
+	for _, s := range pool {
+		if err := w.Write(row(csvRowSharedNamespace, s)); err != nil {
+			return fmt.Errorf("failed to write CSV summary row: %w", err)
+		}
+	}
+	for _, s := range summaries {
+		if err := w.Write(row(csvRowNamespace, s)); err != nil {
+			return fmt.Errorf("failed to write CSV summary row: %w", err)
+		}
+	}
+	if err := w.Write(row(csvRowTotal, analyzer.Total(summaries))); err != nil {
+		return fmt.Errorf("failed to write CSV total row: %w", err)
+	}
 	return nil
 }
+
+func summaryCSVRow(rowType string, s analyzer.NamespaceSummary, nameColumns int) []string {
+	row := []string{rowType, s.Namespace}
+	row = append(row, make([]string, nameColumns)...)
+	return append(row,
+		strconv.Itoa(s.TotalPods),
+		"",
+		"",
+		fmt.Sprintf("%.4f", s.HourlyCost),
+		"",
+		"",
+		fmt.Sprintf("%.2f", s.DailyCost),
+		"",
+		"",
+		fmt.Sprintf("%.2f", s.MonthlyCost),
+	)
+}
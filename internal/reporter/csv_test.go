@@ -1,16 +1,17 @@
 package reporter
 
 import (
+	"bytes"
 	"encoding/csv"
 	"strings"
 	"testing"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 )
 
-// Note: This test cannot use t.Parallel() because captureStdout modifies os.Stdout,
-// which is global state. Running these tests in parallel would cause interference.
 func TestPrintCostCSV(t *testing.T) {
+	t.Parallel()
 	tests := []struct {
 		name              string
 		costs             []calculator.PodCost
@@ -18,12 +19,14 @@ func TestPrintCostCSV(t *testing.T) {
 		wantFirstPodName  string
 		wantContainsCosts []string
 		wantSecondPodName string
+		wantTotalMonthly  string
 	}{
 		{
 			name: "single pod",
 			costs: []calculator.PodCost{
 				{
-					Name: "test-pod-1",
+					Name:      "test-pod-1",
+					Namespace: "default",
 					Hourly: calculator.ResourceCost{
 						CPUCost:    0.01,
 						MemoryCost: 0.005,
@@ -41,44 +44,48 @@ func TestPrintCostCSV(t *testing.T) {
 					},
 				},
 			},
-			wantLineCount:     2,
+			wantLineCount:     4,
 			wantFirstPodName:  "test-pod-1",
 			wantContainsCosts: []string{"0.0100", "10.95"},
 		},
 		{
 			name:          "empty input",
 			costs:         []calculator.PodCost{},
-			wantLineCount: 1,
+			wantLineCount: 2,
 		},
 		{
 			name: "multiple pods",
 			costs: []calculator.PodCost{
 				{
-					Name:    "pod-1",
-					Hourly:  calculator.ResourceCost{TotalCost: 0.01},
-					Daily:   calculator.ResourceCost{TotalCost: 0.24},
-					Monthly: calculator.ResourceCost{TotalCost: 7.30},
+					Name:      "pod-1",
+					Namespace: "team-a",
+					Hourly:    calculator.ResourceCost{TotalCost: 0.01},
+					Daily:     calculator.ResourceCost{TotalCost: 0.24},
+					Monthly:   calculator.ResourceCost{TotalCost: 7.30},
 				},
 				{
-					Name:    "pod-2",
-					Hourly:  calculator.ResourceCost{TotalCost: 0.02},
-					Daily:   calculator.ResourceCost{TotalCost: 0.48},
-					Monthly: calculator.ResourceCost{TotalCost: 14.60},
+					Name:      "pod-2",
+					Namespace: "team-b",
+					Hourly:    calculator.ResourceCost{TotalCost: 0.02},
+					Daily:     calculator.ResourceCost{TotalCost: 0.48},
+					Monthly:   calculator.ResourceCost{TotalCost: 14.60},
 				},
 			},
-			wantLineCount:     3,
+			wantLineCount:     6,
 			wantFirstPodName:  "pod-1",
 			wantSecondPodName: "pod-2",
+			wantTotalMonthly:  "21.90",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output := captureStdout(t, func() {
-				if err := PrintCostCSV(tt.costs); err != nil {
-					t.Fatalf("PrintCostCSV failed: %v", err)
-				}
-			})
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := PrintCostCSV(&buf, NewPodReport(Metadata{}, tt.costs, nil, analyzer.Allocations{})); err != nil {
+				t.Fatalf("PrintCostCSV failed: %v", err)
+			}
+			output := buf.String()
 
 			lines := strings.Split(strings.TrimSpace(output), "\n")
 
@@ -87,13 +94,13 @@ func TestPrintCostCSV(t *testing.T) {
 			}
 
 			// Verify header
-			expectedHeader := "pod_name,hourly_cpu_cost,hourly_memory_cost,hourly_total_cost,daily_cpu_cost,daily_memory_cost,daily_total_cost,monthly_cpu_cost,monthly_memory_cost,monthly_total_cost"
+			expectedHeader := "row_type,namespace,pod_name,pod_count,hourly_cpu_cost,hourly_memory_cost,hourly_total_cost,daily_cpu_cost,daily_memory_cost,daily_total_cost,monthly_cpu_cost,monthly_memory_cost,monthly_total_cost"
 			if lines[0] != expectedHeader {
 				t.Errorf("header mismatch:\nexpected: %s\ngot:      %s", expectedHeader, lines[0])
 			}
 
 			// Parse CSV for proper verification
-			if tt.wantLineCount > 1 {
+			if tt.wantLineCount > 2 {
 				reader := csv.NewReader(strings.NewReader(output))
 				records, err := reader.ReadAll()
 				if err != nil {
@@ -107,8 +114,11 @@ func TestPrintCostCSV(t *testing.T) {
 				// Verify first data row
 				if tt.wantFirstPodName != "" {
 					firstRow := records[1]
-					if firstRow[0] != tt.wantFirstPodName {
-						t.Errorf("first pod name: got %q, want %q", firstRow[0], tt.wantFirstPodName)
+					if firstRow[0] != "pod" {
+						t.Errorf("first row type: got %q, want %q", firstRow[0], "pod")
+					}
+					if firstRow[2] != tt.wantFirstPodName {
+						t.Errorf("first pod name: got %q, want %q", firstRow[2], tt.wantFirstPodName)
 					}
 				}
 
@@ -122,11 +132,241 @@ func TestPrintCostCSV(t *testing.T) {
 				// Verify second data row if present
 				if tt.wantSecondPodName != "" {
 					secondRow := records[2]
-					if secondRow[0] != tt.wantSecondPodName {
-						t.Errorf("second pod name: got %q, want %q", secondRow[0], tt.wantSecondPodName)
+					if secondRow[2] != tt.wantSecondPodName {
+						t.Errorf("second pod name: got %q, want %q", secondRow[2], tt.wantSecondPodName)
 					}
 				}
+
+				// Verify grand total row
+				totalRow := records[len(records)-1]
+				if totalRow[0] != "total" {
+					t.Errorf("last row type: got %q, want %q", totalRow[0], "total")
+				}
+				if tt.wantTotalMonthly != "" && totalRow[len(totalRow)-1] != tt.wantTotalMonthly {
+					t.Errorf("total monthly cost: got %q, want %q", totalRow[len(totalRow)-1], tt.wantTotalMonthly)
+				}
 			}
 		})
 	}
 }
+
+func TestPrintCostCSVExtendedResources(t *testing.T) {
+	t.Parallel()
+	costs := []calculator.PodCost{
+		{
+			Name:      "trainer",
+			Namespace: "ml",
+			Hourly:    calculator.ResourceCost{CPUCost: 0.034, Extended: map[string]float64{"nvidia.com/gpu": 2.5}, TotalCost: 2.534},
+			Daily:     calculator.ResourceCost{CPUCost: 0.816, Extended: map[string]float64{"nvidia.com/gpu": 60}, TotalCost: 60.816},
+			Monthly:   calculator.ResourceCost{CPUCost: 24.82, Extended: map[string]float64{"nvidia.com/gpu": 1825}, TotalCost: 1849.82},
+		},
+		{
+			Name:      "api",
+			Namespace: "ml",
+			Hourly:    calculator.ResourceCost{CPUCost: 0.034, TotalCost: 0.034},
+			Daily:     calculator.ResourceCost{CPUCost: 0.816, TotalCost: 0.816},
+			Monthly:   calculator.ResourceCost{CPUCost: 24.82, TotalCost: 24.82},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintCostCSV(&buf, NewPodReport(Metadata{}, costs, nil, analyzer.Allocations{})); err != nil {
+		t.Fatalf("PrintCostCSV failed: %v", err)
+	}
+	output := buf.String()
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+
+	header := records[0]
+	wantTail := []string{"hourly_nvidia.com/gpu_cost", "daily_nvidia.com/gpu_cost", "monthly_nvidia.com/gpu_cost"}
+	if got := strings.Join(header[len(header)-3:], ","); got != strings.Join(wantTail, ",") {
+		t.Errorf("extended columns: got %q, want %q", got, strings.Join(wantTail, ","))
+	}
+
+	tests := []struct {
+		row         int
+		wantMonthly string
+	}{
+		{row: 1, wantMonthly: "1825.00"},
+		{row: 2, wantMonthly: "0.00"},
+		{row: 4, wantMonthly: ""}, // total row
+	}
+	for _, tt := range tests {
+		record := records[tt.row]
+		if len(record) != len(header) {
+			t.Fatalf("row %d: got %d columns, want %d", tt.row, len(record), len(header))
+		}
+		if got := record[len(record)-1]; got != tt.wantMonthly {
+			t.Errorf("row %d monthly GPU cost: got %q, want %q", tt.row, got, tt.wantMonthly)
+		}
+	}
+}
+
+func TestPrintCostCSVOrphanedClaims(t *testing.T) {
+	t.Parallel()
+	costs := []calculator.PodCost{
+		{
+			Name:      "db-0",
+			Namespace: "db",
+			Hourly:    calculator.ResourceCost{CPUCost: 0.034, StorageCost: 0.011, TotalCost: 0.045},
+			Daily:     calculator.ResourceCost{CPUCost: 0.816, StorageCost: 0.264, TotalCost: 1.08},
+			Monthly:   calculator.ResourceCost{CPUCost: 24.82, StorageCost: 8, TotalCost: 32.82},
+		},
+	}
+	orphans := []calculator.ClaimCost{
+		{Name: "old-backup", Namespace: "db", StorageClass: "gp3", CapacityGB: 50, Hourly: 0.0055, Daily: 0.13, Monthly: 4},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintCostCSV(&buf, NewPodReport(Metadata{}, costs, orphans, analyzer.Allocations{})); err != nil {
+		t.Fatalf("PrintCostCSV failed: %v", err)
+	}
+	output := buf.String()
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("record count: got %d, want 5 (header, pod, orphan, namespace, total)", len(records))
+	}
+
+	header := records[0]
+	if got := header[len(header)-1]; got != "monthly_storage_cost" {
+		t.Errorf("last column: got %q, want %q", got, "monthly_storage_cost")
+	}
+
+	tests := []struct {
+		row         int
+		wantType    string
+		wantName    string
+		wantTotal   string
+		wantStorage string
+	}{
+		{row: 1, wantType: "pod", wantName: "db-0", wantTotal: "32.82", wantStorage: "8.00"},
+		{row: 2, wantType: "orphaned_claim", wantName: "old-backup", wantTotal: "4.00", wantStorage: "4.00"},
+		{row: 4, wantType: "total", wantTotal: "36.82"},
+	}
+	for _, tt := range tests {
+		record := records[tt.row]
+		if len(record) != len(header) {
+			t.Fatalf("row %d: got %d columns, want %d", tt.row, len(record), len(header))
+		}
+		if record[0] != tt.wantType {
+			t.Errorf("row %d type: got %q, want %q", tt.row, record[0], tt.wantType)
+		}
+		if record[2] != tt.wantName {
+			t.Errorf("row %d name: got %q, want %q", tt.row, record[2], tt.wantName)
+		}
+		if got := record[12]; got != tt.wantTotal {
+			t.Errorf("row %d monthly total: got %q, want %q", tt.row, got, tt.wantTotal)
+		}
+		if got := record[len(record)-1]; got != tt.wantStorage {
+			t.Errorf("row %d monthly storage: got %q, want %q", tt.row, got, tt.wantStorage)
+		}
+	}
+}
+
+func TestPrintGroupCSV(t *testing.T) {
+	t.Parallel()
+	keys := []analyzer.GroupKey{{Source: analyzer.GroupSourceLabel, Name: "team"}, {Source: analyzer.GroupSourceAnnotation, Name: "cost-center"}}
+	groups := []analyzer.GroupSummary{
+		{Key: "team", Value: "payments", TotalPods: 2, MonthlyCost: 15, Children: []analyzer.GroupSummary{
+			{Key: "cost-center", Value: "cc-1", TotalPods: 2, MonthlyCost: 15},
+		}},
+		{Key: "team", Value: "<none>", TotalPods: 1, MonthlyCost: 1, Children: []analyzer.GroupSummary{
+			{Key: "cost-center", Value: "<none>", TotalPods: 1, MonthlyCost: 1},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintGroupCSV(&buf, keys, groups); err != nil {
+		t.Fatalf("PrintGroupCSV failed: %v", err)
+	}
+	output := buf.String()
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+
+	want := [][]string{
+		{"row_type", "label:team", "annotation:cost-center", "pod_count", "hourly_cost", "daily_cost", "monthly_cost"},
+		{"group", "payments", "", "2", "0.0000", "0.00", "15.00"},
+		{"group", "payments", "cc-1", "2", "0.0000", "0.00", "15.00"},
+		{"group", "<none>", "", "1", "0.0000", "0.00", "1.00"},
+		{"group", "<none>", "<none>", "1", "0.0000", "0.00", "1.00"},
+		{"total", "", "", "3", "0.0000", "0.00", "16.00"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("row count: got %d, want %d", len(records), len(want))
+	}
+	for i, w := range want {
+		if got := strings.Join(records[i], ","); got != strings.Join(w, ",") {
+			t.Errorf("row %d: got %q, want %q", i, got, strings.Join(w, ","))
+		}
+	}
+}
+
+func TestPrintCostCSVIdle(t *testing.T) {
+	t.Parallel()
+	costs := []calculator.PodCost{
+		{Name: "api", Namespace: "shop", Node: "node-a", Hourly: calculator.ResourceCost{CPUCost: 0.1, TotalCost: 0.1}, Monthly: calculator.ResourceCost{CPUCost: 73, TotalCost: 73}},
+	}
+	idle := &analyzer.Idle{
+		Nodes: []calculator.NodeCost{
+			{Name: "node-a", Pods: 1, IdleHourly: calculator.ResourceCost{CPUCost: 0.05, TotalCost: 0.05}, IdleMonthly: calculator.ResourceCost{CPUCost: 36.5, TotalCost: 36.5}},
+		},
+		Shares: map[string]float64{"shop": 0.05},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintCostCSV(&buf, NewPodReport(Metadata{}, costs, nil, analyzer.Allocations{Idle: idle})); err != nil {
+		t.Fatalf("PrintCostCSV failed: %v", err)
+	}
+	output := buf.String()
+
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV output: %v", err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("record count: got %d, want 5 (header, pod, idle node, namespace, total)", len(records))
+	}
+
+	header := records[0]
+	if got := header[len(header)-1]; got != "monthly_idle_cost" {
+		t.Errorf("last column: got %q, want %q", got, "monthly_idle_cost")
+	}
+
+	tests := []struct {
+		row         int
+		wantType    string
+		wantName    string
+		wantMonthly string
+		wantIdle    string
+	}{
+		{row: 1, wantType: "pod", wantName: "api", wantMonthly: "73.00", wantIdle: ""},
+		{row: 2, wantType: "idle_node", wantName: "node-a", wantMonthly: "36.50", wantIdle: ""},
+		{row: 3, wantType: "namespace", wantMonthly: "109.50", wantIdle: "36.50"},
+		{row: 4, wantType: "total", wantMonthly: "109.50", wantIdle: "36.50"},
+	}
+	for _, tt := range tests {
+		record := records[tt.row]
+		if len(record) != len(header) {
+			t.Fatalf("row %d column count: got %d, want %d", tt.row, len(record), len(header))
+		}
+		if record[0] != tt.wantType || record[2] != tt.wantName {
+			t.Errorf("row %d: got %s %q, want %s %q", tt.row, record[0], record[2], tt.wantType, tt.wantName)
+		}
+		if got := record[12]; got != tt.wantMonthly {
+			t.Errorf("row %d monthly total: got %q, want %q", tt.row, got, tt.wantMonthly)
+		}
+		if got := record[len(record)-1]; got != tt.wantIdle {
+			t.Errorf("row %d idle share: got %q, want %q", tt.row, got, tt.wantIdle)
+		}
+	}
+}
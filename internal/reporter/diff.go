@@ -0,0 +1,144 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+)
+
+// PrintDiffTable lists added, removed and changed workloads followed by the change
+// in total monthly cost
+func PrintDiffTable(w io.Writer, diff analyzer.CostDiff) {
+	if len(diff.Workloads) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "CHANGE\tNAMESPACE\tKIND\tNAME\tREPLICAS\tBEFORE\tAFTER\tDELTA")
+		for _, d := range diff.Workloads {
+			before, after := diffCells(d)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.Change, d.Namespace, d.Kind, d.Name, replicaChange(d), before, after, formatDelta(d.Delta()))
+		}
+		tw.Flush()
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, describeDiff(diff))
+}
+
+// PrintDiffMarkdown renders the diff as a Markdown summary and table, for pull
+// request comments
+func PrintDiffMarkdown(w io.Writer, diff analyzer.CostDiff) {
+	fmt.Fprintf(w, "**%s**\n", describeDiff(diff))
+	if len(diff.Workloads) == 0 {
+		return
+	}
+	rows := make([][]string, len(diff.Workloads))
+	for i, d := range diff.Workloads {
+		before, after := diffCells(d)
+		rows[i] = []string{d.Change, d.Namespace, d.Kind, markdownCode(d.Name), replicaChange(d), before, after, formatDelta(d.Delta())}
+	}
+	fmt.Fprintln(w)
+	writeMarkdownTable(w, markdownColumns("Change", "Namespace", "Kind", "Name", "Replicas:", "Before:", "After:", "Delta:"), rows)
+}
+
+// describeDiff summarizes the change in total monthly cost in a sentence
+func describeDiff(diff analyzer.CostDiff) string {
+	delta := diff.Delta()
+	var change string
+	switch {
+	case delta >= 0.005:
+		change = fmt.Sprintf("This change adds $%.2f/month", delta)
+	case delta <= -0.005:
+		change = fmt.Sprintf("This change saves $%.2f/month", -delta)
+	default:
+		change = "This change does not affect cost"
+	}
+	summary := fmt.Sprintf("%s ($%.2f → $%.2f", change, diff.Before, diff.After)
+	if diff.Before > 0 {
+		summary += fmt.Sprintf(", %+.1f%%", delta/diff.Before*100)
+	}
+	return fmt.Sprintf("%s; %d workloads changed, %d unchanged)", summary, len(diff.Workloads), diff.Unchanged)
+}
+
+// replicaChange renders replicas before and after, or the one side that exists
+func replicaChange(d analyzer.WorkloadDiff) string {
+	switch d.Change {
+	case analyzer.ChangeAdded:
+		return fmt.Sprintf("%d", d.AfterReplicas)
+	case analyzer.ChangeRemoved:
+		return fmt.Sprintf("%d", d.BeforeReplicas)
+	}
+	if d.BeforeReplicas != d.AfterReplicas {
+		return fmt.Sprintf("%d → %d", d.BeforeReplicas, d.AfterReplicas)
+	}
+	return fmt.Sprintf("%d", d.AfterReplicas)
+}
+
+// diffCells renders the monthly cost before and after, with "-" on the side where
+// the workload does not exist
+func diffCells(d analyzer.WorkloadDiff) (before, after string) {
+	before, after = fmt.Sprintf("$%.2f", d.Before), fmt.Sprintf("$%.2f", d.After)
+	switch d.Change {
+	case analyzer.ChangeAdded:
+		before = "-"
+	case analyzer.ChangeRemoved:
+		after = "-"
+	}
+	return before, after
+}
+
+func formatDelta(delta float64) string {
+	if delta < 0 {
+		return fmt.Sprintf("-$%.2f", -delta)
+	}
+	return fmt.Sprintf("+$%.2f", delta)
+}
+
+type jsonDiffOutput struct {
+	Before    float64            `json:"before_monthly_cost"`
+	After     float64            `json:"after_monthly_cost"`
+	Delta     float64            `json:"delta_monthly_cost"`
+	Unchanged int                `json:"unchanged"`
+	Workloads []jsonWorkloadDiff `json:"workloads"`
+}
+
+type jsonWorkloadDiff struct {
+	Change         string  `json:"change"`
+	Namespace      string  `json:"namespace"`
+	Kind           string  `json:"kind"`
+	Name           string  `json:"name"`
+	BeforeReplicas int32   `json:"before_replicas"`
+	AfterReplicas  int32   `json:"after_replicas"`
+	Before         float64 `json:"before_monthly_cost"`
+	After          float64 `json:"after_monthly_cost"`
+	Delta          float64 `json:"delta_monthly_cost"`
+}
+
+// PrintDiffJSON outputs the diff in JSON format
+func PrintDiffJSON(w io.Writer, diff analyzer.CostDiff) error {
+	return encodeJSON(w, toJSONDiff(diff))
+}
+
+func toJSONDiff(diff analyzer.CostDiff) *jsonDiffOutput {
+	out := &jsonDiffOutput{
+		Before:    diff.Before,
+		After:     diff.After,
+		Delta:     diff.Delta(),
+		Unchanged: diff.Unchanged,
+		Workloads: make([]jsonWorkloadDiff, len(diff.Workloads)),
+	}
+	for i, d := range diff.Workloads {
+		out.Workloads[i] = jsonWorkloadDiff{
+			Change:         d.Change,
+			Namespace:      d.Namespace,
+			Kind:           d.Kind,
+			Name:           d.Name,
+			BeforeReplicas: d.BeforeReplicas,
+			AfterReplicas:  d.AfterReplicas,
+			Before:         d.Before,
+			After:          d.After,
+			Delta:          d.Delta(),
+		}
+	}
+	return out
+}
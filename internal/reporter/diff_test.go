@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+)
+
+func TestDescribeDiff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		diff analyzer.CostDiff
+		want string
+	}{
+		{
+			name: "increase",
+			diff: analyzer.CostDiff{Before: 100, After: 412, Workloads: make([]analyzer.WorkloadDiff, 2), Unchanged: 5},
+			want: "This change adds $312.00/month ($100.00 → $412.00, +312.0%; 2 workloads changed, 5 unchanged)",
+		},
+		{
+			name: "decrease",
+			diff: analyzer.CostDiff{Before: 200, After: 150, Workloads: make([]analyzer.WorkloadDiff, 1)},
+			want: "This change saves $50.00/month ($200.00 → $150.00, -25.0%; 1 workloads changed, 0 unchanged)",
+		},
+		{
+			name: "from nothing",
+			diff: analyzer.CostDiff{After: 30, Workloads: make([]analyzer.WorkloadDiff, 1)},
+			want: "This change adds $30.00/month ($0.00 → $30.00; 1 workloads changed, 0 unchanged)",
+		},
+		{
+			name: "no change",
+			diff: analyzer.CostDiff{Before: 50, After: 50, Unchanged: 3},
+			want: "This change does not affect cost ($50.00 → $50.00, +0.0%; 0 workloads changed, 3 unchanged)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := describeDiff(tt.diff); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintDiffMarkdown(t *testing.T) {
+	t.Parallel()
+	diff := analyzer.CostDiff{
+		Before: 90,
+		After:  135,
+		Workloads: []analyzer.WorkloadDiff{
+			{Change: analyzer.ChangeChanged, Namespace: "shop", Kind: "Deployment", Name: "web", BeforeReplicas: 3, AfterReplicas: 4, Before: 90, After: 120},
+			{Change: analyzer.ChangeAdded, Namespace: "shop", Kind: "CronJob", Name: "report", AfterReplicas: 1, After: 15},
+		},
+	}
+
+	var buf bytes.Buffer
+	PrintDiffMarkdown(&buf, diff)
+	output := buf.String()
+
+	for _, want := range []string{
+		"**This change adds $45.00/month",
+		"| changed | shop | Deployment | `web` | 3 → 4 | $90.00 | $120.00 | +$30.00 |",
+		"| added | shop | CronJob | `report` | 1 | - | $15.00 | +$15.00 |",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
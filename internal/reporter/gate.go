@@ -0,0 +1,96 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/gate"
+)
+
+// PrintViolationTable lists budget violations, most severe first
+func PrintViolationTable(w io.Writer, violations []gate.Violation) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "LEVEL\tSCOPE\tNAME\tRULE\tMONTHLY\tBASELINE\tACTUAL\tLIMIT")
+	for _, v := range violations {
+		name := v.Name
+		if name == "" {
+			name = "-"
+		}
+		baseline := "-"
+		if v.Rule == gate.RuleMaxIncrease {
+			baseline = fmt.Sprintf("$%.2f", v.Baseline)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t$%.2f\t%s\t%s\t%s\n",
+			v.Level, v.Scope, name, v.Rule, v.Monthly, baseline, formatGateValue(v.Rule, v.Actual), formatGateValue(v.Rule, v.Limit))
+	}
+}
+
+// formatGateValue renders a monthly cost, or an increase in percent
+func formatGateValue(rule string, value float64) string {
+	if rule != gate.RuleMaxIncrease {
+		return fmt.Sprintf("$%.2f", value)
+	}
+	if math.IsInf(value, 1) {
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", value)
+}
+
+type jsonGateOutput struct {
+	Result     string              `json:"result"`
+	Total      float64             `json:"monthly_cost"`
+	Violations []jsonGateViolation `json:"violations"`
+}
+
+type jsonGateViolation struct {
+	Level    string   `json:"level"`
+	Scope    string   `json:"scope"`
+	Name     string   `json:"name,omitempty"`
+	Rule     string   `json:"rule"`
+	Monthly  float64  `json:"monthly_cost"`
+	Baseline *float64 `json:"baseline_monthly_cost,omitempty"`
+	// Actual is omitted for increases from zero, which are infinite
+	Actual *float64 `json:"actual,omitempty"`
+	Limit  float64  `json:"limit"`
+}
+
+// PrintViolationJSON outputs the gate result ("pass", "warn" or "fail"), the total
+// monthly cost and the violations in JSON format
+func PrintViolationJSON(w io.Writer, total float64, violations []gate.Violation) error {
+	out := jsonGateOutput{
+		Result:     gateResult(violations),
+		Total:      total,
+		Violations: make([]jsonGateViolation, len(violations)),
+	}
+	for i, v := range violations {
+		jv := jsonGateViolation{
+			Level:   v.Level,
+			Scope:   v.Scope,
+			Name:    v.Name,
+			Rule:    v.Rule,
+			Monthly: v.Monthly,
+			Limit:   v.Limit,
+		}
+		if v.Rule == gate.RuleMaxIncrease {
+			baseline := v.Baseline
+			jv.Baseline = &baseline
+		}
+		if !math.IsInf(v.Actual, 0) {
+			actual := v.Actual
+			jv.Actual = &actual
+		}
+		out.Violations[i] = jv
+	}
+	return encodeJSON(w, out)
+}
+
+func gateResult(violations []gate.Violation) string {
+	if worst := gate.Worst(violations); worst != "" {
+		return worst
+	}
+	return "pass"
+}
@@ -0,0 +1,139 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+)
+
+// csvRowGroup marks group rows in grouped CSV output
+const csvRowGroup = "group"
+
+// PrintGroupTable displays nested group summaries, indenting each level,
+// followed by the grand total
+func PrintGroupTable(w io.Writer, groups []analyzer.GroupSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "GROUP\tPODS\tHOURLY\tDAILY\tMONTHLY")
+	var printLevel func(groups []analyzer.GroupSummary, depth int)
+	printLevel = func(groups []analyzer.GroupSummary, depth int) {
+		for _, g := range groups {
+			fmt.Fprintf(tw, "%s%s=%s\t%d\t$%.4f\t$%.2f\t$%.2f\n",
+				strings.Repeat("  ", depth), g.Key, g.Value, g.TotalPods, g.HourlyCost, g.DailyCost, g.MonthlyCost)
+			printLevel(g.Children, depth+1)
+		}
+	}
+	printLevel(groups, 0)
+
+	total := analyzer.GroupTotal(groups)
+	fmt.Fprintf(tw, "TOTAL\t%d\t$%.4f\t$%.2f\t$%.2f\n", total.TotalPods, total.HourlyCost, total.DailyCost, total.MonthlyCost)
+}
+
+type jsonGroupOutput struct {
+	GroupBy []string           `json:"group_by"`
+	Groups  []jsonGroupSummary `json:"groups"`
+	Summary jsonGroupSummary   `json:"summary"`
+}
+
+type jsonGroupSummary struct {
+	Key         string             `json:"key,omitempty"`
+	Value       string             `json:"value,omitempty"`
+	TotalPods   int                `json:"total_pods"`
+	HourlyCost  float64            `json:"hourly_cost"`
+	DailyCost   float64            `json:"daily_cost"`
+	MonthlyCost float64            `json:"monthly_cost"`
+	Groups      []jsonGroupSummary `json:"groups,omitempty"`
+}
+
+// PrintGroupJSON outputs nested group summaries in JSON format; summary holds the
+// grand total
+func PrintGroupJSON(w io.Writer, keys []analyzer.GroupKey, groups []analyzer.GroupSummary) error {
+	groupBy := make([]string, len(keys))
+	for i, k := range keys {
+		groupBy[i] = k.String()
+	}
+
+	return encodeJSON(w, jsonGroupOutput{
+		GroupBy: groupBy,
+		Groups:  toJSONGroups(groups),
+		Summary: toJSONGroup(analyzer.GroupTotal(groups)),
+	})
+}
+
+func toJSONGroups(groups []analyzer.GroupSummary) []jsonGroupSummary {
+	out := make([]jsonGroupSummary, len(groups))
+	for i, g := range groups {
+		out[i] = toJSONGroup(g)
+	}
+	return out
+}
+
+func toJSONGroup(g analyzer.GroupSummary) jsonGroupSummary {
+	out := jsonGroupSummary{
+		Key:         g.Key,
+		Value:       g.Value,
+		TotalPods:   g.TotalPods,
+		HourlyCost:  g.HourlyCost,
+		DailyCost:   g.DailyCost,
+		MonthlyCost: g.MonthlyCost,
+	}
+	if len(g.Children) > 0 {
+		out.Groups = toJSONGroups(g.Children)
+	}
+	return out
+}
+
+// PrintGroupCSV outputs one row per group at every level, with a column per group
+// key; deeper keys are empty on rows for outer levels. A total row follows.
+func PrintGroupCSV(w io.Writer, keys []analyzer.GroupKey, groups []analyzer.GroupSummary) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{"row_type"}
+	for _, k := range keys {
+		header = append(header, k.String())
+	}
+	header = append(header, "pod_count", "hourly_cost", "daily_cost", "monthly_cost")
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	row := func(rowType string, values []string, g analyzer.GroupSummary) []string {
+		r := append([]string{rowType}, values...)
+		r = append(r, make([]string, len(keys)-len(values))...)
+		return append(r,
+			strconv.Itoa(g.TotalPods),
+			fmt.Sprintf("%.4f", g.HourlyCost),
+			fmt.Sprintf("%.2f", g.DailyCost),
+			fmt.Sprintf("%.2f", g.MonthlyCost),
+		)
+	}
+
+	var write func(groups []analyzer.GroupSummary, values []string) error
+	write = func(groups []analyzer.GroupSummary, values []string) error {
+		for _, g := range groups {
+			path := append(append([]string(nil), values...), g.Value)
+			if err := cw.Write(row(csvRowGroup, path, g)); err != nil {
+				return fmt.Errorf("failed to write CSV row: %w", err)
+			}
+			if err := write(g.Children, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := write(groups, nil); err != nil {
+		return err
+	}
+
+	if err := cw.Write(row(csvRowTotal, nil, analyzer.GroupTotal(groups))); err != nil {
+		return fmt.Errorf("failed to write CSV total row: %w", err)
+	}
+	return nil
+}
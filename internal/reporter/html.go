@@ -0,0 +1,304 @@
+package reporter
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+//go:embed html.tmpl
+var htmlTemplateText string
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"money":         func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"hourly":        func(v float64) string { return fmt.Sprintf("$%.4f", v) },
+	"percent":       func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	"delta":         formatDelta,
+	"replicaChange": replicaChange,
+	"diffCells": func(d analyzer.WorkloadDiff) []string {
+		before, after := diffCells(d)
+		return []string{before, after}
+	},
+	"describeDiff": describeDiff,
+}).Parse(htmlTemplateText))
+
+func init() {
+	Register("html", ReporterFunc(PrintHTML))
+}
+
+// costComponent is one part of a cost split, e.g. the CPU share of a namespace
+type costComponent struct {
+	Name    string
+	Color   string
+	Monthly float64
+	// Share is the component's fraction of the whole
+	Share float64
+}
+
+// htmlNamespace is a namespace section: its summary, cost split and rows
+type htmlNamespace struct {
+	Summary    analyzer.NamespaceSummary
+	Components []costComponent
+	// CPU and Memory are the monthly request costs; Other is the rest of the total
+	CPU, Memory, Other float64
+	// Share is the namespace's fraction of the report total
+	Share float64
+	// Shared marks a shared namespace whose cost is allocated to the others
+	Shared bool
+	Rows   []htmlRow
+}
+
+// htmlRow is a pod or workload in a namespace table
+type htmlRow struct {
+	Name     string
+	Kind     string
+	Replicas int32
+	Hourly   float64
+	Monthly  calculator.ResourceCost
+	Extended float64
+}
+
+// htmlReport is the data behind the HTML template
+type htmlReport struct {
+	Metadata   Metadata
+	Generated  string
+	ByWorkload bool
+	Unit       string
+	Rows       int
+	Total      analyzer.NamespaceSummary
+	// Components split the total; CPU, Memory and Other sum the namespace columns
+	Components         []costComponent
+	CPU, Memory, Other float64
+	Namespaces         []htmlNamespace
+	Orphans            []calculator.ClaimCost
+	Diff               *analyzer.CostDiff
+	PieChart           template.HTML
+	BarChart           template.HTML
+	// ShowStorage and ShowExtended add row columns when any row has such costs
+	ShowStorage, ShowExtended bool
+}
+
+// Component colors, shared by the charts and their legends
+const (
+	colorCPU      = "#4e79a7"
+	colorMemory   = "#f28e2b"
+	colorStorage  = "#59a14f"
+	colorExtended = "#b07aa1"
+	colorIdle     = "#bab0ac"
+	colorShared   = "#76b7b2"
+)
+
+// PrintHTML renders a report as a single self-contained HTML page for readers
+// without a terminal: the total split into CPU, memory and other costs as a pie
+// chart, a bar chart of namespace costs, and a sortable table of pods or workloads
+// per namespace. Styles, scripts and charts are inline, so the file can be mailed
+// or archived as is.
+func PrintHTML(w io.Writer, r Report) error {
+	if err := htmlTemplate.Execute(w, newHTMLReport(r)); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
+
+func newHTMLReport(r Report) htmlReport {
+	rows := make(map[string][]htmlRow)
+	if r.ByWorkload() {
+		for _, c := range r.Workloads {
+			rows[c.Namespace] = append(rows[c.Namespace], htmlRow{
+				Name: c.Name, Kind: c.Kind, Replicas: c.Replicas,
+				Hourly: c.Hourly.TotalCost, Monthly: c.Monthly, Extended: sumExtended(c.Monthly),
+			})
+		}
+	} else {
+		for _, c := range r.Pods {
+			rows[c.Namespace] = append(rows[c.Namespace], htmlRow{
+				Name: c.Name, Kind: c.OwnerKind, Replicas: 1,
+				Hourly: c.Hourly.TotalCost, Monthly: c.Monthly, Extended: sumExtended(c.Monthly),
+			})
+		}
+	}
+
+	out := htmlReport{
+		Metadata:   r.Metadata,
+		ByWorkload: r.ByWorkload(),
+		Unit:       "pods",
+		Total:      analyzer.Total(r.Summaries),
+		Orphans:    r.Orphans,
+		Diff:       r.Diff,
+	}
+	if out.ByWorkload {
+		out.Unit = "workloads"
+	}
+	if !r.Metadata.GeneratedAt.IsZero() {
+		out.Generated = r.Metadata.GeneratedAt.UTC().Format("2006-01-02 15:04 MST")
+	}
+
+	section := func(s analyzer.NamespaceSummary, shared bool) htmlNamespace {
+		ns := htmlNamespace{Summary: s, Shared: shared, Rows: rows[s.Namespace]}
+		var cpu, memory, storage, extended float64
+		for _, row := range ns.Rows {
+			cpu += row.Monthly.CPUCost
+			memory += row.Monthly.MemoryCost
+			storage += row.Monthly.StorageCost
+			extended += row.Extended
+			out.ShowStorage = out.ShowStorage || row.Monthly.StorageCost > 0
+			out.ShowExtended = out.ShowExtended || row.Extended > 0
+		}
+		ns.Components = []costComponent{
+			{Name: "CPU", Color: colorCPU, Monthly: cpu},
+			{Name: "Memory", Color: colorMemory, Monthly: memory},
+			{Name: "Storage", Color: colorStorage, Monthly: storage + s.OrphanedMonthlyCost},
+			{Name: "Extended resources", Color: colorExtended, Monthly: extended},
+			{Name: "Idle capacity", Color: colorIdle, Monthly: s.IdleMonthlyCost},
+			{Name: "Shared namespaces", Color: colorShared, Monthly: s.SharedMonthlyCost},
+		}
+		ns.CPU, ns.Memory, ns.Other = cpu, memory, math.Max(0, s.MonthlyCost-cpu-memory)
+		if out.Total.MonthlyCost > 0 {
+			ns.Share = s.MonthlyCost / out.Total.MonthlyCost
+		}
+		out.Rows += len(ns.Rows)
+		return ns
+	}
+	for _, s := range r.Summaries {
+		out.Namespaces = append(out.Namespaces, section(s, false))
+	}
+	if shared := r.Allocations.Shared; shared != nil {
+		for _, s := range shared.Pool {
+			out.Namespaces = append(out.Namespaces, section(s, true))
+		}
+	}
+
+	// The report total splits into the allocated namespaces' components; shared
+	// namespaces are already counted through their shares
+	for _, ns := range out.Namespaces {
+		if ns.Shared {
+			continue
+		}
+		out.CPU += ns.CPU
+		out.Memory += ns.Memory
+		out.Other += ns.Other
+		for i, c := range ns.Components {
+			if i == len(out.Components) {
+				out.Components = append(out.Components, costComponent{Name: c.Name, Color: c.Color})
+			}
+			out.Components[i].Monthly += c.Monthly
+		}
+	}
+	out.Components = withShares(nonZero(out.Components))
+	for i := range out.Namespaces {
+		out.Namespaces[i].Components = withShares(nonZero(out.Namespaces[i].Components))
+	}
+
+	out.PieChart = pieChart(out.Components)
+	out.BarChart = barChart(out.Namespaces)
+	return out
+}
+
+func sumExtended(c calculator.ResourceCost) float64 {
+	total := 0.0
+	for _, cost := range c.Extended {
+		total += cost
+	}
+	return total
+}
+
+// nonZero drops components that cost nothing, keeping charts and legends short
+func nonZero(components []costComponent) []costComponent {
+	var out []costComponent
+	for _, c := range components {
+		if c.Monthly >= 0.005 {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// withShares sets each component's fraction of their sum
+func withShares(components []costComponent) []costComponent {
+	total := 0.0
+	for _, c := range components {
+		total += c.Monthly
+	}
+	for i := range components {
+		if total > 0 {
+			components[i].Share = components[i].Monthly / total
+		}
+	}
+	return components
+}
+
+// pieChart draws the cost split as an SVG pie chart, starting at twelve o'clock
+func pieChart(components []costComponent) template.HTML {
+	const size, r = 220.0, 100.0
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="pie" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img" aria-label="Monthly cost by resource">`, size, size, size, size)
+	if len(components) == 0 {
+		fmt.Fprintf(&b, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="#eee"/>`, size/2, size/2, r)
+	}
+	angle := -math.Pi / 2
+	for _, c := range components {
+		fraction := c.Share
+		title := template.HTMLEscapeString(fmt.Sprintf("%s: $%.2f (%.1f%%)", c.Name, c.Monthly, fraction*100))
+		if fraction > 0.9999 {
+			fmt.Fprintf(&b, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="%s"><title>%s</title></circle>`, size/2, size/2, r, c.Color, title)
+			break
+		}
+		end := angle + fraction*2*math.Pi
+		largeArc := 0
+		if fraction > 0.5 {
+			largeArc = 1
+		}
+		fmt.Fprintf(&b, `<path d="M%.0f,%.0f L%.2f,%.2f A%.0f,%.0f 0 %d 1 %.2f,%.2f Z" fill="%s"><title>%s</title></path>`,
+			size/2, size/2,
+			size/2+r*math.Cos(angle), size/2+r*math.Sin(angle),
+			r, r, largeArc,
+			size/2+r*math.Cos(end), size/2+r*math.Sin(end),
+			c.Color, title)
+		angle = end
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// barChart draws each namespace's monthly cost as a horizontal bar stacked by
+// component, scaled to the most expensive namespace
+func barChart(namespaces []htmlNamespace) template.HTML {
+	const labelWidth, barWidth, valueWidth, rowHeight = 160.0, 420.0, 90.0, 26.0
+	largest := 0.0
+	for _, ns := range namespaces {
+		largest = math.Max(largest, ns.Summary.MonthlyCost)
+	}
+
+	height := rowHeight*float64(len(namespaces)) + 4
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="bars" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" role="img" aria-label="Monthly cost by namespace">`,
+		labelWidth+barWidth+valueWidth, height, labelWidth+barWidth+valueWidth, height)
+	for i, ns := range namespaces {
+		y := float64(i)*rowHeight + 2
+		name := ns.Summary.Namespace
+		if ns.Shared {
+			name += " (shared)"
+		}
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.1f" text-anchor="end">%s</text>`, labelWidth-8, y+rowHeight/2+4, template.HTMLEscapeString(name))
+		x := labelWidth
+		for _, c := range ns.Components {
+			if largest <= 0 {
+				break
+			}
+			width := c.Monthly / largest * barWidth
+			fmt.Fprintf(&b, `<rect x="%.2f" y="%.1f" width="%.2f" height="%.0f" fill="%s"><title>%s</title></rect>`,
+				x, y+3, width, rowHeight-8, c.Color,
+				template.HTMLEscapeString(fmt.Sprintf("%s %s: $%.2f", ns.Summary.Namespace, c.Name, c.Monthly)))
+			x += width
+		}
+		fmt.Fprintf(&b, `<text x="%.2f" y="%.1f">$%.2f</text>`, x+6, y+rowHeight/2+4, ns.Summary.MonthlyCost)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
@@ -0,0 +1,89 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestPrintHTML(t *testing.T) {
+	t.Parallel()
+	pods := []calculator.PodCost{
+		{Name: "web-1", Namespace: "shop", OwnerKind: "Deployment", Monthly: calculator.ResourceCost{CPUCost: 20, MemoryCost: 10, TotalCost: 30}},
+		{Name: "<b>db-0</b>", Namespace: "data", Monthly: calculator.ResourceCost{CPUCost: 6, MemoryCost: 2, StorageCost: 2, TotalCost: 10}},
+	}
+	r := NewPodReport(Metadata{Scope: "2 namespaces", Note: "Estimates only."}, pods, nil, analyzer.Allocations{})
+
+	var buf bytes.Buffer
+	if err := PrintHTML(&buf, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{
+		"<title>Kubernetes cost report: 2 namespaces</title>",
+		`<svg class="pie"`,
+		`<svg class="bars"`,
+		"CPU: $26.00 (65.0%)",
+		"Storage: $2.00 (5.0%)",
+		`<tr><td>shop</td><td class="num">1</td><td class="num" data-sort="20">$20.00</td><td class="num" data-sort="10">$10.00</td><td class="num" data-sort="0">$0.00</td>`,
+		"<h3>data &mdash; $10.00/month</h3>",
+		`<th class="num">Storage monthly</th>`,
+		"&lt;b&gt;db-0&lt;/b&gt;",
+		"<blockquote>Estimates only.</blockquote>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	for _, notWant := range []string{"<b>db-0</b>", "src=", "href=", "Changes since baseline"} {
+		if strings.Contains(output, notWant) {
+			t.Errorf("output contains %q", notWant)
+		}
+	}
+}
+
+func TestPieChart(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		components []costComponent
+		want       []string
+	}{
+		{
+			name: "empty",
+			want: []string{`<circle cx="110" cy="110" r="100" fill="#eee"/>`},
+		},
+		{
+			name:       "single component",
+			components: []costComponent{{Name: "CPU", Color: colorCPU, Monthly: 10, Share: 1}},
+			want:       []string{`<circle cx="110" cy="110" r="100" fill="#4e79a7"><title>CPU: $10.00 (100.0%)</title></circle>`},
+		},
+		{
+			name: "split",
+			components: []costComponent{
+				{Name: "CPU", Color: colorCPU, Monthly: 75, Share: 0.75},
+				{Name: "Memory", Color: colorMemory, Monthly: 25, Share: 0.25},
+			},
+			want: []string{
+				`<path d="M110,110 L110.00,10.00 A100,100 0 1 1 10.00,110.00 Z" fill="#4e79a7">`,
+				`<path d="M110,110 L10.00,110.00 A100,100 0 0 1 110.00,10.00 Z" fill="#f28e2b">`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := string(pieChart(tt.components))
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("got %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
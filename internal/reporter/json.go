@@ -3,82 +3,336 @@ package reporter
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"time"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 )
 
 type jsonOutput struct {
-	Namespace string              `json:"namespace"`
-	Pods      []jsonPodCost       `json:"pods"`
-	Summary   jsonNamespaceSummary `json:"summary"`
+	Metadata       *jsonMetadata          `json:"metadata,omitempty"`
+	Namespace      string                 `json:"namespace,omitempty"`
+	Pods           []jsonPodCost          `json:"pods"`
+	OrphanedClaims []jsonClaimCost        `json:"orphaned_claims,omitempty"`
+	Namespaces     []jsonNamespaceSummary `json:"namespaces"`
+	Summary        jsonNamespaceSummary   `json:"summary"`
+	Idle           *jsonNodeOutput        `json:"idle,omitempty"`
+	Shared         *jsonShared            `json:"shared,omitempty"`
+	Diff           *jsonDiffOutput        `json:"diff,omitempty"`
+}
+
+type jsonMetadata struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Scope       string    `json:"scope,omitempty"`
+	Rates       jsonRates `json:"rates"`
+}
+
+type jsonRates struct {
+	Currency             string  `json:"currency,omitempty"`
+	Source               string  `json:"source,omitempty"`
+	CPUPerCorePerHour    float64 `json:"cpu_per_core_per_hour"`
+	MemoryPerGBPerHour   float64 `json:"memory_per_gb_per_hour"`
+	StoragePerGBPerMonth float64 `json:"storage_per_gb_per_month,omitempty"`
+}
+
+type jsonShared struct {
+	Strategy string `json:"strategy"`
+	// Namespaces are the shared namespaces whose cost was allocated
+	Namespaces  []jsonNamespaceSummary `json:"namespaces"`
+	MonthlyCost float64                `json:"monthly_cost"`
+	// Allocated is false when no namespace could receive a share
+	Allocated bool `json:"allocated"`
+}
+
+type jsonClaimCost struct {
+	Name         string  `json:"name"`
+	Namespace    string  `json:"namespace"`
+	StorageClass string  `json:"storage_class,omitempty"`
+	CapacityGB   float64 `json:"capacity_gb"`
+	HourlyCost   float64 `json:"hourly_cost"`
+	DailyCost    float64 `json:"daily_cost"`
+	MonthlyCost  float64 `json:"monthly_cost"`
 }
 
 type jsonPodCost struct {
-	Name    string           `json:"name"`
-	Hourly  jsonResourceCost `json:"hourly"`
-	Daily   jsonResourceCost `json:"daily"`
-	Monthly jsonResourceCost `json:"monthly"`
+	Name        string           `json:"name"`
+	Namespace   string           `json:"namespace"`
+	OwnerKind   string           `json:"owner_kind,omitempty"`
+	OwnerName   string           `json:"owner_name,omitempty"`
+	RequestRule string           `json:"request_rule,omitempty"`
+	Hourly      jsonResourceCost `json:"hourly"`
+	Daily       jsonResourceCost `json:"daily"`
+	Monthly     jsonResourceCost `json:"monthly"`
+	Usage       *jsonUsage       `json:"usage,omitempty"`
+}
+
+type jsonUsage struct {
+	CPURequestCores float64  `json:"cpu_request_cores"`
+	CPUUsageCores   float64  `json:"cpu_usage_cores"`
+	MemoryRequestGB float64  `json:"memory_request_gb"`
+	MemoryUsageGB   float64  `json:"memory_usage_gb"`
+	HourlyCost      float64  `json:"hourly_cost"`
+	DailyCost       float64  `json:"daily_cost"`
+	MonthlyCost     float64  `json:"monthly_cost"`
+	Efficiency      *float64 `json:"efficiency,omitempty"`
+}
+
+type jsonWorkloadOutput struct {
+	Metadata       *jsonMetadata          `json:"metadata,omitempty"`
+	Namespace      string                 `json:"namespace,omitempty"`
+	Workloads      []jsonWorkloadCost     `json:"workloads"`
+	OrphanedClaims []jsonClaimCost        `json:"orphaned_claims,omitempty"`
+	Namespaces     []jsonNamespaceSummary `json:"namespaces"`
+	Summary        jsonNamespaceSummary   `json:"summary"`
+	Diff           *jsonDiffOutput        `json:"diff,omitempty"`
+}
+
+type jsonWorkloadCost struct {
+	Kind        string           `json:"kind"`
+	Name        string           `json:"name"`
+	Namespace   string           `json:"namespace"`
+	Replicas    int32            `json:"replicas"`
+	RequestRule string           `json:"request_rule,omitempty"`
+	Hourly      jsonResourceCost `json:"hourly"`
+	Daily       jsonResourceCost `json:"daily"`
+	Monthly     jsonResourceCost `json:"monthly"`
 }
 
 type jsonResourceCost struct {
-	CPUCost    float64 `json:"cpu_cost"`
-	MemoryCost float64 `json:"memory_cost"`
-	TotalCost  float64 `json:"total_cost"`
+	CPUCost     float64            `json:"cpu_cost"`
+	MemoryCost  float64            `json:"memory_cost"`
+	Extended    map[string]float64 `json:"extended,omitempty"`
+	StorageCost float64            `json:"storage_cost,omitempty"`
+	TotalCost   float64            `json:"total_cost"`
 }
 
 type jsonNamespaceSummary struct {
+	Namespace   string  `json:"namespace,omitempty"`
 	TotalPods   int     `json:"total_pods"`
 	HourlyCost  float64 `json:"hourly_cost"`
 	DailyCost   float64 `json:"daily_cost"`
 	MonthlyCost float64 `json:"monthly_cost"`
-}
 
-// PrintCostJSON outputs pod costs in JSON format
-func PrintCostJSON(namespace string, costs []calculator.PodCost) error {
-	pods := make([]jsonPodCost, len(costs))
-	var totalHourly, totalDaily, totalMonthly float64
+	OrphanedClaims      int     `json:"orphaned_claims,omitempty"`
+	OrphanedMonthlyCost float64 `json:"orphaned_monthly_cost,omitempty"`
+
+	UsageMonthlyCost float64  `json:"usage_monthly_cost,omitempty"`
+	Efficiency       *float64 `json:"efficiency,omitempty"`
+
+	IdleMonthlyCost float64 `json:"idle_monthly_cost,omitempty"`
 
-	for i, c := range costs {
+	DirectMonthlyCost *float64 `json:"direct_monthly_cost,omitempty"`
+	SharedMonthlyCost *float64 `json:"shared_monthly_cost,omitempty"`
+}
+
+// PrintCostJSON outputs a report's pod costs in JSON format.
+// The report's namespace is reported at the top level when a single namespace was
+// analyzed and may be empty for multi-namespace reports; summary holds the grand
+// total. Metadata, when the report has a generation time, records it with the
+// scope and the rates used.
+// Orphaned claims are listed separately and included in the namespace totals.
+// Pods with a usage sample carry it under "usage" with their efficiency.
+// With idle capacity, node capacity and idle costs are reported under "idle";
+// distributed shares are included in the namespace totals and broken out in
+// idle_monthly_cost. With shared costs, the shared namespaces are reported under
+// "shared" and namespace totals include their allocated share, broken out with
+// the direct cost in direct_monthly_cost and shared_monthly_cost.
+func PrintCostJSON(w io.Writer, r Report) error {
+	pods := make([]jsonPodCost, len(r.Pods))
+	for i, c := range r.Pods {
 		pods[i] = jsonPodCost{
-			Name: c.Name,
-			Hourly: jsonResourceCost{
-				CPUCost:    c.Hourly.CPUCost,
-				MemoryCost: c.Hourly.MemoryCost,
-				TotalCost:  c.Hourly.TotalCost,
-			},
-			Daily: jsonResourceCost{
-				CPUCost:    c.Daily.CPUCost,
-				MemoryCost: c.Daily.MemoryCost,
-				TotalCost:  c.Daily.TotalCost,
-			},
-			Monthly: jsonResourceCost{
-				CPUCost:    c.Monthly.CPUCost,
-				MemoryCost: c.Monthly.MemoryCost,
-				TotalCost:  c.Monthly.TotalCost,
-			},
+			Name:        c.Name,
+			Namespace:   c.Namespace,
+			OwnerKind:   c.OwnerKind,
+			OwnerName:   c.OwnerName,
+			RequestRule: c.RequestRule,
+			Hourly:      toJSONResourceCost(c.Hourly),
+			Daily:       toJSONResourceCost(c.Daily),
+			Monthly:     toJSONResourceCost(c.Monthly),
+			Usage:       toJSONUsage(c),
+		}
+	}
+
+	alloc := r.Allocations
+	namespaces := make([]jsonNamespaceSummary, len(r.Summaries))
+	for i, s := range r.Summaries {
+		namespaces[i] = toJSONNamespaceSummary(s)
+		if alloc.Shared != nil {
+			withSharedCosts(&namespaces[i], s)
 		}
-		totalHourly += c.Hourly.TotalCost
-		totalDaily += c.Daily.TotalCost
-		totalMonthly += c.Monthly.TotalCost
 	}
 
 	output := jsonOutput{
-		Namespace: namespace,
-		Pods:      pods,
-		Summary: jsonNamespaceSummary{
-			TotalPods:   len(costs),
-			HourlyCost:  totalHourly,
-			DailyCost:   totalDaily,
-			MonthlyCost: totalMonthly,
-		},
+		Metadata:       toJSONMetadata(r.Metadata),
+		Namespace:      r.Metadata.Namespace,
+		Pods:           pods,
+		OrphanedClaims: toJSONClaimCosts(r.Orphans),
+		Namespaces:     namespaces,
+		Summary:        toJSONNamespaceSummary(analyzer.Total(r.Summaries)),
+	}
+	if alloc.Idle != nil {
+		nodes := toJSONNodeOutput(alloc.Idle.Nodes, alloc.Idle.Shares != nil)
+		output.Idle = &nodes
+	}
+	if alloc.Shared != nil {
+		output.Shared = toJSONShared(alloc.Shared)
+		withSharedCosts(&output.Summary, analyzer.Total(r.Summaries))
+	}
+	if r.Diff != nil {
+		output.Diff = toJSONDiff(*r.Diff)
+	}
+
+	return encodeJSON(w, output)
+}
+
+// PrintWorkloadCostJSON outputs a report's workload costs in JSON format.
+// Namespace summaries count each workload's replicas as pods; orphaned claims are
+// listed and included in the totals as for PrintCostJSON.
+func PrintWorkloadCostJSON(w io.Writer, r Report) error {
+	workloads := make([]jsonWorkloadCost, len(r.Workloads))
+	for i, c := range r.Workloads {
+		workloads[i] = jsonWorkloadCost{
+			Kind:        c.Kind,
+			Name:        c.Name,
+			Namespace:   c.Namespace,
+			Replicas:    c.Replicas,
+			RequestRule: c.RequestRule,
+			Hourly:      toJSONResourceCost(c.Hourly),
+			Daily:       toJSONResourceCost(c.Daily),
+			Monthly:     toJSONResourceCost(c.Monthly),
+		}
+	}
+
+	namespaces := make([]jsonNamespaceSummary, len(r.Summaries))
+	for i, s := range r.Summaries {
+		namespaces[i] = toJSONNamespaceSummary(s)
+	}
+
+	output := jsonWorkloadOutput{
+		Metadata:       toJSONMetadata(r.Metadata),
+		Namespace:      r.Metadata.Namespace,
+		Workloads:      workloads,
+		OrphanedClaims: toJSONClaimCosts(r.Orphans),
+		Namespaces:     namespaces,
+		Summary:        toJSONNamespaceSummary(analyzer.Total(r.Summaries)),
+	}
+	if r.Diff != nil {
+		output.Diff = toJSONDiff(*r.Diff)
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
+	return encodeJSON(w, output)
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(output); err != nil {
+	if err := encoder.Encode(v); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
-
 	return nil
 }
+
+// toJSONMetadata omits metadata from reports built without a generation time
+func toJSONMetadata(m Metadata) *jsonMetadata {
+	if m.GeneratedAt.IsZero() {
+		return nil
+	}
+	return &jsonMetadata{
+		GeneratedAt: m.GeneratedAt,
+		Scope:       m.Scope,
+		Rates: jsonRates{
+			Currency:             m.Rates.Currency,
+			Source:               m.Rates.Source,
+			CPUPerCorePerHour:    m.Rates.CPUPerCorePerHour,
+			MemoryPerGBPerHour:   m.Rates.MemoryPerGBPerHour,
+			StoragePerGBPerMonth: m.Rates.StoragePerGBPerMonth,
+		},
+	}
+}
+
+func toJSONResourceCost(c calculator.ResourceCost) jsonResourceCost {
+	return jsonResourceCost{
+		CPUCost:     c.CPUCost,
+		MemoryCost:  c.MemoryCost,
+		Extended:    c.Extended,
+		StorageCost: c.StorageCost,
+		TotalCost:   c.TotalCost,
+	}
+}
+
+func toJSONClaimCosts(orphans []calculator.ClaimCost) []jsonClaimCost {
+	var claims []jsonClaimCost
+	for _, o := range orphans {
+		claims = append(claims, jsonClaimCost{
+			Name:         o.Name,
+			Namespace:    o.Namespace,
+			StorageClass: o.StorageClass,
+			CapacityGB:   o.CapacityGB,
+			HourlyCost:   o.Hourly,
+			DailyCost:    o.Daily,
+			MonthlyCost:  o.Monthly,
+		})
+	}
+	return claims
+}
+
+func toJSONUsage(c calculator.PodCost) *jsonUsage {
+	if c.Usage == nil {
+		return nil
+	}
+	u := &jsonUsage{
+		CPURequestCores: c.Usage.CPURequestCores,
+		CPUUsageCores:   c.Usage.CPUUsageCores,
+		MemoryRequestGB: c.Usage.MemoryRequestGB,
+		MemoryUsageGB:   c.Usage.MemoryUsageGB,
+		HourlyCost:      c.Usage.Hourly.TotalCost,
+		DailyCost:       c.Usage.Daily.TotalCost,
+		MonthlyCost:     c.Usage.Monthly.TotalCost,
+	}
+	if efficiency, ok := c.Efficiency(); ok {
+		u.Efficiency = &efficiency
+	}
+	return u
+}
+
+func toJSONNamespaceSummary(s analyzer.NamespaceSummary) jsonNamespaceSummary {
+	var efficiency *float64
+	if e, ok := s.Efficiency(); ok {
+		efficiency = &e
+	}
+	return jsonNamespaceSummary{
+		Namespace:   s.Namespace,
+		TotalPods:   s.TotalPods,
+		HourlyCost:  s.HourlyCost,
+		DailyCost:   s.DailyCost,
+		MonthlyCost: s.MonthlyCost,
+
+		OrphanedClaims:      s.OrphanedClaims,
+		OrphanedMonthlyCost: s.OrphanedMonthlyCost,
+
+		UsageMonthlyCost: s.UsageMonthlyCost,
+		Efficiency:       efficiency,
+
+		IdleMonthlyCost: s.IdleMonthlyCost,
+	}
+}
+
+func toJSONShared(shared *analyzer.Shared) *jsonShared {
+	out := &jsonShared{
+		Strategy:   shared.Strategy,
+		Namespaces: make([]jsonNamespaceSummary, len(shared.Pool)),
+		Allocated:  len(shared.Shares) > 0,
+	}
+	for i, s := range shared.Pool {
+		out.Namespaces[i] = toJSONNamespaceSummary(s)
+		out.MonthlyCost += s.MonthlyCost
+	}
+	return out
+}
+
+// withSharedCosts breaks a summary's monthly cost into direct and shared costs
+func withSharedCosts(out *jsonNamespaceSummary, s analyzer.NamespaceSummary) {
+	direct, shared := s.DirectMonthlyCost(), s.SharedMonthlyCost
+	out.DirectMonthlyCost, out.SharedMonthlyCost = &direct, &shared
+}
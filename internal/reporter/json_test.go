@@ -1,26 +1,30 @@
 package reporter
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"strings"
 	"testing"
 
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
 )
 
-// Note: This test cannot use t.Parallel() because captureStdout modifies os.Stdout,
-// which is global state. Running these tests in parallel would cause interference.
 func TestPrintCostJSON(t *testing.T) {
+	t.Parallel()
 	tests := []struct {
-		name              string
-		namespace         string
-		costs             []calculator.PodCost
-		wantNamespace     string
-		wantPodCount      int
-		wantFirstPodName  string
-		wantHourlyTotal   float64
-		wantMonthlyCost   float64
-		wantSummaryPods   int
+		name               string
+		namespace          string
+		costs              []calculator.PodCost
+		wantNamespace      string
+		wantPodCount       int
+		wantFirstPodName   string
+		wantHourlyTotal    float64
+		wantMonthlyCost    float64
+		wantSummaryPods    int
 		wantSummaryMonthly float64
+		wantNamespaces     []string
 	}{
 		{
 			name:      "single pod",
@@ -53,6 +57,22 @@ func TestPrintCostJSON(t *testing.T) {
 			wantMonthlyCost:    10.95,
 			wantSummaryPods:    1,
 			wantSummaryMonthly: 10.95,
+			wantNamespaces:     []string{"default"},
+		},
+		{
+			name:      "multiple namespaces",
+			namespace: "",
+			costs: []calculator.PodCost{
+				{Name: "api", Namespace: "team-a", Monthly: calculator.ResourceCost{TotalCost: 30}},
+				{Name: "worker", Namespace: "team-b", Monthly: calculator.ResourceCost{TotalCost: 10}},
+				{Name: "web", Namespace: "team-a", Monthly: calculator.ResourceCost{TotalCost: 5}},
+			},
+			wantNamespace:      "",
+			wantPodCount:       3,
+			wantFirstPodName:   "api",
+			wantSummaryPods:    3,
+			wantSummaryMonthly: 45,
+			wantNamespaces:     []string{"team-a", "team-b"},
 		},
 		{
 			name:               "empty input",
@@ -67,11 +87,12 @@ func TestPrintCostJSON(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output := captureStdout(t, func() {
-				if err := PrintCostJSON(tt.namespace, tt.costs); err != nil {
-					t.Fatalf("PrintCostJSON failed: %v", err)
-				}
-			})
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := PrintCostJSON(&buf, NewPodReport(Metadata{Namespace: tt.namespace}, tt.costs, nil, analyzer.Allocations{})); err != nil {
+				t.Fatalf("PrintCostJSON failed: %v", err)
+			}
+			output := buf.String()
 
 			// Parse JSON output
 			var jsonOut jsonOutput
@@ -107,6 +128,155 @@ func TestPrintCostJSON(t *testing.T) {
 			if jsonOut.Summary.MonthlyCost != tt.wantSummaryMonthly {
 				t.Errorf("summary monthly_cost: got %.2f, want %.2f", jsonOut.Summary.MonthlyCost, tt.wantSummaryMonthly)
 			}
+
+			// Verify per-namespace summaries
+			if len(jsonOut.Namespaces) != len(tt.wantNamespaces) {
+				t.Fatalf("namespace summary count: got %d, want %d", len(jsonOut.Namespaces), len(tt.wantNamespaces))
+			}
+			for i, ns := range tt.wantNamespaces {
+				if jsonOut.Namespaces[i].Namespace != ns {
+					t.Errorf("namespace summary %d: got %q, want %q", i, jsonOut.Namespaces[i].Namespace, ns)
+				}
+			}
 		})
 	}
 }
+
+func TestPrintWorkloadCostJSON(t *testing.T) {
+	t.Parallel()
+	costs := []calculator.WorkloadCost{
+		{Kind: "Deployment", Name: "api", Namespace: "default", Replicas: 3, Monthly: calculator.ResourceCost{TotalCost: 30}},
+		{Kind: "DaemonSet", Name: "agent", Namespace: "default", Replicas: 2, Monthly: calculator.ResourceCost{TotalCost: 4}},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintWorkloadCostJSON(&buf, NewWorkloadReport(Metadata{Namespace: "default"}, costs, nil)); err != nil {
+		t.Fatalf("PrintWorkloadCostJSON failed: %v", err)
+	}
+	output := buf.String()
+
+	var jsonOut jsonWorkloadOutput
+	if err := json.Unmarshal([]byte(output), &jsonOut); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+
+	if len(jsonOut.Workloads) != 2 {
+		t.Fatalf("workload count: got %d, want 2", len(jsonOut.Workloads))
+	}
+
+	first := jsonOut.Workloads[0]
+	if first.Kind != "Deployment" || first.Name != "api" || first.Replicas != 3 {
+		t.Errorf("first workload: got %s/%s x%d, want Deployment/api x3", first.Kind, first.Name, first.Replicas)
+	}
+
+	if jsonOut.Summary.TotalPods != 5 {
+		t.Errorf("summary total_pods: got %d, want 5", jsonOut.Summary.TotalPods)
+	}
+
+	if jsonOut.Summary.MonthlyCost != 34 {
+		t.Errorf("summary monthly_cost: got %.2f, want 34.00", jsonOut.Summary.MonthlyCost)
+	}
+}
+
+func TestReadJSONReport(t *testing.T) {
+	t.Parallel()
+	monthly := func(total float64) calculator.ResourceCost {
+		return calculator.ResourceCost{CPUCost: total / 2, MemoryCost: total / 2, TotalCost: total}
+	}
+	pods := []calculator.PodCost{
+		{Name: "web-1", Namespace: "shop", OwnerKind: "Deployment", OwnerName: "web", Monthly: monthly(20)},
+		{Name: "web-2", Namespace: "shop", OwnerKind: "Deployment", OwnerName: "web", Monthly: monthly(20)},
+		{Name: "debug", Namespace: "batch", Monthly: monthly(5)},
+	}
+	workloads := []calculator.WorkloadCost{
+		{Kind: "Deployment", Name: "web", Namespace: "shop", Replicas: 3, Monthly: monthly(60)},
+	}
+
+	tests := []struct {
+		name          string
+		print         func(w io.Writer) error
+		wantWorkloads map[string]float64
+		wantTotal     float64
+	}{
+		{
+			name: "pods are rolled up by owner",
+			print: func(w io.Writer) error {
+				return PrintCostJSON(w, NewPodReport(Metadata{}, pods, nil, analyzer.Allocations{}))
+			},
+			wantWorkloads: map[string]float64{"shop/Deployment/web": 40, "batch/Pod/debug": 5},
+			wantTotal:     45,
+		},
+		{
+			name: "workloads",
+			print: func(w io.Writer) error {
+				return PrintWorkloadCostJSON(w, NewWorkloadReport(Metadata{Namespace: "shop"}, workloads, nil))
+			},
+			wantWorkloads: map[string]float64{"shop/Deployment/web": 60},
+			wantTotal:     60,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := tt.print(&buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			output := buf.String()
+
+			report, err := ReadJSONReport(strings.NewReader(output))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := make(map[string]float64)
+			for _, w := range report.Workloads {
+				got[w.Namespace+"/"+w.Kind+"/"+w.Name] = w.Monthly.TotalCost
+			}
+			if len(got) != len(tt.wantWorkloads) {
+				t.Errorf("workloads: got %v, want %v", got, tt.wantWorkloads)
+			}
+			for key, want := range tt.wantWorkloads {
+				if got[key] != want {
+					t.Errorf("%s: got %v, want %v", key, got[key], want)
+				}
+			}
+			if report.Summary.MonthlyCost != tt.wantTotal {
+				t.Errorf("summary: got %v, want %v", report.Summary.MonthlyCost, tt.wantTotal)
+			}
+		})
+	}
+
+	if _, err := ReadJSONReport(strings.NewReader(`{"pods": []}`)); err == nil {
+		t.Error("expected an error for a report without a summary")
+	}
+}
+
+func TestPrintDiffJSON(t *testing.T) {
+	t.Parallel()
+	diff := analyzer.CostDiff{
+		Before:    25,
+		After:     10,
+		Unchanged: 2,
+		Workloads: []analyzer.WorkloadDiff{
+			{Change: analyzer.ChangeRemoved, Namespace: "batch", Kind: "Job", Name: "import", BeforeReplicas: 1, Before: 15},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := PrintDiffJSON(&buf, diff); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	var result jsonDiffOutput
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	if result.Delta != -15 || result.Unchanged != 2 {
+		t.Errorf("got delta %v and %d unchanged, want -15 and 2", result.Delta, result.Unchanged)
+	}
+	if len(result.Workloads) != 1 || result.Workloads[0].Change != "removed" || result.Workloads[0].Delta != -15 {
+		t.Errorf("workloads: got %+v, want one removed workload with delta -15", result.Workloads)
+	}
+}
@@ -0,0 +1,311 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// DefaultMarkdownTop is the number of pods or workloads listed before the rest are
+// collapsed in Markdown output
+const DefaultMarkdownTop = 10
+
+func init() {
+	Register("markdown", ReporterFunc(PrintMarkdown))
+}
+
+// PrintMarkdown renders a report as GitHub-flavoured Markdown for merge request
+// comments: a headline total, the namespace summary, changes against a baseline
+// when the report has one, and the most expensive pods or workloads. The remaining
+// rows, orphaned claims, shared namespaces, node capacity and the rates used are
+// in collapsible sections.
+func PrintMarkdown(w io.Writer, r Report) error {
+	total := analyzer.Total(r.Summaries)
+	unit := "pods"
+	if r.ByWorkload() {
+		unit = "workloads"
+	}
+	header, rows := r.markdownRows()
+	fmt.Fprintf(w, "### Estimated cost: $%.2f/month\n\n", total.MonthlyCost)
+	fmt.Fprintf(w, "%d %s", len(rows), unit)
+	if r.Metadata.Scope != "" {
+		fmt.Fprintf(w, " in %s", r.Metadata.Scope)
+	}
+	fmt.Fprintf(w, ", $%.4f/hour, $%.2f/day.\n\n", total.HourlyCost, total.DailyCost)
+
+	writeMarkdownSummary(w, r.Summaries)
+
+	if r.Diff != nil {
+		fmt.Fprintf(w, "\n#### Changes since baseline\n\n")
+		PrintDiffMarkdown(w, *r.Diff)
+	}
+
+	top := r.Top
+	if top <= 0 {
+		top = DefaultMarkdownTop
+	}
+	if len(rows) > 0 {
+		shown := min(top, len(rows))
+		fmt.Fprintf(w, "\n#### Top %d %s by monthly cost\n\n", shown, unit)
+		writeMarkdownTable(w, header, rows[:shown])
+		if len(rows) > shown {
+			writeMarkdownDetails(w, fmt.Sprintf("%d more %s", len(rows)-shown, unit), func() {
+				writeMarkdownTable(w, header, rows[shown:])
+			})
+		}
+	}
+
+	if len(r.Orphans) > 0 {
+		orphaned := 0.0
+		for _, o := range r.Orphans {
+			orphaned += o.Monthly
+		}
+		writeMarkdownDetails(w, fmt.Sprintf("Orphaned storage: %d PVCs, $%.2f/month", len(r.Orphans), orphaned), func() {
+			rows := make([][]string, len(r.Orphans))
+			for i, o := range r.Orphans {
+				rows[i] = []string{o.Namespace, markdownCode(o.Name), orDash(o.StorageClass), fmt.Sprintf("%.1f", o.CapacityGB), fmt.Sprintf("$%.2f", o.Monthly)}
+			}
+			writeMarkdownTable(w, markdownColumns("Namespace", "Claim", "Storage class", "Size (GB):", "Monthly:"), rows)
+		})
+	}
+
+	if shared := r.Allocations.Shared; shared != nil && len(shared.Pool) > 0 {
+		pool := analyzer.Total(shared.Pool)
+		title := fmt.Sprintf("Shared namespaces: $%.2f/month allocated %s", pool.MonthlyCost, describeStrategy(shared.Strategy))
+		if len(shared.Shares) == 0 {
+			title = fmt.Sprintf("Shared namespaces: $%.2f/month not allocated", pool.MonthlyCost)
+		}
+		writeMarkdownDetails(w, title, func() {
+			rows := make([][]string, len(shared.Pool))
+			for i, s := range shared.Pool {
+				rows[i] = []string{s.Namespace, fmt.Sprintf("%d", s.TotalPods), fmt.Sprintf("$%.2f", s.MonthlyCost)}
+			}
+			writeMarkdownTable(w, markdownColumns("Namespace", "Pods:", "Monthly:"), rows)
+		})
+	}
+
+	if idle := r.Allocations.Idle; idle != nil {
+		nodes := analyzer.NodeTotal(idle.Nodes)
+		writeMarkdownDetails(w, fmt.Sprintf("Node capacity: $%.2f/month, $%.2f/month idle", nodes.Monthly.TotalCost, nodes.IdleMonthly.TotalCost), func() {
+			rows := make([][]string, len(idle.Nodes))
+			for i, n := range idle.Nodes {
+				rows[i] = []string{
+					n.Name, orDash(n.InstanceType), fmt.Sprintf("%d", n.Pods),
+					formatCores(n.CPURequestedCores) + " / " + formatCores(n.CPUAllocatableCores),
+					formatGB(n.MemoryRequestedGB) + " / " + formatGB(n.MemoryAllocatableGB),
+					fmt.Sprintf("$%.2f", n.Monthly.TotalCost), fmt.Sprintf("$%.2f", n.IdleMonthly.TotalCost),
+				}
+			}
+			writeMarkdownTable(w, markdownColumns("Node", "Instance type", "Pods:", "CPU requested:", "Memory requested:", "Monthly:", "Idle monthly:"), rows)
+		})
+	}
+
+	if rates := r.Metadata.Rates; rates.CPUPerCorePerHour > 0 || rates.MemoryPerGBPerHour > 0 {
+		writeMarkdownDetails(w, "Rates", func() {
+			fmt.Fprintf(w, "- CPU: $%.4f per core-hour\n- Memory: $%.4f per GB-hour\n", rates.CPUPerCorePerHour, rates.MemoryPerGBPerHour)
+			if rates.Source != "" {
+				fmt.Fprintf(w, "- Source: %s\n", rates.Source)
+			}
+			if !r.Metadata.GeneratedAt.IsZero() {
+				fmt.Fprintf(w, "- Generated: %s\n", r.Metadata.GeneratedAt.UTC().Format("2006-01-02 15:04 MST"))
+			}
+		})
+	}
+
+	if r.Metadata.Note != "" {
+		fmt.Fprintf(w, "\n> %s\n", r.Metadata.Note)
+	}
+	return nil
+}
+
+// writeMarkdownSummary writes the namespace summary table with the same optional
+// columns as PrintNamespaceSummary
+func writeMarkdownSummary(w io.Writer, summaries []analyzer.NamespaceSummary) {
+	total := analyzer.Total(summaries)
+	showOrphaned := total.OrphanedClaims > 0
+	_, showUsage := total.Efficiency()
+	showIdle := total.IdleMonthlyCost > 0
+	showShared := total.SharedMonthlyCost > 0
+
+	header := markdownColumns("Namespace", "Pods:", "Hourly:", "Daily:", "Monthly:")
+	if showOrphaned {
+		header = append(header, markdownColumns("Orphaned storage:")...)
+	}
+	if showIdle {
+		header = append(header, markdownColumns("Idle share:")...)
+	}
+	if showShared {
+		header = append(header, markdownColumns("Direct:", "Shared:")...)
+	}
+	if showUsage {
+		header = append(header, markdownColumns("Usage monthly:", "Efficiency:")...)
+	}
+
+	row := func(name string, s analyzer.NamespaceSummary) []string {
+		r := []string{name, fmt.Sprintf("%d", s.TotalPods), fmt.Sprintf("$%.4f", s.HourlyCost), fmt.Sprintf("$%.2f", s.DailyCost), fmt.Sprintf("$%.2f", s.MonthlyCost)}
+		if showOrphaned {
+			r = append(r, fmt.Sprintf("$%.2f", s.OrphanedMonthlyCost))
+		}
+		if showIdle {
+			r = append(r, fmt.Sprintf("$%.2f", s.IdleMonthlyCost))
+		}
+		if showShared {
+			r = append(r, fmt.Sprintf("$%.2f", s.DirectMonthlyCost()), fmt.Sprintf("$%.2f", s.SharedMonthlyCost))
+		}
+		if showUsage {
+			efficiency, ok := s.Efficiency()
+			r = append(r, fmt.Sprintf("$%.2f", s.UsageMonthlyCost), formatEfficiency(efficiency, ok))
+		}
+		return r
+	}
+
+	rows := make([][]string, 0, len(summaries)+1)
+	for _, s := range summaries {
+		rows = append(rows, row(s.Namespace, s))
+	}
+	totalRow := row("Total", total)
+	for i, cell := range totalRow {
+		totalRow[i] = "**" + cell + "**"
+	}
+	writeMarkdownTable(w, header, append(rows, totalRow))
+}
+
+// markdownRows renders the pods or workloads with their CPU and memory split, and
+// storage, extended resource and usage columns when any row has them
+func (r Report) markdownRows() ([]markdownColumn, [][]string) {
+	if r.ByWorkload() {
+		extended := extendedNames(r.Workloads, func(c calculator.WorkloadCost) map[string]float64 { return c.Monthly.Extended })
+		showStorage := false
+		for _, c := range r.Workloads {
+			showStorage = showStorage || c.Hourly.StorageCost > 0
+		}
+		header := markdownColumns("Namespace", "Kind", "Name", "Replicas:")
+		header = append(header, markdownCostColumns(showStorage, extended)...)
+		rows := make([][]string, len(r.Workloads))
+		for i, c := range r.Workloads {
+			rows[i] = append([]string{c.Namespace, c.Kind, markdownCode(c.Name), fmt.Sprintf("%d", c.Replicas)},
+				markdownCostCells(showStorage, extended, c.Hourly, c.Monthly)...)
+		}
+		return header, rows
+	}
+
+	extended := extendedNames(r.Pods, func(c calculator.PodCost) map[string]float64 { return c.Monthly.Extended })
+	showStorage, showUsage := hasStorage(r.Pods), hasUsage(r.Pods)
+	header := markdownColumns("Namespace", "Pod")
+	header = append(header, markdownCostColumns(showStorage, extended)...)
+	if showUsage {
+		header = append(header, markdownColumns("Usage monthly:", "Efficiency:")...)
+	}
+	rows := make([][]string, len(r.Pods))
+	for i, c := range r.Pods {
+		rows[i] = append([]string{c.Namespace, markdownCode(c.Name)}, markdownCostCells(showStorage, extended, c.Hourly, c.Monthly)...)
+		if showUsage {
+			if c.Usage == nil {
+				rows[i] = append(rows[i], "-", "-")
+			} else {
+				efficiency, ok := c.Efficiency()
+				rows[i] = append(rows[i], fmt.Sprintf("$%.2f", c.Usage.Monthly.TotalCost), formatEfficiency(efficiency, ok))
+			}
+		}
+	}
+	return header, rows
+}
+
+func markdownCostColumns(showStorage bool, extended []string) []markdownColumn {
+	header := markdownColumns("Hourly:", "Monthly:", "CPU monthly:", "Memory monthly:")
+	if showStorage {
+		header = append(header, markdownColumns("Storage monthly:")...)
+	}
+	for _, name := range extended {
+		header = append(header, markdownColumn{name: name + " monthly", right: true})
+	}
+	return header
+}
+
+func markdownCostCells(showStorage bool, extended []string, hourly, monthly calculator.ResourceCost) []string {
+	cells := []string{
+		fmt.Sprintf("$%.4f", hourly.TotalCost),
+		fmt.Sprintf("$%.2f", monthly.TotalCost),
+		fmt.Sprintf("$%.2f", monthly.CPUCost),
+		fmt.Sprintf("$%.2f", monthly.MemoryCost),
+	}
+	if showStorage {
+		cells = append(cells, fmt.Sprintf("$%.2f", monthly.StorageCost))
+	}
+	for _, name := range extended {
+		if cost, ok := monthly.Extended[name]; ok {
+			cells = append(cells, fmt.Sprintf("$%.2f", cost))
+		} else {
+			cells = append(cells, "-")
+		}
+	}
+	return cells
+}
+
+// markdownColumn is a table column; right aligns numbers
+type markdownColumn struct {
+	name  string
+	right bool
+}
+
+// markdownColumns builds columns from names; a trailing ":" marks a right-aligned column
+func markdownColumns(names ...string) []markdownColumn {
+	columns := make([]markdownColumn, len(names))
+	for i, name := range names {
+		trimmed := strings.TrimSuffix(name, ":")
+		columns[i] = markdownColumn{name: trimmed, right: trimmed != name}
+	}
+	return columns
+}
+
+func writeMarkdownTable(w io.Writer, header []markdownColumn, rows [][]string) {
+	var names, rules []string
+	for _, c := range header {
+		names = append(names, markdownEscape(c.name))
+		rule := strings.Repeat("-", len(c.name)+2)
+		if c.right {
+			rule = rule[:len(rule)-1] + ":"
+		}
+		rules = append(rules, rule)
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(names, " | "))
+	fmt.Fprintf(w, "|%s|\n", strings.Join(rules, "|"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = markdownEscape(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// writeMarkdownDetails wraps the output of body in a collapsible section
+func writeMarkdownDetails(w io.Writer, summary string, body func()) {
+	fmt.Fprintf(w, "\n<details>\n<summary>%s</summary>\n\n", htmlEscaper.Replace(summary))
+	body()
+	fmt.Fprintf(w, "\n</details>\n")
+}
+
+var (
+	markdownEscaper = strings.NewReplacer("|", `\|`)
+	htmlEscaper     = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// markdownEscape keeps pipes in cells from splitting the table
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func markdownCode(s string) string {
+	return "`" + s + "`"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,104 @@
+package reporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestPrintMarkdown(t *testing.T) {
+	t.Parallel()
+	pods := []calculator.PodCost{
+		{Name: "web-1", Namespace: "shop", Monthly: calculator.ResourceCost{CPUCost: 20, MemoryCost: 10, TotalCost: 30}},
+		{Name: "web-2", Namespace: "shop", Monthly: calculator.ResourceCost{CPUCost: 14, MemoryCost: 6, TotalCost: 20}},
+		{Name: "db-0", Namespace: "data", Monthly: calculator.ResourceCost{CPUCost: 8, MemoryCost: 2, TotalCost: 10}},
+	}
+	diff := analyzer.CostDiff{
+		Before: 50,
+		After:  60,
+		Workloads: []analyzer.WorkloadDiff{
+			{Change: analyzer.ChangeAdded, Namespace: "data", Kind: "Pod", Name: "db-0", AfterReplicas: 1, After: 10},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		report  Report
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "all pods",
+			report: NewPodReport(Metadata{Scope: "2 namespaces", Note: "Estimates only."}, pods, nil, analyzer.Allocations{}),
+			want: []string{
+				"### Estimated cost: $60.00/month",
+				"3 pods in 2 namespaces",
+				"| Namespace | Pods | Hourly | Daily | Monthly |\n|-----------|-----:|",
+				"| **Total** | **3** |",
+				"#### Top 3 pods by monthly cost",
+				"| shop | `web-1` | $0.0000 | $30.00 | $20.00 | $10.00 |",
+				"> Estimates only.",
+			},
+			notWant: []string{"<details>", "Changes since baseline"},
+		},
+		{
+			name: "top pods with diff",
+			report: func() Report {
+				r := NewPodReport(Metadata{}, pods, nil, analyzer.Allocations{})
+				r.Top = 1
+				r.Diff = &diff
+				return r
+			}(),
+			want: []string{
+				"#### Changes since baseline",
+				"**This change adds $10.00/month",
+				"| added | data | Pod | `db-0` | 1 | - | $10.00 | +$10.00 |",
+				"#### Top 1 pods by monthly cost",
+				"<details>\n<summary>2 more pods</summary>\n\n| Namespace | Pod |",
+				"| data | `db-0` |",
+			},
+		},
+		{
+			name: "workloads",
+			report: NewWorkloadReport(Metadata{}, []calculator.WorkloadCost{
+				{Kind: "Deployment", Name: "web", Namespace: "shop", Replicas: 3, Monthly: calculator.ResourceCost{TotalCost: 90}},
+			}, []calculator.ClaimCost{{Name: "old-data", Namespace: "shop", CapacityGB: 10, Monthly: 1}}),
+			want: []string{
+				"1 workloads",
+				"| shop | Deployment | `web` | 3 |",
+				"<summary>Orphaned storage: 1 PVCs, $1.00/month</summary>",
+				"| shop | `old-data` | - | 10.0 | $1.00 |",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := PrintMarkdown(&buf, tt.report); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	t.Parallel()
+	if got, want := markdownEscape("team=a|b"), `team=a\|b`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// Row types of node CSV output, and of the idle node rows in pod CSV output
+const (
+	csvRowNode     = "node"
+	csvRowIdleNode = "idle_node"
+)
+
+// PrintNodeTable displays each node's allocatable and requested CPU and memory with
+// the monthly cost of its capacity and of the unrequested remainder, followed by
+// the cluster total
+func PrintNodeTable(w io.Writer, nodes []calculator.NodeCost) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "NODE\tINSTANCE TYPE\tPODS\tCPU ALLOC\tCPU REQ\tMEM ALLOC\tMEM REQ\tMONTHLY\tIDLE MONTHLY\tIDLE")
+	row := func(name, instanceType string, n calculator.NodeCost) {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t$%.2f\t$%.2f\t%.1f%%\n",
+			name,
+			instanceType,
+			n.Pods,
+			formatCores(n.CPUAllocatableCores),
+			formatCores(n.CPURequestedCores),
+			formatGB(n.MemoryAllocatableGB),
+			formatGB(n.MemoryRequestedGB),
+			n.Monthly.TotalCost,
+			n.IdleMonthly.TotalCost,
+			n.IdleFraction()*100,
+		)
+	}
+	for _, n := range nodes {
+		instanceType := n.InstanceType
+		if instanceType == "" {
+			instanceType = "-"
+		}
+		row(n.Name, instanceType, n)
+	}
+	row("TOTAL", "", analyzer.NodeTotal(nodes))
+}
+
+type jsonNodeOutput struct {
+	Nodes   []jsonNodeCost `json:"nodes"`
+	Summary jsonNodeCost   `json:"summary"`
+	// Distributed is set in analyze output when the idle cost was shared out
+	// across namespaces
+	Distributed bool `json:"distributed,omitempty"`
+}
+
+type jsonNodeCost struct {
+	Name                string           `json:"name,omitempty"`
+	InstanceType        string           `json:"instance_type,omitempty"`
+	Pods                int              `json:"pods"`
+	CPUAllocatableCores float64          `json:"cpu_allocatable_cores"`
+	MemoryAllocatableGB float64          `json:"memory_allocatable_gb"`
+	CPURequestedCores   float64          `json:"cpu_requested_cores"`
+	MemoryRequestedGB   float64          `json:"memory_requested_gb"`
+	Hourly              jsonResourceCost `json:"hourly"`
+	Daily               jsonResourceCost `json:"daily"`
+	Monthly             jsonResourceCost `json:"monthly"`
+	IdleHourly          jsonResourceCost `json:"idle_hourly"`
+	IdleDaily           jsonResourceCost `json:"idle_daily"`
+	IdleMonthly         jsonResourceCost `json:"idle_monthly"`
+	IdleFraction        float64          `json:"idle_fraction"`
+}
+
+// PrintNodeJSON outputs node capacity and idle costs in JSON format, with the
+// cluster total under "summary"
+func PrintNodeJSON(w io.Writer, nodes []calculator.NodeCost) error {
+	return encodeJSON(w, toJSONNodeOutput(nodes, false))
+}
+
+func toJSONNodeOutput(nodes []calculator.NodeCost, distributed bool) jsonNodeOutput {
+	out := jsonNodeOutput{
+		Nodes:       make([]jsonNodeCost, len(nodes)),
+		Summary:     toJSONNodeCost(analyzer.NodeTotal(nodes)),
+		Distributed: distributed,
+	}
+	for i, n := range nodes {
+		out.Nodes[i] = toJSONNodeCost(n)
+	}
+	return out
+}
+
+func toJSONNodeCost(n calculator.NodeCost) jsonNodeCost {
+	return jsonNodeCost{
+		Name:                n.Name,
+		InstanceType:        n.InstanceType,
+		Pods:                n.Pods,
+		CPUAllocatableCores: n.CPUAllocatableCores,
+		MemoryAllocatableGB: n.MemoryAllocatableGB,
+		CPURequestedCores:   n.CPURequestedCores,
+		MemoryRequestedGB:   n.MemoryRequestedGB,
+		Hourly:              toJSONResourceCost(n.Hourly),
+		Daily:               toJSONResourceCost(n.Daily),
+		Monthly:             toJSONResourceCost(n.Monthly),
+		IdleHourly:          toJSONResourceCost(n.IdleHourly),
+		IdleDaily:           toJSONResourceCost(n.IdleDaily),
+		IdleMonthly:         toJSONResourceCost(n.IdleMonthly),
+		IdleFraction:        n.IdleFraction(),
+	}
+}
+
+// PrintNodeCSV outputs node capacity and idle costs in CSV format: one row per node
+// and a total row, with the capacity cost columns followed by the same columns for
+// the idle remainder
+func PrintNodeCSV(w io.Writer, nodes []calculator.NodeCost) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{
+		"row_type", "node", "instance_type", "pod_count",
+		"cpu_allocatable_cores", "memory_allocatable_gb", "cpu_requested_cores", "memory_requested_gb",
+	}
+	header = append(header, csvCostHeader...)
+	for _, column := range csvCostHeader {
+		header = append(header, "idle_"+column)
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	row := func(rowType string, n calculator.NodeCost) []string {
+		r := []string{
+			rowType, n.Name, n.InstanceType, strconv.Itoa(n.Pods),
+			fmt.Sprintf("%.3f", n.CPUAllocatableCores),
+			fmt.Sprintf("%.3f", n.MemoryAllocatableGB),
+			fmt.Sprintf("%.3f", n.CPURequestedCores),
+			fmt.Sprintf("%.3f", n.MemoryRequestedGB),
+		}
+		r = append(r, costCSVColumns(n.Hourly, n.Daily, n.Monthly)...)
+		return append(r, costCSVColumns(n.IdleHourly, n.IdleDaily, n.IdleMonthly)...)
+	}
+	for _, n := range nodes {
+		if err := cw.Write(row(csvRowNode, n)); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	if err := cw.Write(row(csvRowTotal, analyzer.NodeTotal(nodes))); err != nil {
+		return fmt.Errorf("failed to write CSV total row: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/recommend"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// PrintRecommendationTable displays per-container request recommendations with
+// the projected monthly saving, followed by the total saving
+func PrintRecommendationTable(w io.Writer, recs []recommend.Recommendation) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(tw, "NAMESPACE\tPOD\tCONTAINER\tSAMPLES\tCPU REQ\tRECOMMENDED\tMEM REQ\tRECOMMENDED\tMONTHLY\tRECOMMENDED MONTHLY\tSAVING")
+	for _, r := range recs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t$%.2f\t$%.2f\t$%.2f\n",
+			r.Namespace,
+			r.Pod,
+			r.Container,
+			r.Samples,
+			quantityCell(r.CurrentCPU),
+			quantityCell(r.CPU),
+			quantityCell(r.CurrentMemory),
+			quantityCell(r.Memory),
+			r.CurrentMonthly,
+			r.RecommendedMonthly,
+			r.MonthlySaving(),
+		)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nProjected Monthly Saving: $%.2f\n", recommend.TotalMonthlySaving(recs))
+}
+
+type jsonRecommendationOutput struct {
+	Percentile         float64              `json:"percentile"`
+	Headroom           float64              `json:"headroom"`
+	Containers         []jsonRecommendation `json:"containers"`
+	TotalMonthlySaving float64              `json:"total_monthly_saving"`
+}
+
+type jsonRecommendation struct {
+	Namespace   string            `json:"namespace"`
+	Pod         string            `json:"pod"`
+	Container   string            `json:"container"`
+	Samples     int               `json:"samples"`
+	Current     jsonContainerSize `json:"current"`
+	Recommended jsonContainerSize `json:"recommended"`
+	Saving      float64           `json:"monthly_saving"`
+}
+
+type jsonContainerSize struct {
+	CPURequest    string  `json:"cpu_request,omitempty"`
+	MemoryRequest string  `json:"memory_request,omitempty"`
+	CPULimit      string  `json:"cpu_limit,omitempty"`
+	MemoryLimit   string  `json:"memory_limit,omitempty"`
+	MonthlyCost   float64 `json:"monthly_cost"`
+}
+
+// PrintRecommendationJSON outputs recommendations in JSON format; opts records the
+// percentile and headroom they were computed with
+func PrintRecommendationJSON(w io.Writer, recs []recommend.Recommendation, opts recommend.Options) error {
+	containers := make([]jsonRecommendation, len(recs))
+	for i, r := range recs {
+		containers[i] = jsonRecommendation{
+			Namespace: r.Namespace,
+			Pod:       r.Pod,
+			Container: r.Container,
+			Samples:   r.Samples,
+			Current: jsonContainerSize{
+				CPURequest:    quantityValue(r.CurrentCPU),
+				MemoryRequest: quantityValue(r.CurrentMemory),
+				CPULimit:      quantityValue(r.CurrentCPULimit),
+				MemoryLimit:   quantityValue(r.CurrentMemoryLimit),
+				MonthlyCost:   r.CurrentMonthly,
+			},
+			Recommended: jsonContainerSize{
+				CPURequest:    quantityValue(r.CPU),
+				MemoryRequest: quantityValue(r.Memory),
+				CPULimit:      quantityValue(r.CPULimit),
+				MemoryLimit:   quantityValue(r.MemoryLimit),
+				MonthlyCost:   r.RecommendedMonthly,
+			},
+			Saving: r.MonthlySaving(),
+		}
+	}
+
+	return encodeJSON(w, jsonRecommendationOutput{
+		Percentile:         opts.Percentile,
+		Headroom:           opts.Headroom,
+		Containers:         containers,
+		TotalMonthlySaving: recommend.TotalMonthlySaving(recs),
+	})
+}
+
+// PrintRecommendationCSV outputs one row per container; unset requests and limits are empty
+func PrintRecommendationCSV(w io.Writer, recs []recommend.Recommendation) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	header := []string{
+		"namespace", "pod", "container", "samples",
+		"cpu_request", "memory_request", "cpu_limit", "memory_limit",
+		"recommended_cpu_request", "recommended_memory_request", "recommended_cpu_limit", "recommended_memory_limit",
+		"monthly_cost", "recommended_monthly_cost", "monthly_saving",
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, r := range recs {
+		row := []string{
+			r.Namespace, r.Pod, r.Container, strconv.Itoa(r.Samples),
+			quantityValue(r.CurrentCPU), quantityValue(r.CurrentMemory), quantityValue(r.CurrentCPULimit), quantityValue(r.CurrentMemoryLimit),
+			quantityValue(r.CPU), quantityValue(r.Memory), quantityValue(r.CPULimit), quantityValue(r.MemoryLimit),
+			fmt.Sprintf("%.2f", r.CurrentMonthly),
+			fmt.Sprintf("%.2f", r.RecommendedMonthly),
+			fmt.Sprintf("%.2f", r.MonthlySaving()),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	return nil
+}
+
+// quantityCell renders a quantity for tables, "-" when unset
+func quantityCell(q resource.Quantity) string {
+	if q.IsZero() {
+		return "-"
+	}
+	return q.String()
+}
+
+// quantityValue renders a quantity for JSON and CSV, empty when unset
+func quantityValue(q resource.Quantity) string {
+	if q.IsZero() {
+		return ""
+	}
+	return q.String()
+}
@@ -0,0 +1,117 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// SavedReport is the cost data of a JSON report written by analyze or estimate,
+// read back for comparison
+type SavedReport struct {
+	// Workloads lists the report's workloads, or its pods rolled up by owner
+	Workloads []calculator.WorkloadCost
+	// Namespaces and Summary are the report's namespace summaries and grand total
+	Namespaces []analyzer.NamespaceSummary
+	Summary    analyzer.NamespaceSummary
+}
+
+// jsonReport accepts both pod and workload JSON output
+type jsonReport struct {
+	Pods       []jsonPodCost          `json:"pods"`
+	Workloads  []jsonWorkloadCost     `json:"workloads"`
+	Namespaces []jsonNamespaceSummary `json:"namespaces"`
+	Summary    *jsonNamespaceSummary  `json:"summary"`
+}
+
+// ReadJSONReport decodes a report written by PrintCostJSON or PrintWorkloadCostJSON
+func ReadJSONReport(r io.Reader) (SavedReport, error) {
+	var in jsonReport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return SavedReport{}, fmt.Errorf("failed to decode JSON report: %w", err)
+	}
+	if in.Summary == nil {
+		return SavedReport{}, fmt.Errorf("not a kcost JSON report: no summary")
+	}
+
+	var report SavedReport
+	for _, w := range in.Workloads {
+		report.Workloads = append(report.Workloads, calculator.WorkloadCost{
+			Kind:        w.Kind,
+			Name:        w.Name,
+			Namespace:   w.Namespace,
+			Replicas:    w.Replicas,
+			RequestRule: w.RequestRule,
+			Hourly:      fromJSONResourceCost(w.Hourly),
+			Daily:       fromJSONResourceCost(w.Daily),
+			Monthly:     fromJSONResourceCost(w.Monthly),
+		})
+	}
+	if len(in.Pods) > 0 {
+		pods := make([]calculator.PodCost, len(in.Pods))
+		for i, p := range in.Pods {
+			pods[i] = calculator.PodCost{
+				Name:        p.Name,
+				Namespace:   p.Namespace,
+				OwnerKind:   p.OwnerKind,
+				OwnerName:   p.OwnerName,
+				RequestRule: p.RequestRule,
+				Hourly:      fromJSONResourceCost(p.Hourly),
+				Daily:       fromJSONResourceCost(p.Daily),
+				Monthly:     fromJSONResourceCost(p.Monthly),
+			}
+		}
+		report.Workloads = append(report.Workloads, analyzer.AggregateByOwner(pods)...)
+	}
+
+	report.Namespaces = make([]analyzer.NamespaceSummary, len(in.Namespaces))
+	for i, s := range in.Namespaces {
+		report.Namespaces[i] = fromJSONNamespaceSummary(s)
+	}
+	report.Summary = fromJSONNamespaceSummary(*in.Summary)
+	return report, nil
+}
+
+func fromJSONResourceCost(c jsonResourceCost) calculator.ResourceCost {
+	return calculator.ResourceCost{
+		CPUCost:     c.CPUCost,
+		MemoryCost:  c.MemoryCost,
+		Extended:    c.Extended,
+		StorageCost: c.StorageCost,
+		TotalCost:   c.TotalCost,
+	}
+}
+
+func fromJSONNamespaceSummary(s jsonNamespaceSummary) analyzer.NamespaceSummary {
+	summary := analyzer.NamespaceSummary{
+		Namespace:           s.Namespace,
+		TotalPods:           s.TotalPods,
+		HourlyCost:          s.HourlyCost,
+		DailyCost:           s.DailyCost,
+		MonthlyCost:         s.MonthlyCost,
+		OrphanedClaims:      s.OrphanedClaims,
+		OrphanedMonthlyCost: s.OrphanedMonthlyCost,
+		UsageMonthlyCost:    s.UsageMonthlyCost,
+		IdleMonthlyCost:     s.IdleMonthlyCost,
+	}
+	if s.SharedMonthlyCost != nil {
+		summary.SharedMonthlyCost = *s.SharedMonthlyCost
+	}
+	return summary
+}
+
+// IsJSONReport reports whether data is a kcost JSON report rather than, for
+// example, a JSON manifest
+func IsJSONReport(data []byte) bool {
+	var probe struct {
+		Kind    string          `json:"kind"`
+		Summary json.RawMessage `json:"summary"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Kind == "" && probe.Summary != nil
+}
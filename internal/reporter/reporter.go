@@ -0,0 +1,166 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+// Report is the cost model every output format renders: priced pods or workloads,
+// the claims no pod mounts, per-namespace summaries and how they were produced
+type Report struct {
+	Metadata Metadata
+	// Pods or Workloads hold the priced rows, rendered in order; Workloads is
+	// non-nil for reports priced per workload controller
+	Pods      []calculator.PodCost
+	Workloads []calculator.WorkloadCost
+	Orphans   []calculator.ClaimCost
+	// Summaries are the per-namespace totals, including orphaned claims and any
+	// idle or shared cost allocations
+	Summaries   []analyzer.NamespaceSummary
+	Allocations analyzer.Allocations
+	// Diff compares the report with a baseline, when one was given
+	Diff *analyzer.CostDiff
+	// Top limits how many pods or workloads summary formats list before
+	// collapsing the rest; zero uses the format's default
+	Top int
+}
+
+// Metadata describes how a report was produced
+type Metadata struct {
+	// Namespace is set when exactly one namespace was analyzed
+	Namespace string
+	// Scope describes what was analyzed, e.g. "namespace 'default'"
+	Scope       string
+	Rates       calculator.Rates
+	GeneratedAt time.Time
+	// Note follows table output, e.g. what the estimates are based on
+	Note string
+}
+
+// NewPodReport builds a report of pod costs, summarized per namespace with orphaned
+// claims and allocations applied
+func NewPodReport(meta Metadata, pods []calculator.PodCost, orphans []calculator.ClaimCost, alloc analyzer.Allocations) Report {
+	return Report{
+		Metadata:    meta,
+		Pods:        pods,
+		Orphans:     orphans,
+		Summaries:   alloc.Apply(analyzer.AddOrphanedClaims(analyzer.AggregateByNamespace(pods), orphans)),
+		Allocations: alloc,
+	}
+}
+
+// NewWorkloadReport builds a report of workload costs, counting each workload's
+// replicas as pods in the namespace summaries
+func NewWorkloadReport(meta Metadata, workloads []calculator.WorkloadCost, orphans []calculator.ClaimCost) Report {
+	if workloads == nil {
+		workloads = []calculator.WorkloadCost{}
+	}
+	return Report{
+		Metadata:  meta,
+		Workloads: workloads,
+		Orphans:   orphans,
+		Summaries: analyzer.AddOrphanedClaims(analyzer.AggregateWorkloadsByNamespace(workloads), orphans),
+	}
+}
+
+// ByWorkload reports whether the report is priced per workload controller
+func (r Report) ByWorkload() bool {
+	return r.Workloads != nil
+}
+
+// Reporter renders a report in one output format
+type Reporter interface {
+	Report(w io.Writer, r Report) error
+}
+
+// ReporterFunc adapts a function to the Reporter interface
+type ReporterFunc func(w io.Writer, r Report) error
+
+// Report calls f(w, r)
+func (f ReporterFunc) Report(w io.Writer, r Report) error {
+	return f(w, r)
+}
+
+var reporters = make(map[string]Reporter)
+
+// Register makes a reporter available under a format name for Lookup. It panics
+// if the name is already taken.
+func Register(format string, r Reporter) {
+	if _, ok := reporters[format]; ok {
+		panic(fmt.Sprintf("reporter: format %q registered twice", format))
+	}
+	reporters[format] = r
+}
+
+// Lookup returns the reporter registered for a format name
+func Lookup(format string) (Reporter, error) {
+	r, ok := reporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported output format: %s (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return r, nil
+}
+
+// Formats returns the registered format names in alphabetical order
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for name := range reporters {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func init() {
+	Register("table", ReporterFunc(PrintTable))
+	Register("json", ReporterFunc(PrintJSON))
+	Register("csv", ReporterFunc(PrintCSV))
+}
+
+// PrintTable renders the pod or workload table, orphaned claims, the namespace
+// summary, shared namespaces, node capacity, changes since the baseline and the
+// report's note
+func PrintTable(w io.Writer, r Report) error {
+	if r.ByWorkload() {
+		PrintWorkloadCostTable(w, r.Workloads)
+	} else {
+		PrintCostTable(w, r.Pods)
+	}
+	PrintOrphanedClaims(w, r.Orphans)
+	PrintNamespaceSummary(w, r.Summaries)
+	PrintSharedNamespaces(w, r.Allocations.Shared)
+	if r.Allocations.Idle != nil {
+		fmt.Fprintf(w, "\nNode Capacity:\n")
+		PrintNodeTable(w, r.Allocations.Idle.Nodes)
+	}
+	if r.Diff != nil {
+		fmt.Fprintf(w, "\nChanges Since Baseline:\n")
+		PrintDiffTable(w, *r.Diff)
+	}
+	if r.Metadata.Note != "" {
+		fmt.Fprintf(w, "\nNote: %s\n", r.Metadata.Note)
+	}
+	return nil
+}
+
+// PrintJSON renders the report with PrintCostJSON or PrintWorkloadCostJSON
+func PrintJSON(w io.Writer, r Report) error {
+	if r.ByWorkload() {
+		return PrintWorkloadCostJSON(w, r)
+	}
+	return PrintCostJSON(w, r)
+}
+
+// PrintCSV renders the report with PrintCostCSV or PrintWorkloadCostCSV
+func PrintCSV(w io.Writer, r Report) error {
+	if r.ByWorkload() {
+		return PrintWorkloadCostCSV(w, r)
+	}
+	return PrintCostCSV(w, r)
+}
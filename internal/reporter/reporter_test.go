@@ -0,0 +1,92 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/analyzer"
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/calculator"
+)
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+	for _, format := range []string{"table", "json", "csv", "markdown", "html"} {
+		if _, err := Lookup(format); err != nil {
+			t.Errorf("%s: unexpected error: %v", format, err)
+		}
+	}
+
+	_, err := Lookup("yaml")
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format: yaml (supported: csv, html, json, markdown, table") {
+		t.Errorf("got %v, want an unsupported format error listing the formats", err)
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		report Report
+		want   []string
+	}{
+		{
+			name: "pods",
+			report: NewPodReport(Metadata{Note: "Estimates only."}, []calculator.PodCost{
+				{Name: "web-1", Namespace: "shop", Monthly: calculator.ResourceCost{TotalCost: 30}},
+			}, nil, analyzer.Allocations{}),
+			want: []string{"POD", "web-1", "Namespace Summary:", "Estimated Monthly Cost: $30.00", "Note: Estimates only."},
+		},
+		{
+			name: "workloads",
+			report: NewWorkloadReport(Metadata{}, []calculator.WorkloadCost{
+				{Kind: "Deployment", Name: "web", Namespace: "shop", Replicas: 3, Monthly: calculator.ResourceCost{TotalCost: 90}},
+			}, nil),
+			want: []string{"KIND", "Deployment", "Total Pods: 3", "Estimated Monthly Cost: $90.00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := PrintTable(&buf, tt.report); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestPrintJSONMetadata(t *testing.T) {
+	t.Parallel()
+	meta := Metadata{
+		Scope:       "namespace 'shop'",
+		Rates:       calculator.Rates{CPUPerCorePerHour: 0.03, MemoryPerGBPerHour: 0.004},
+		GeneratedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	var buf bytes.Buffer
+	if err := PrintJSON(&buf, NewPodReport(meta, nil, nil, analyzer.Allocations{})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out jsonOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("failed to parse JSON output: %v", err)
+	}
+	if out.Metadata == nil {
+		t.Fatal("metadata missing")
+	}
+	if !out.Metadata.GeneratedAt.Equal(meta.GeneratedAt) || out.Metadata.Scope != meta.Scope {
+		t.Errorf("got %+v, want generated_at %v and scope %q", out.Metadata, meta.GeneratedAt, meta.Scope)
+	}
+	if out.Metadata.Rates.CPUPerCorePerHour != 0.03 {
+		t.Errorf("cpu rate: got %v, want 0.03", out.Metadata.Rates.CPUPerCorePerHour)
+	}
+}
@@ -2,20 +2,104 @@ package reporter
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
 )
 
 // PrintPodResourcesTable displays pod resources in a formatted table
-func PrintPodResourcesTable(resources []k8s.PodResources) {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	defer w.Flush()
+func PrintPodResourcesTable(w io.Writer, resources []k8s.PodResources) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
 
-	fmt.Fprintln(w, "POD\tCPU REQUEST\tMEMORY REQUEST\tCPU LIMIT\tMEMORY LIMIT")
+	showNamespace := false
 	for _, r := range resources {
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			r.Name, r.CPURequest, r.MemoryRequest, r.CPULimit, r.MemoryLimit)
+		if r.Namespace != resources[0].Namespace {
+			showNamespace = true
+			break
+		}
 	}
+
+	extended := extendedNames(resources, func(r k8s.PodResources) map[string]string { return r.Extended })
+	showRule := hasNonDefaultRule(resources)
+	if showNamespace {
+		fmt.Fprint(tw, "NAMESPACE\t")
+	}
+	fmt.Fprintf(tw, "POD\tCPU REQUEST\tMEMORY REQUEST\tCPU LIMIT\tMEMORY LIMIT%s", extendedRequestHeader(extended))
+	if showRule {
+		fmt.Fprint(tw, "\tREQUEST RULE")
+	}
+	fmt.Fprintln(tw)
+	for _, r := range resources {
+		if showNamespace {
+			fmt.Fprintf(tw, "%s\t", r.Namespace)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s%s",
+			r.Name, r.CPURequest, r.MemoryRequest, r.CPULimit, r.MemoryLimit, extendedRequestCells(extended, r))
+		if showRule {
+			fmt.Fprintf(tw, "\t%s", requestRuleCell(r.RequestRule()))
+		}
+		fmt.Fprintln(tw)
+	}
+}
+
+// PrintWorkloadResourcesTable displays per-replica resources for workload controllers
+func PrintWorkloadResourcesTable(w io.Writer, workloads []k8s.Workload) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	resources := make([]k8s.PodResources, len(workloads))
+	for i, wl := range workloads {
+		resources[i] = k8s.ExtractResources(wl.Pod())
+	}
+	extended := extendedNames(resources, func(r k8s.PodResources) map[string]string { return r.Extended })
+	showRule := hasNonDefaultRule(resources)
+
+	fmt.Fprintf(tw, "NAMESPACE\tKIND\tNAME\tREPLICAS\tCPU REQUEST\tMEMORY REQUEST\tCPU LIMIT\tMEMORY LIMIT%s", extendedRequestHeader(extended))
+	if showRule {
+		fmt.Fprint(tw, "\tREQUEST RULE")
+	}
+	fmt.Fprintln(tw)
+	for i, wl := range workloads {
+		r := resources[i]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s%s",
+			wl.Namespace, wl.Kind, wl.Name, wl.Replicas, r.CPURequest, r.MemoryRequest, r.CPULimit, r.MemoryLimit, extendedRequestCells(extended, r))
+		if showRule {
+			fmt.Fprintf(tw, "\t%s", requestRuleCell(r.RequestRule()))
+		}
+		fmt.Fprintln(tw)
+	}
+}
+
+func extendedRequestHeader(names []string) string {
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "\t%s REQUEST", strings.ToUpper(name))
+	}
+	return b.String()
+}
+
+func extendedRequestCells(names []string, r k8s.PodResources) string {
+	var b strings.Builder
+	for _, name := range names {
+		if qty, ok := r.Extended[name]; ok {
+			b.WriteString("\t" + qty)
+		} else {
+			b.WriteString("\t-")
+		}
+	}
+	return b.String()
+}
+
+// hasNonDefaultRule reports whether any pod's requests were set by something other
+// than the sum of its app containers (init containers, sidecars or overhead)
+func hasNonDefaultRule(resources []k8s.PodResources) bool {
+	for _, r := range resources {
+		if rule := r.RequestRule(); rule != "" && rule != k8s.RuleContainers {
+			return true
+		}
+	}
+	return false
 }
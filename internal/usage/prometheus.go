@@ -0,0 +1,197 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPrometheusPoints is the most points Prometheus returns per series for a range query
+const maxPrometheusPoints = 11000
+
+// minRateWindow is the shortest window rate() is computed over, so that it spans
+// several scrapes at common scrape intervals
+const minRateWindow = 5 * time.Minute
+
+// Prometheus reads container usage history from the cAdvisor metrics scraped by a
+// Prometheus-compatible server (Prometheus, Thanos, Mimir, VictoriaMetrics)
+type Prometheus struct {
+	// URL is the server's base URL, e.g. http://prometheus.monitoring:9090
+	URL string
+	// Headers are added to every request, e.g. Authorization or X-Scope-OrgID
+	Headers http.Header
+	// Range is how far back from now to query, sampled every Step
+	Range time.Duration
+	Step  time.Duration
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+	// Now defaults to time.Now
+	Now func() time.Time
+}
+
+// ContainerUsage implements Source. CPU is the per-second rate of
+// container_cpu_usage_seconds_total and memory is container_memory_working_set_bytes,
+// each evaluated every Step over the last Range.
+func (p Prometheus) ContainerUsage(ctx context.Context, namespaces []string) (map[ContainerKey]*Series, error) {
+	if p.Step <= 0 || p.Range <= 0 {
+		return nil, fmt.Errorf("prometheus range and step must be positive")
+	}
+	if points := p.Range / p.Step; points > maxPrometheusPoints {
+		return nil, fmt.Errorf("a %s range at a %s step is %d points per series, more than Prometheus allows (%d); use a larger step",
+			p.Range, p.Step, points, maxPrometheusPoints)
+	}
+
+	selector := containerSelector(namespaces)
+	window := max(p.Step, minRateWindow)
+	queries := []struct {
+		query string
+		add   func(s *Series, v float64)
+	}{
+		{
+			query: fmt.Sprintf("sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total%s[%s]))", selector, promDuration(window)),
+			add:   func(s *Series, v float64) { s.CPU = append(s.CPU, v) },
+		},
+		{
+			query: fmt.Sprintf("sum by (namespace, pod, container) (container_memory_working_set_bytes%s)", selector),
+			add:   func(s *Series, v float64) { s.Memory = append(s.Memory, v) },
+		},
+	}
+
+	now := time.Now
+	if p.Now != nil {
+		now = p.Now
+	}
+	end := now()
+	start := end.Add(-p.Range)
+
+	series := make(map[ContainerKey]*Series)
+	for _, q := range queries {
+		result, err := p.queryRange(ctx, q.query, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range result {
+			key := ContainerKey{Namespace: r.Metric["namespace"], Pod: r.Metric["pod"], Container: r.Metric["container"]}
+			s := series[key]
+			if s == nil {
+				s = &Series{}
+				series[key] = s
+			}
+			for _, point := range r.Values {
+				v, err := point.value()
+				if err != nil {
+					return nil, err
+				}
+				q.add(s, v)
+			}
+		}
+	}
+	return series, nil
+}
+
+// promResponse is the envelope of the Prometheus HTTP API
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string       `json:"resultType"`
+		Result     []promSeries `json:"result"`
+	} `json:"data"`
+}
+
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promPoint       `json:"values"`
+}
+
+// promPoint is a [timestamp, "value"] pair
+type promPoint [2]any
+
+func (p promPoint) value() (float64, error) {
+	s, ok := p[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected prometheus sample value %v", p[1])
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid prometheus sample value %q: %w", s, err)
+	}
+	return v, nil
+}
+
+func (p Prometheus) queryRange(ctx context.Context, query string, start, end time.Time) ([]promSeries, error) {
+	form := url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.FormatFloat(p.Step.Seconds(), 'f', -1, 64)},
+	}
+	endpoint := strings.TrimSuffix(p.URL, "/") + "/api/v1/query_range"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prometheus request: %w", err)
+	}
+	for name, values := range p.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+
+	var parsed promResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if parsed.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed (HTTP %d): %s: %s", resp.StatusCode, parsed.ErrorType, parsed.Error)
+	}
+	if parsed.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("unexpected prometheus result type %q", parsed.Data.ResultType)
+	}
+	return parsed.Data.Result, nil
+}
+
+// containerSelector matches real containers (not the pause container or pod-level
+// cgroups) in the given namespaces, or in all namespaces when empty
+func containerSelector(namespaces []string) string {
+	matchers := []string{`container!=""`, `container!="POD"`}
+	if len(namespaces) > 0 {
+		quoted := make([]string, len(namespaces))
+		for i, ns := range namespaces {
+			quoted[i] = regexp.QuoteMeta(ns)
+		}
+		matchers = append(matchers, fmt.Sprintf("namespace=~%q", strings.Join(quoted, "|")))
+	}
+	return "{" + strings.Join(matchers, ",") + "}"
+}
+
+// promDuration renders a duration in Prometheus syntax, e.g. 5m or 90s
+func promDuration(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
@@ -0,0 +1,132 @@
+package usage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPrometheusContainerUsage(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		mu      sync.Mutex
+		queries []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","errorType":"unauthorized","error":"missing token"}`))
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		if got, want := r.Form.Get("start"), strconv.FormatInt(now.Add(-24*time.Hour).Unix(), 10); got != want {
+			t.Errorf("start: got %q, want %q", got, want)
+		}
+		if got := r.Form.Get("step"); got != "3600" {
+			t.Errorf("step: got %q, want %q", got, "3600")
+		}
+
+		query := r.Form.Get("query")
+		mu.Lock()
+		queries = append(queries, query)
+		mu.Unlock()
+
+		a, b := "0.2", "0.3"
+		if strings.Contains(query, "container_memory_working_set_bytes") {
+			a, b = "268435456", "536870912"
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"namespace":"shop","pod":"api-0","container":"app"},"values":[[1740744000,"` + a + `"],[1740747600,"` + b + `"]]}
+		]}}`))
+	}))
+	defer server.Close()
+
+	p := Prometheus{
+		URL:     server.URL + "/",
+		Headers: http.Header{"Authorization": {"Bearer secret"}},
+		Range:   24 * time.Hour,
+		Step:    time.Hour,
+		Now:     func() time.Time { return now },
+	}
+	series, err := p.ContainerUsage(context.Background(), []string{"shop", "web"})
+	if err != nil {
+		t.Fatalf("ContainerUsage failed: %v", err)
+	}
+
+	s, ok := series[ContainerKey{Namespace: "shop", Pod: "api-0", Container: "app"}]
+	if !ok {
+		t.Fatalf("no series for shop/api-0/app in %v", series)
+	}
+	if len(s.CPU) != 2 || s.CPU[1] != 0.3 {
+		t.Errorf("CPU samples: got %v, want [0.2 0.3]", s.CPU)
+	}
+	if len(s.Memory) != 2 || s.Memory[0] != 268435456 {
+		t.Errorf("memory samples: got %v, want [268435456 536870912]", s.Memory)
+	}
+
+	means := PodMeans(series)
+	if got := means["shop/api-0"]; got.CPU != 0.25 || got.Memory != 402653184 {
+		t.Errorf("pod mean: got %+v, want {CPU:0.25 Memory:402653184}", got)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("queries: got %d, want 2", len(queries))
+	}
+	for _, want := range []string{
+		`rate(container_cpu_usage_seconds_total{container!="",container!="POD",namespace=~"shop|web"}[60m])`,
+		`container_memory_working_set_bytes{container!="",container!="POD",namespace=~"shop|web"}`,
+	} {
+		if !strings.Contains(queries[0]+queries[1], want) {
+			t.Errorf("queries %q do not contain %q", queries, want)
+		}
+	}
+}
+
+func TestPrometheusErrors(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		p       Prometheus
+		wantErr string
+	}{
+		{
+			name:    "server error is reported",
+			p:       Prometheus{URL: server.URL, Range: time.Hour, Step: time.Minute},
+			wantErr: "bad_data: parse error",
+		},
+		{
+			name:    "too many points",
+			p:       Prometheus{URL: server.URL, Range: 30 * 24 * time.Hour, Step: 15 * time.Second},
+			wantErr: "use a larger step",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.p.ContainerUsage(context.Background(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error: got %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/albert-saclot/k8s-cost-analyzer/internal/k8s"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ContainerKey identifies a container of a pod
+type ContainerKey struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// Series holds the usage samples observed for one container
+type Series struct {
+	// CPU is in cores
+	CPU []float64
+	// Memory is in bytes
+	Memory []float64
+}
+
+// Source returns observed usage for the containers of pods in the given namespaces
+// (all namespaces when empty)
+type Source interface {
+	ContainerUsage(ctx context.Context, namespaces []string) (map[ContainerKey]*Series, error)
+}
+
+// MetricsServer samples metrics-server Samples times, Interval apart. metrics-server
+// only keeps the latest sample, so this is the only way to build a series from it.
+type MetricsServer struct {
+	Client   *kubernetes.Clientset
+	Samples  int
+	Interval time.Duration
+}
+
+// ContainerUsage implements Source
+func (m MetricsServer) ContainerUsage(ctx context.Context, namespaces []string) (map[ContainerKey]*Series, error) {
+	samples := max(m.Samples, 1)
+	series := make(map[ContainerKey]*Series)
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(m.Interval):
+			}
+		}
+
+		pods, err := k8s.FetchPodUsage(ctx, m.Client, namespaces)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d of %d: %w", i+1, samples, err)
+		}
+		for _, pod := range pods {
+			for _, c := range pod.Containers {
+				key := ContainerKey{Namespace: pod.Namespace, Pod: pod.Name, Container: c.Name}
+				s := series[key]
+				if s == nil {
+					s = &Series{}
+					series[key] = s
+				}
+				s.CPU = append(s.CPU, c.CPU.AsApproximateFloat64())
+				s.Memory = append(s.Memory, c.Memory.AsApproximateFloat64())
+			}
+		}
+	}
+	return series, nil
+}
+
+// Mean is a pod's average CPU (cores) and memory (bytes) usage
+type Mean struct {
+	CPU    float64
+	Memory float64
+}
+
+// PodMeans sums the average usage of each pod's containers, keyed by namespace/name.
+// Averages are what a pod costs over the sampled period.
+func PodMeans(series map[ContainerKey]*Series) map[string]Mean {
+	means := make(map[string]Mean)
+	for key, s := range series {
+		m := means[key.Namespace+"/"+key.Pod]
+		m.CPU += average(s.CPU)
+		m.Memory += average(s.Memory)
+		means[key.Namespace+"/"+key.Pod] = m
+	}
+	return means
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Percentile returns the p-th percentile (0-100) of values using the nearest-rank
+// method, so the result is always an observed sample. It returns 0 for no values.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
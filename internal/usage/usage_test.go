@@ -0,0 +1,35 @@
+package usage
+
+import "testing"
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+	values := []float64{5, 1, 4, 2, 3, 10, 6, 8, 7, 9}
+
+	tests := []struct {
+		name   string
+		values []float64
+		p      float64
+		want   float64
+	}{
+		{name: "median", values: values, p: 50, want: 5},
+		{name: "p95 of ten samples is the maximum", values: values, p: 95, want: 10},
+		{name: "p90", values: values, p: 90, want: 9},
+		{name: "lowest percentile is the minimum", values: values, p: 1, want: 1},
+		{name: "single sample", values: []float64{0.25}, p: 95, want: 0.25},
+		{name: "no samples", p: 95, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Percentile(tt.values, tt.p); got != tt.want {
+				t.Errorf("Percentile(p%g): got %g, want %g", tt.p, got, tt.want)
+			}
+		})
+	}
+
+	if values[0] != 5 {
+		t.Error("Percentile sorted its input in place")
+	}
+}
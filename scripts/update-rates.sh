@@ -2,11 +2,11 @@
 # Update pricing rates in config/rates.yaml
 #
 # CURRENT STATUS: Manual process documented below
-# FUTURE: Could be automated to fetch from cloud provider APIs
+# Or import a downloaded pricing dump with: kcost rates import
 
 set -e
 
-RATES_FILE="config/rates.yaml"
+RATES_FILE="${KCOST_RATES:-${XDG_CONFIG_HOME:-$HOME/.config}/kcost/rates.yaml}"
 TODAY=$(date +%Y-%m-%d)
 
 echo "Pricing Rate Update Tool"
@@ -24,15 +24,16 @@ echo "   - CPU rate = instance_price / num_cores"
 echo "   - Memory rate = instance_price / memory_gb"
 echo "   - Apply blending for typical request/limit patterns"
 echo ""
-echo "4. Update $RATES_FILE:"
+echo "4. Update $RATES_FILE (start from config/rates.yaml if it does not exist):"
 echo "   - Set cpu_per_core_per_hour"
 echo "   - Set memory_per_gb_per_hour"
-echo "   - Update 'Last updated' date to $TODAY"
+echo "   - Set last_updated to $TODAY"
+echo "   - Or add a dated entry under periods (effective_from: $TODAY)"
+echo "   - Check the result: kcost rates validate $RATES_FILE"
 echo ""
-echo "Future enhancement ideas:"
-echo "- Fetch AWS pricing via AWS Price List API"
-echo "- Query GCP Cloud Billing Catalog API"
-echo "- Pull from OpenCost's maintained pricing data"
-echo "- Support multiple regions/instance types"
-echo ""
-echo "For now, please update $RATES_FILE manually."
+echo "Or import a downloaded pricing dump instead of calculating by hand:"
+echo "   - OpenCost:  kcost rates import --format opencost default.json --output-file $RATES_FILE"
+echo "   - AWS:       curl -O https://pricing.us-east-1.amazonaws.com/offers/v1.0/aws/AmazonEC2/current/us-east-1/index.json"
+echo "                kcost rates import --format aws-json --region us-east-1 index.json --output-file $RATES_FILE"
+echo "   - GCP:       save the Compute Engine SKU list from the Cloud Billing Catalog API, then"
+echo "                kcost rates import --format gcp-skus --region us-central1 --machine-family E2 skus.json --output-file $RATES_FILE"